@@ -0,0 +1,137 @@
+package log
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// accessTimeLayout 是 NCSA 日志中 %t 的时间格式
+const accessTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// AccessLog 是一条 HTTP 访问日志，字段与 Apache/NCSA 日志格式对应
+type AccessLog struct {
+	RemoteAddr string    // 客户端地址 %h，不带端口
+	User       string    // 认证用户名 %u，为空时输出 -
+	Time       time.Time // 收到请求的时间 %t
+	Method     string
+	URI        string
+	Proto      string
+	Status     int   // 响应状态码 %>s
+	Size       int64 // 响应体字节数 %b，为 0 时输出 -
+	Referer    string
+	UserAgent  string
+}
+
+// NewAccessLog 根据请求和响应结果创建访问日志，start 是收到请求的时间
+func NewAccessLog(r *http.Request, status int, size int64, start time.Time) AccessLog {
+	e := AccessLog{
+		RemoteAddr: r.RemoteAddr,
+		Time:       start,
+		Method:     r.Method,
+		URI:        r.RequestURI,
+		Proto:      r.Proto,
+		Status:     status,
+		Size:       size,
+		Referer:    r.Referer(),
+		UserAgent:  r.UserAgent(),
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		e.RemoteAddr = host
+	}
+	if e.URI == "" && r.URL != nil {
+		e.URI = r.URL.RequestURI()
+	}
+	if user, _, ok := r.BasicAuth(); ok {
+		e.User = user
+	} else if r.URL != nil && r.URL.User != nil {
+		e.User = r.URL.User.Username()
+	}
+	return e
+}
+
+// AppendCommon 按 Common Log Format 追加一行（不含换行）：
+//
+//	127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326
+func (e AccessLog) AppendCommon(b []byte) []byte {
+	b = append(b, accessField(e.RemoteAddr)...)
+	b = append(b, " - "...)
+	b = append(b, accessField(e.User)...)
+	b = append(b, " ["...)
+	b = e.Time.AppendFormat(b, accessTimeLayout)
+	b = append(b, "] \""...)
+	b = appendAccessEscaped(b, e.Method+" "+e.URI+" "+e.Proto)
+	b = append(b, "\" "...)
+	b = strconv.AppendInt(b, int64(e.Status), 10)
+	b = append(b, ' ')
+	if e.Size > 0 {
+		b = strconv.AppendInt(b, e.Size, 10)
+	} else {
+		b = append(b, '-')
+	}
+	return b
+}
+
+// AppendCombined 按 Combined Log Format 追加一行（不含换行），即 Common 格式加上 Referer 和 User-Agent，
+// GoAccess、AWStats 等分析工具可以直接读取
+func (e AccessLog) AppendCombined(b []byte) []byte {
+	b = e.AppendCommon(b)
+	b = append(b, " \""...)
+	b = appendAccessEscaped(b, accessField(e.Referer))
+	b = append(b, "\" \""...)
+	b = appendAccessEscaped(b, accessField(e.UserAgent))
+	return append(b, '"')
+}
+
+// Combined 返回 Combined Log Format 的一行
+func (e AccessLog) Combined() string {
+	return string(e.AppendCombined(nil))
+}
+
+func accessField(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// appendAccessEscaped 按 Apache 的规则转义：双引号和反斜杠前加反斜杠，控制字符输出为 \xhh
+func appendAccessEscaped(b []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			b = append(b, '\\', c)
+		case c < 0x20 || c == 0x7f:
+			b = append(b, '\\', 'x', hex[c>>4], hex[c&0xf])
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
+
+// AccessLogWriter 将访问日志按 Combined Log Format 逐行写入 w，可以被多个 goroutine 并发使用
+type AccessLogWriter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	buf []byte
+}
+
+// NewAccessLogWriter 创建写入 w 的访问日志 writer，w 可以是 FailoverWriter、BufferedWriter 等
+func NewAccessLogWriter(w io.Writer) *AccessLogWriter {
+	return &AccessLogWriter{w: w}
+}
+
+// Write 写入一条访问日志
+func (w *AccessLogWriter) Write(e AccessLog) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(e.AppendCombined(w.buf[:0]), '\n')
+	_, err := w.w.Write(w.buf)
+	return err
+}
@@ -0,0 +1,35 @@
+package log
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAccessLogCombined(t *testing.T) {
+	r := httptest.NewRequest("GET", "/apache_pb.gif?q=1", nil)
+	r.RemoteAddr = "127.0.0.1:52000"
+	r.SetBasicAuth("frank", "secret")
+	r.Header.Set("Referer", "http://www.example.com/start.html")
+	r.Header.Set("User-Agent", `Mozilla/4.08 "beta"`)
+	start := time.Date(2000, 10, 10, 13, 55, 36, 0, time.FixedZone("", -7*3600))
+
+	var buf bytes.Buffer
+	w := NewAccessLogWriter(&buf)
+	if err := w.Write(NewAccessLog(r, 200, 2326, start)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	want := `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif?q=1 HTTP/1.1" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08 \"beta\""` + "\n"
+	if buf.String() != want {
+		t.Errorf("got  %s\nwant %s", buf.String(), want)
+	}
+}
+
+func TestAccessLogEmptyFields(t *testing.T) {
+	e := AccessLog{RemoteAddr: "10.0.0.1", Time: time.Unix(0, 0).UTC(), Method: "HEAD", URI: "/\n", Proto: "HTTP/1.0", Status: 304}
+	want := `10.0.0.1 - - [01/Jan/1970:00:00:00 +0000] "HEAD /\x0a HTTP/1.0" 304 - "-" "-"`
+	if got := e.Combined(); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,57 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultArchiveLayout 是 ArchiveDir 不含模板时子目录的时间格式，按月份归档
+const defaultArchiveLayout = "{2006-01}"
+
+// archiveTemplate 返回 ArchiveDir 对应的目录模板，不含 {} 占位符时追加按月份的子目录
+func archiveTemplate(cfg Config) string {
+	if strings.Contains(cfg.ArchiveDir, "{") {
+		return cfg.ArchiveDir
+	}
+	return filepath.Join(cfg.ArchiveDir, defaultArchiveLayout)
+}
+
+// archiveFile 将滚动出的文件移动到 t 对应的归档目录，返回移动后的路径
+func archiveFile(cfg Config, path string, t time.Time) (string, error) {
+	if cfg.RotateUTC {
+		t = t.UTC()
+	}
+	dir := rotateFilename(archiveTemplate(cfg), 0, t)
+	if err := makeDirs(cfg, dir); err != nil {
+		return "", err
+	}
+	dst := filepath.Join(dir, filepath.Base(path))
+	if err := os.Rename(path, dst); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
+
+// archiveDirs 返回已存在的归档目录，没有配置 ArchiveDir 时返回 nil
+func archiveDirs(cfg Config) []string {
+	if cfg.ArchiveDir == "" {
+		return nil
+	}
+	dirs, _ := filepath.Glob(placeholderRe.ReplaceAllString(archiveTemplate(cfg), "*"))
+	return dirs
+}
+
+// archivePatterns 返回归档目录中属于 Config.Filename 的文件的 glob 模式，供按总大小清理时使用
+func archivePatterns(cfg Config) []string {
+	if cfg.ArchiveDir == "" {
+		return nil
+	}
+	dir := placeholderRe.ReplaceAllString(archiveTemplate(cfg), "*")
+	var patterns []string
+	for _, p := range retentionPatterns(cfg.Filename) {
+		patterns = append(patterns, filepath.Join(dir, filepath.Base(p)))
+	}
+	return patterns
+}
@@ -0,0 +1,80 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArchiveRotatedFiles(t *testing.T) {
+	current := time.Date(2024, 5, 31, 21, 0, 0, 0, time.UTC)
+	SetClock(ClockFunc(func() time.Time { return current }))
+	defer SetClock(nil)
+
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "archive")
+	cfg := Config{Compress: true, RotateUTC: true, ArchiveDir: archive}
+	rotate := func(f fileOutput) {
+		f.Write([]byte("record\n"))
+		current = current.Add(time.Hour)
+		if err := f.Rotate(); err != nil {
+			t.Fatalf("Rotate failed: %v", err)
+		}
+	}
+	f := newFileOutput(cfg, filepath.Join(dir, "app.log"))
+	for i := 0; i < 4; i++ {
+		rotate(f)
+	}
+	f.Close()
+
+	may, _ := filepath.Glob(filepath.Join(archive, "2024-05", "app-*.log.gz"))
+	june, _ := filepath.Glob(filepath.Join(archive, "2024-06", "app-*.log.gz"))
+	if len(may) != 2 || len(june) != 2 {
+		t.Errorf("archived May=%v June=%v, want 2 each", may, june)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("active directory has %d entries, want app.log and archive", len(entries))
+	}
+
+	// MaxBackups 对归档目录中的备份同样生效
+	cfg.MaxBackups = 2
+	f = newFileOutput(cfg, filepath.Join(dir, "app.log"))
+	rotate(f)
+	f.Close()
+	all, _ := filepath.Glob(filepath.Join(archive, "*", "app-*.log.gz"))
+	if len(all) != 2 || !strings.Contains(all[0], "2024-06") {
+		t.Errorf("archived files after MaxBackups = %v, want the 2 newest", all)
+	}
+}
+
+func TestArchivePeriodFiles(t *testing.T) {
+	current := time.Date(2024, 5, 31, 23, 59, 0, 0, time.UTC)
+	SetClock(ClockFunc(func() time.Time { return current }))
+	defer SetClock(nil)
+
+	dir := t.TempDir()
+	rotated := make(chan string, 1)
+	logger := NewLogger(Config{
+		Filename:       filepath.Join(dir, "app.log"),
+		RotateInterval: RotateDaily,
+		RotateUTC:      true,
+		ArchiveDir:     filepath.Join(dir, "archive", "{2006}", "{01}"),
+		OnRotate:       func(p string) { rotated <- p },
+	})
+	defer logger.Close()
+	logger.Info("may")
+	current = current.Add(time.Hour)
+	logger.Info("june")
+
+	select {
+	case p := <-rotated:
+		// 结束的周期文件按周期开始时间归档
+		if want := filepath.Join(dir, "archive", "2024", "05", "app-2024-05-31.log"); p != want {
+			t.Errorf("archived to %s, want %s", p, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("period file not archived")
+	}
+}
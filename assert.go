@@ -0,0 +1,70 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// StacktraceKey 是调用栈属性的 key，ecs 格式映射为 error.stack_trace，clef 格式追加到 @x
+const StacktraceKey = ecsStackKey
+
+// Assert 在 cond 为 false 时输出一条带调用栈的 Error 记录，用于让软性不变量的违反在生产环境可见而不导致崩溃。
+// 开发模式（Config.Development）下记录后会 panic，尽早暴露问题。
+//
+//	log.Assert(balance >= 0, "balance must not be negative", "account", id, "balance", balance)
+func (l *Logger) Assert(cond bool, msg string, args ...any) {
+	if cond {
+		return
+	}
+	args = append(args, StacktraceKey, stacktrace())
+	l.log(context.Background(), slog.LevelError, msg, args...)
+	if l.development {
+		l.Sync()
+		panic(fmt.Sprintf("slogx: assertion failed: %s", msg))
+	}
+}
+
+// Assert 使用默认 Logger 检查不变量
+func Assert(cond bool, msg string, args ...any) {
+	defaultLogger.Assert(cond, msg, args...)
+}
+
+// stacktrace 返回调用方的调用栈文本，去掉本库内部的栈帧和 runtime.goexit，格式与 panic 输出一致：
+//
+//	main.pay()
+//		/app/main.go:10
+func stacktrace() string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var b strings.Builder
+	for {
+		frame, more := frames.Next()
+		if !isLibraryFrame(frame) && frame.Function != "runtime.goexit" {
+			if b.Len() > 0 {
+				b.WriteByte('\n')
+			}
+			b.WriteString(frame.Function)
+			b.WriteString("()\n\t")
+			b.WriteString(frame.File)
+			b.WriteByte(':')
+			b.WriteString(strconv.Itoa(frame.Line))
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// withStacktrace 在 level 达到 Config.StacktraceLevel 且 args 中还没有调用栈时附加调用栈
+func (l *Logger) withStacktrace(level slog.Level, args []any) []any {
+	if l.stackLevel == nil || level < l.stackLevel.Level() || hasKey(args, StacktraceKey) {
+		return args
+	}
+	return append(args, StacktraceKey, stacktrace())
+}
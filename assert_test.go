@@ -0,0 +1,64 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestAssert(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: slog.LevelDebug, Format: "json", Writer: &buf})
+
+	logger.Assert(true, "never logged")
+	if buf.Len() != 0 {
+		t.Fatalf("Expected no output for a holding invariant, got: %s", buf.String())
+	}
+	logger.Assert(1+1 == 3, "math is broken", "a", 1)
+	output := buf.String()
+	if !strings.Contains(output, `"level":"ERROR"`) || !strings.Contains(output, `"msg":"math is broken"`) {
+		t.Errorf("Expected error record, got: %s", output)
+	}
+	if !strings.Contains(output, `"stacktrace":"github.com/luojiego/slogx.TestAssert()`) {
+		t.Errorf("Expected stack trace starting at the caller, got: %s", output)
+	}
+}
+
+func TestAssertPanicsInDevelopment(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: slog.LevelDebug, Writer: &buf, Development: true})
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic in development mode")
+		}
+		if !strings.Contains(buf.String(), "invariant violated") {
+			t.Errorf("Expected record before panic, got: %s", buf.String())
+		}
+	}()
+	logger.Assert(false, "invariant violated")
+}
+
+func TestStacktraceLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Format: "json", Writer: &buf, StacktraceLevel: slog.LevelError})
+
+	logger.Warn("no stack")
+	logger.With("a", 1).Error("with stack")
+	logger.Assert(false, "single stack")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %q", buf.String())
+	}
+	if strings.Contains(lines[0], "stacktrace") {
+		t.Errorf("Expected no stack below the threshold, got %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"stacktrace":"github.com/luojiego/slogx.TestStacktraceLevel()`) ||
+		strings.Contains(lines[1], "log.go") || strings.Contains(lines[1], "runtime.goexit") {
+		t.Errorf("Expected a cleaned stack starting at the caller, got %s", lines[1])
+	}
+	if strings.Count(lines[2], "stacktrace") != 1 {
+		t.Errorf("Expected Assert's stack not to be duplicated, got %s", lines[2])
+	}
+}
@@ -0,0 +1,56 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+)
+
+// BaggageFunc 返回 ctx 中 baggage 成员 key 的值，没有该成员时 ok 为 false。
+// slogx 不依赖 OpenTelemetry，使用 OTel 时这样设置 Config.Baggage：
+//
+//	Baggage: func(ctx context.Context, key string) (string, bool) {
+//		m := baggage.FromContext(ctx).Member(key)
+//		return m.Value(), m.Key() != ""
+//	},
+//	BaggageKeys: []string{"tenant_id", "customer_tier"},
+type BaggageFunc func(ctx context.Context, key string) (value string, ok bool)
+
+// baggageHandler 将 ctx 中允许列表内的 baggage 成员以成员名为 key 附加到记录，
+// 记录或 With 绑定的属性中已有同名 key 时不重复添加。
+// baggage 由上游任意设置并随请求传播，只输出允许列表中的成员，避免不可信或敏感的数据进入日志
+type baggageHandler struct {
+	slog.Handler
+	baggage BaggageFunc
+	keys    []string
+	state   attrState
+}
+
+func (h *baggageHandler) Handle(ctx context.Context, r slog.Record) error {
+	var add []slog.Attr
+	var attrs []slog.Attr
+	for _, key := range h.keys {
+		value, ok := h.baggage(ctx, key)
+		if !ok {
+			continue
+		}
+		if attrs == nil {
+			attrs = h.state.collect(r)
+		}
+		if !hasAttr(attrs, key) && !hasAttr(add, key) {
+			add = append(add, slog.String(key, value))
+		}
+	}
+	if len(add) > 0 {
+		r = r.Clone()
+		r.AddAttrs(add...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *baggageHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &baggageHandler{Handler: h.Handler.WithAttrs(attrs), baggage: h.baggage, keys: h.keys, state: h.state.withAttrs(attrs)}
+}
+
+func (h *baggageHandler) WithGroup(name string) slog.Handler {
+	return &baggageHandler{Handler: h.Handler.WithGroup(name), baggage: h.baggage, keys: h.keys, state: h.state.withGroup(name)}
+}
@@ -0,0 +1,46 @@
+package log
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type baggageKey struct{}
+
+func TestBaggage(t *testing.T) {
+	var buf strings.Builder
+	logger := NewLogger(Config{
+		Writer: &buf,
+		Format: "json",
+		Baggage: func(ctx context.Context, key string) (string, bool) {
+			members, _ := ctx.Value(baggageKey{}).(map[string]string)
+			value, ok := members[key]
+			return value, ok
+		},
+		BaggageKeys: []string{"tenant_id", "customer_tier"},
+	})
+	ctx := context.WithValue(context.Background(), baggageKey{}, map[string]string{
+		"tenant_id":     "acme",
+		"customer_tier": "",
+		"session_token": "secret",
+	})
+
+	logger.InfoContext(ctx, "with baggage")
+	logger.With("tenant_id", "bound").InfoContext(ctx, "bound tenant")
+	logger.Info("without ctx")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %q", buf.String())
+	}
+	if !strings.Contains(lines[0], `"tenant_id":"acme"`) || !strings.Contains(lines[0], `"customer_tier":""`) || strings.Contains(lines[0], "secret") {
+		t.Errorf("Expected only allowlisted members, got %s", lines[0])
+	}
+	if strings.Count(lines[1], "tenant_id") != 1 || !strings.Contains(lines[1], `"tenant_id":"bound"`) {
+		t.Errorf("Expected the bound attr to be kept, got %s", lines[1])
+	}
+	if strings.Contains(lines[2], "tenant_id") {
+		t.Errorf("Expected no baggage without ctx, got %s", lines[2])
+	}
+}
@@ -0,0 +1,57 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// 时钟未同步时附加的属性 key 和时钟同步后输出的校正记录
+const (
+	UptimeKey          = "uptime"
+	BootTimeKey        = "boot_time"
+	ClockSyncedMessage = "clock synchronized"
+)
+
+// processStart 记录进程启动时间，time.Since 使用其中的单调时钟读数，不受系统时间跳变影响
+var processStart = time.Now()
+
+// bootTimeHandler 用于 RTC 从 epoch 启动的嵌入式设备：系统时间早于 validAfter 时认为时钟尚未同步，
+// 在记录上附加进程启动以来的单调时间 uptime；时钟同步（例如 NTP 完成）后先输出一条校正记录，
+// 给出启动时刻的真实时间 boot_time，同步前记录的真实时间即 boot_time + uptime。
+type bootTimeHandler struct {
+	slog.Handler
+	validAfter time.Time
+	unsynced   *atomic.Bool // 是否输出过未同步的记录，同一个 Logger 派生出的 handler 共享
+}
+
+func newBootTimeHandler(h slog.Handler, validAfter time.Time) *bootTimeHandler {
+	return &bootTimeHandler{Handler: h, validAfter: validAfter, unsynced: &atomic.Bool{}}
+}
+
+func (h *bootTimeHandler) Handle(ctx context.Context, r slog.Record) error {
+	uptime := time.Since(processStart)
+	if r.Time.Before(h.validAfter) {
+		h.unsynced.Store(true)
+		r = r.Clone()
+		r.AddAttrs(slog.Duration(UptimeKey, uptime))
+		return h.Handler.Handle(ctx, r)
+	}
+	if h.unsynced.CompareAndSwap(true, false) {
+		c := slog.NewRecord(r.Time, slog.LevelWarn, ClockSyncedMessage, 0)
+		c.AddAttrs(slog.Time(BootTimeKey, r.Time.Add(-uptime)), slog.Duration(UptimeKey, uptime))
+		if h.Handler.Enabled(ctx, c.Level) {
+			h.Handler.Handle(ctx, c)
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *bootTimeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &bootTimeHandler{Handler: h.Handler.WithAttrs(attrs), validAfter: h.validAfter, unsynced: h.unsynced}
+}
+
+func (h *bootTimeHandler) WithGroup(name string) slog.Handler {
+	return &bootTimeHandler{Handler: h.Handler.WithGroup(name), validAfter: h.validAfter, unsynced: h.unsynced}
+}
@@ -0,0 +1,42 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBootTimeBeforeClockSync(t *testing.T) {
+	current := time.Unix(42, 0) // RTC 从 epoch 启动
+	SetClock(ClockFunc(func() time.Time { return current }))
+	defer SetClock(nil)
+
+	var buf bytes.Buffer
+	logger := NewLogger(Config{
+		Level:           slog.LevelDebug,
+		Writer:          &buf,
+		ClockValidAfter: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	logger.Info("booting")
+	current = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) // NTP 同步完成
+	logger.Info("synced")
+	logger.Info("running")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 lines, got %q", buf.String())
+	}
+	if !strings.Contains(lines[0], "msg=booting") || !strings.Contains(lines[0], "uptime=") {
+		t.Errorf("Expected uptime on unsynced record, got %q", lines[0])
+	}
+	if !strings.Contains(lines[1], `msg="clock synchronized"`) || !strings.Contains(lines[1], "boot_time=2024-05-01") {
+		t.Errorf("Expected correction record, got %q", lines[1])
+	}
+	for _, line := range lines[2:] {
+		if strings.Contains(line, "uptime=") {
+			t.Errorf("Expected no uptime after sync, got %q", line)
+		}
+	}
+}
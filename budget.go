@@ -0,0 +1,111 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+)
+
+// DroppedAttrsKey 是记录因超出大小上限而丢弃的属性数量的 key
+const DroppedAttrsKey = "dropped_attrs"
+
+// optionalValue 标记可丢弃的属性值，解析时返回原值，未设置大小上限时与普通属性没有区别
+type optionalValue struct {
+	value    slog.Value
+	priority int
+}
+
+func (v optionalValue) LogValue() slog.Value { return v.value }
+
+// Optional 将属性标记为可丢弃：记录超出 Config.MaxRecordBytes 时，优先级低的可丢弃属性先被移除，
+// 优先级相同时靠后的先被移除，并追加 dropped_attrs 记录丢弃的数量。只对日志调用时传入的顶层属性生效。
+//
+//	log.Info("request done", "status", 200, log.Optional(slog.Any("headers", h), 1), log.Optional(slog.String("body", b), 0))
+func Optional(attr slog.Attr, priority int) slog.Attr {
+	return slog.Any(attr.Key, optionalValue{value: attr.Value, priority: priority})
+}
+
+// budgetHandler 在记录超出大小上限时按优先级丢弃可丢弃的属性
+type budgetHandler struct {
+	slog.Handler
+	max int
+}
+
+func (h *budgetHandler) Handle(ctx context.Context, r slog.Record) error {
+	type candidate struct {
+		index    int
+		priority int
+	}
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	var optional []candidate
+	size := len(r.Message)
+	r.Attrs(func(a slog.Attr) bool {
+		if ov, ok := optionalAttr(a); ok {
+			optional = append(optional, candidate{index: len(attrs), priority: ov.priority})
+			a.Value = ov.value
+		}
+		size += attrSize(a)
+		attrs = append(attrs, a)
+		return true
+	})
+	if optional == nil {
+		return h.Handler.Handle(ctx, r)
+	}
+
+	dropped := make(map[int]bool)
+	if size > h.max {
+		sort.SliceStable(optional, func(i, j int) bool {
+			if optional[i].priority != optional[j].priority {
+				return optional[i].priority < optional[j].priority
+			}
+			return optional[i].index > optional[j].index
+		})
+		for _, c := range optional {
+			if size <= h.max {
+				break
+			}
+			size -= attrSize(attrs[c.index])
+			dropped[c.index] = true
+		}
+	}
+
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	for i, a := range attrs {
+		if !dropped[i] {
+			nr.AddAttrs(a)
+		}
+	}
+	if len(dropped) > 0 {
+		nr.AddAttrs(slog.Int(DroppedAttrsKey, len(dropped)))
+	}
+	return h.Handler.Handle(ctx, nr)
+}
+
+func (h *budgetHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &budgetHandler{Handler: h.Handler.WithAttrs(attrs), max: h.max}
+}
+
+func (h *budgetHandler) WithGroup(name string) slog.Handler {
+	return &budgetHandler{Handler: h.Handler.WithGroup(name), max: h.max}
+}
+
+func optionalAttr(a slog.Attr) (optionalValue, bool) {
+	if a.Value.Kind() != slog.KindLogValuer {
+		return optionalValue{}, false
+	}
+	ov, ok := a.Value.LogValuer().(optionalValue)
+	return ov, ok
+}
+
+// attrSize 估算属性输出后的字节数，按 key、值和分隔符计算，与具体格式无关
+func attrSize(a slog.Attr) int {
+	v := a.Value.Resolve()
+	if v.Kind() != slog.KindGroup {
+		return len(a.Key) + len(valueText(v)) + 2
+	}
+	n := len(a.Key) + 2
+	for _, ga := range v.Group() {
+		n += attrSize(ga)
+	}
+	return n
+}
@@ -0,0 +1,35 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestOptionalAttrsDroppedByPriority(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: slog.LevelDebug, Writer: &buf, MaxRecordBytes: 120})
+
+	logger.Info("request done",
+		"status", 200,
+		Optional(slog.String("body", strings.Repeat("b", 60)), 0),
+		Optional(slog.String("headers", strings.Repeat("h", 30)), 1),
+	)
+	output := buf.String()
+	if strings.Contains(output, "body=") {
+		t.Errorf("Expected low priority attr to be dropped, got: %s", output)
+	}
+	if !strings.Contains(output, "headers=hhh") || !strings.Contains(output, "status=200") {
+		t.Errorf("Expected higher priority and required attrs to be kept, got: %s", output)
+	}
+	if !strings.Contains(output, "dropped_attrs=1") {
+		t.Errorf("Expected dropped_attrs count, got: %s", output)
+	}
+
+	buf.Reset()
+	logger.Info("small", Optional(slog.String("trace", "x"), 0))
+	if output := buf.String(); !strings.Contains(output, "trace=x") || strings.Contains(output, "dropped_attrs") {
+		t.Errorf("Expected optional attr to be kept under budget, got: %s", output)
+	}
+}
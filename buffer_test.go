@@ -0,0 +1,129 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer 是并发安全的 bytes.Buffer
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestBufferedWriterFlush(t *testing.T) {
+	out := &syncBuffer{}
+	w := NewBufferedWriter(out, 1024, time.Hour)
+
+	w.Write([]byte("hello\n"))
+	if out.String() != "" {
+		t.Errorf("Expected data to stay buffered, got %q", out.String())
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if out.String() != "hello\n" {
+		t.Errorf("Expected data after Flush, got %q", out.String())
+	}
+
+	w.Write([]byte("bye\n"))
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if out.String() != "hello\nbye\n" {
+		t.Errorf("Expected Close to flush remaining data, got %q", out.String())
+	}
+}
+
+func TestBufferedWriterPeriodicFlush(t *testing.T) {
+	out := &syncBuffer{}
+	w := NewBufferedWriter(out, 1024, 10*time.Millisecond)
+	defer w.Close()
+
+	w.Write([]byte("tick\n"))
+	deadline := time.Now().Add(time.Second)
+	for out.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if out.String() != "tick\n" {
+		t.Errorf("Expected periodic flush, got %q", out.String())
+	}
+}
+
+func TestLoggerBufferedFileSync(t *testing.T) {
+	filename := t.TempDir() + "/test.log"
+	logger := NewLogger(Config{
+		Level:         slog.LevelDebug,
+		Filename:      filename,
+		BufferSize:    64 * 1024,
+		FlushInterval: time.Hour,
+	})
+	defer logger.Close()
+
+	logger.Info("buffered message")
+	if content, _ := os.ReadFile(filename); len(content) != 0 {
+		t.Errorf("Expected message to stay buffered, got %q", content)
+	}
+	if err := logger.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "buffered message") {
+		t.Errorf("Expected message after Sync, got %q", content)
+	}
+}
+
+// closeCounter 记录 Close 调用次数
+type closeCounter struct {
+	syncBuffer
+	closes int
+}
+
+func (c *closeCounter) Close() error {
+	c.closes++
+	return nil
+}
+
+func TestBufferedWriterCloseTwice(t *testing.T) {
+	out := &closeCounter{}
+	w := NewBufferedWriter(out, 1024, time.Hour)
+
+	w.Write([]byte("hello\n"))
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Second Close failed: %v", err)
+	}
+	if out.closes != 1 {
+		t.Errorf("Expected underlying writer closed once, got %d", out.closes)
+	}
+
+	if _, err := w.Write([]byte("late\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected os.ErrClosed after Close, got %v", err)
+	}
+	if out.String() != "hello\n" {
+		t.Errorf("Expected no data after Close, got %q", out.String())
+	}
+}
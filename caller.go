@@ -0,0 +1,285 @@
+package log
+
+import (
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultCallerTemplate 是默认的调用位置模板，输出形如 [log_test.go:42]
+const DefaultCallerTemplate = "[{file}:{line}]"
+
+// 调用位置模板支持的占位符：
+//
+//	{file}      文件名，例如 main.go
+//	{shortpath} 所在目录加文件名，例如 cmd/main.go
+//	{path}      完整路径，配合 "vscode://file{path}:{line}" 等模板可生成可点击的链接
+//	{relpath}   相对主模块根目录的路径，例如 internal/server/http.go；其他模块的文件输出为包路径加文件名
+//	{line}      行号
+//	{func}      简短函数名，例如 (*Server).Serve
+//	{fullfunc}  带包路径的完整函数名
+//
+// Go 运行时的 PC 表只记录行号，不记录列号，因此不提供列占位符。
+const (
+	callerLiteral = iota
+	callerFile
+	callerShortPath
+	callerPath
+	callerRelPath
+	callerLine
+	callerFunc
+	callerFullFunc
+)
+
+var callerPlaceholders = map[string]int{
+	"file":      callerFile,
+	"shortpath": callerShortPath,
+	"path":      callerPath,
+	"relpath":   callerRelPath,
+	"line":      callerLine,
+	"func":      callerFunc,
+	"fullfunc":  callerFullFunc,
+}
+
+type callerSegment struct {
+	kind int
+	text string
+}
+
+// callerTemplate 是预先解析好的调用位置模板，避免每条日志重复解析
+type callerTemplate []callerSegment
+
+// parseCallerTemplate 解析调用位置模板，未知的占位符按原样输出
+func parseCallerTemplate(s string) callerTemplate {
+	var t callerTemplate
+	for s != "" {
+		start := strings.IndexByte(s, '{')
+		if start < 0 {
+			t = append(t, callerSegment{kind: callerLiteral, text: s})
+			break
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			t = append(t, callerSegment{kind: callerLiteral, text: s})
+			break
+		}
+		end += start
+		if start > 0 {
+			t = append(t, callerSegment{kind: callerLiteral, text: s[:start]})
+		}
+		if kind, ok := callerPlaceholders[s[start+1:end]]; ok {
+			t = append(t, callerSegment{kind: kind})
+		} else {
+			t = append(t, callerSegment{kind: callerLiteral, text: s[start : end+1]})
+		}
+		s = s[end+1:]
+	}
+	return t
+}
+
+var defaultCallerTemplate = parseCallerTemplate(DefaultCallerTemplate)
+
+// format 按模板渲染调用位置
+func (t callerTemplate) format(frame runtime.Frame) string {
+	var b strings.Builder
+	for _, seg := range t {
+		switch seg.kind {
+		case callerLiteral:
+			b.WriteString(seg.text)
+		case callerFile:
+			b.WriteString(filepath.Base(frame.File))
+		case callerShortPath:
+			b.WriteString(filepath.Base(filepath.Dir(frame.File)))
+			b.WriteString("/")
+			b.WriteString(filepath.Base(frame.File))
+		case callerPath:
+			b.WriteString(frame.File)
+		case callerRelPath:
+			b.WriteString(relativePath(frame))
+		case callerLine:
+			b.WriteString(strconv.Itoa(frame.Line))
+		case callerFunc:
+			b.WriteString(shortFuncName(frame.Function))
+		case callerFullFunc:
+			b.WriteString(frame.Function)
+		}
+	}
+	return b.String()
+}
+
+// CallerFormat 是常用的调用位置格式预设，每种预设对应一个调用位置模板，需要更灵活的格式时使用 Config.CallerTemplate
+type CallerFormat int
+
+const (
+	CallerFileLine     CallerFormat = iota // [main.go:42]（默认）
+	CallerFileOnly                         // [main.go]
+	CallerFileLineFunc                     // [main.go:42 main.run]
+	CallerFullPath                         // [/home/app/cmd/server/main.go:42]
+	CallerRelativePath                     // [cmd/server/main.go:42]，相对主模块根目录
+)
+
+// Template 返回预设对应的调用位置模板
+func (f CallerFormat) Template() string {
+	switch f {
+	case CallerFileOnly:
+		return "[{file}]"
+	case CallerFileLineFunc:
+		return "[{file}:{line} {func}]"
+	case CallerFullPath:
+		return "[{path}:{line}]"
+	case CallerRelativePath:
+		return "[{relpath}:{line}]"
+	default:
+		return DefaultCallerTemplate
+	}
+}
+
+var (
+	mainModule = sync.OnceValue(func() string {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			return bi.Main.Path
+		}
+		return ""
+	})
+	// moduleRoot 是从主模块中非 main 包的文件推算出的模块根目录，用于计算 main 包文件的相对路径
+	moduleRoot atomic.Pointer[string]
+)
+
+// relativePath 返回文件相对主模块根目录的路径。包路径以主模块路径开头时，
+// 相对目录即包路径去掉模块路径的部分；main 包的包路径不含目录信息，使用推算出的模块根目录，推算之前退化为 {shortpath}
+func relativePath(frame runtime.Frame) string {
+	file := filepath.ToSlash(frame.File)
+	base := filepath.Base(file)
+	pkg := packagePath(frame.Function)
+	mod := mainModule()
+	if pkg == "main" {
+		if root := moduleRoot.Load(); root != nil && strings.HasPrefix(file, *root) {
+			return file[len(*root):]
+		}
+		return filepath.Base(filepath.Dir(file)) + "/" + base
+	}
+	if mod == "" || (pkg != mod && !strings.HasPrefix(pkg, mod+"/")) {
+		return pkg + "/" + base
+	}
+	rel := strings.TrimPrefix(strings.TrimPrefix(pkg, mod), "/")
+	if rel != "" {
+		rel += "/"
+	}
+	if moduleRoot.Load() == nil {
+		if root, ok := strings.CutSuffix(file, rel+base); ok {
+			moduleRoot.Store(&root)
+		}
+	}
+	return rel + base
+}
+
+// packagePath 返回函数名中的包路径，例如
+// "github.com/luojiego/slogx.(*Logger).Info" -> "github.com/luojiego/slogx"
+func packagePath(name string) string {
+	slash := strings.LastIndexByte(name, '/') + 1
+	if i := strings.IndexByte(name[slash:], '.'); i >= 0 {
+		return name[:slash+i]
+	}
+	return name
+}
+
+// shortFuncName 去掉函数名中的包路径，例如
+// "github.com/luojiego/slogx.(*Logger).Info" -> "(*Logger).Info"
+func shortFuncName(name string) string {
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// slogxPackage 是本包的包路径，查找调用位置时跳过其中的栈帧
+var slogxPackage = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	return packagePath(runtime.FuncForPC(pc).Name())
+}()
+
+// maxCallerDepth 是查找调用位置时最多检查的栈帧数
+const maxCallerDepth = 32
+
+// callerPC 返回本模块和登记的封装包之外第一个调用方的 PC，再向外跳过 skip 层（用户自己封装日志的函数）。
+// 按包路径而不是固定的层数跳过，调用位置不受库内部调用深度（With、WithField、包级别函数等）的影响；
+// 本包的测试文件不跳过，测试中的调用位置是测试函数。返回值可以直接作为 slog.Record 的 PC。
+func callerPC(skip int) uintptr {
+	var pcs [maxCallerDepth]uintptr
+	// 跳过 runtime.Callers 和 callerPC 自身。Go 为每个逻辑栈帧（包括被内联的函数）返回单独的 PC
+	n := runtime.Callers(2, pcs[:])
+	for i, pc := range pcs[:n] {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		if isLibraryFrame(frame) {
+			continue
+		}
+		if i+skip < n {
+			return pcs[i+skip]
+		}
+		return pc
+	}
+	return 0
+}
+
+var (
+	wrapperMu       sync.Mutex
+	wrapperPackages atomic.Pointer[map[string]bool] // 写时复制，查找调用位置时无锁读取
+)
+
+// RegisterWrapperPackage 登记封装本库的包，查找调用位置和清理调用栈时像本库一样跳过其中的栈帧，
+// 调用位置为封装函数的调用方，不再需要按封装的层数计算 WithCallerSkip。通常在封装包的 init 中调用：
+//
+//	func init() {
+//		log.RegisterWrapperPackage("example.com/platform/logging")
+//	}
+//
+// importPath 是完整的包路径，只匹配该包本身，子包需要分别登记。包的测试文件不跳过。
+func RegisterWrapperPackage(importPath string) {
+	wrapperMu.Lock()
+	defer wrapperMu.Unlock()
+	next := map[string]bool{importPath: true}
+	if cur := wrapperPackages.Load(); cur != nil {
+		for p := range *cur {
+			next[p] = true
+		}
+	}
+	wrapperPackages.Store(&next)
+}
+
+// isLibraryFrame 判断栈帧是否属于本模块或登记的封装包的非测试代码
+func isLibraryFrame(frame runtime.Frame) bool {
+	pkg := packagePath(frame.Function)
+	if pkg != slogxPackage && !strings.HasPrefix(pkg, slogxPackage+"/") {
+		wrappers := wrapperPackages.Load()
+		if wrappers == nil || !(*wrappers)[pkg] {
+			return false
+		}
+	}
+	return !strings.HasSuffix(frame.File, "_test.go")
+}
+
+// sourceValue 返回与 slog 的 AddSource 结构相同的调用位置分组
+func sourceValue(frame runtime.Frame) slog.Value {
+	return slog.GroupValue(
+		slog.String("function", frame.Function),
+		slog.String("file", frame.File),
+		slog.Int("line", frame.Line),
+	)
+}
+
+// frameForPC 通过 runtime.CallersFrames 解析 PC，可以正确处理被内联的函数
+func frameForPC(pc uintptr) (runtime.Frame, bool) {
+	if pc == 0 {
+		return runtime.Frame{}, false
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return frame, frame.File != ""
+}
@@ -0,0 +1,198 @@
+package log
+
+import (
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCallerTemplate(t *testing.T) {
+	frame := runtime.Frame{
+		File:     "/home/dev/app/internal/server/handler.go",
+		Line:     42,
+		Function: "example.com/app/internal/server.(*Server).Serve",
+	}
+
+	cases := map[string]string{
+		DefaultCallerTemplate:           "[handler.go:42]",
+		"{shortpath}:{line} {func}":     "server/handler.go:42 (*Server).Serve",
+		"vscode://file{path}:{line}":    "vscode://file/home/dev/app/internal/server/handler.go:42",
+		"{fullfunc}":                    "example.com/app/internal/server.(*Server).Serve",
+		"{file}:{line}:{col} {unclosed": "handler.go:42:{col} {unclosed",
+	}
+	for tmpl, want := range cases {
+		if got := parseCallerTemplate(tmpl).format(frame); got != want {
+			t.Errorf("template %q: got %q, want %q", tmpl, got, want)
+		}
+	}
+}
+
+func TestShortFuncName(t *testing.T) {
+	cases := map[string]string{
+		"github.com/luojiego/slogx.(*Logger).Info": "(*Logger).Info",
+		"main.main":               "main",
+		"main.logSomething.func1": "logSomething.func1",
+	}
+	for name, want := range cases {
+		if got := shortFuncName(name); got != want {
+			t.Errorf("shortFuncName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestCallerFormatRelativePath(t *testing.T) {
+	moduleRoot.Store(nil)
+	defer moduleRoot.Store(nil)
+	tmpl := parseCallerTemplate(CallerRelativePath.Template())
+
+	// 模块根目录推算出来之前，main 包退化为 {shortpath}
+	mainFrame := runtime.Frame{File: "/src/slogx/cmd/app/main.go", Line: 7, Function: "main.main"}
+	if got := tmpl.format(mainFrame); got != "[app/main.go:7]" {
+		t.Errorf("got %q, want [app/main.go:7]", got)
+	}
+
+	frame := runtime.Frame{File: "/src/slogx/internal/x/f.go", Line: 3, Function: "github.com/luojiego/slogx/internal/x.(*T).F"}
+	if got := tmpl.format(frame); got != "[internal/x/f.go:3]" {
+		t.Errorf("got %q, want [internal/x/f.go:3]", got)
+	}
+	if got := tmpl.format(mainFrame); got != "[cmd/app/main.go:7]" {
+		t.Errorf("got %q, want [cmd/app/main.go:7]", got)
+	}
+
+	other := runtime.Frame{File: "/go/pkg/mod/example.com/lib@v1.0.0/lib.go", Line: 9, Function: "example.com/lib.Do"}
+	if got := tmpl.format(other); got != "[example.com/lib/lib.go:9]" {
+		t.Errorf("got %q, want [example.com/lib/lib.go:9]", got)
+	}
+}
+
+func TestCallerFormatPresets(t *testing.T) {
+	frame := runtime.Frame{File: "/app/main.go", Line: 42, Function: "main.run"}
+	cases := map[CallerFormat]string{
+		CallerFileLine:     "[main.go:42]",
+		CallerFileOnly:     "[main.go]",
+		CallerFileLineFunc: "[main.go:42 run]",
+		CallerFullPath:     "[/app/main.go:42]",
+	}
+	for f, want := range cases {
+		if got := parseCallerTemplate(f.Template()).format(frame); got != want {
+			t.Errorf("CallerFormat %d: got %q, want %q", f, got, want)
+		}
+	}
+}
+
+// logThroughWrapper 模拟调用方自己封装的日志函数
+func logThroughWrapper(l *Logger, msg string) {
+	l.Info(msg)
+}
+
+func TestCallerSkipsLibraryFrames(t *testing.T) {
+	var buf strings.Builder
+	logger := NewLogger(Config{Writer: &buf, CallerFormat: CallerFileLineFunc})
+	old := GetDefaultLogger()
+	SetDefaultLogger(logger)
+	defer SetDefaultLogger(old)
+
+	logger.Info("method")
+	Info("package function")
+	WithField("k", "v").Info("with field")
+	logThroughWrapper(logger, "wrapper")
+	logThroughWrapper(logger.WithCallerSkip(1), "skipped wrapper")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("Expected 5 lines, got %q", buf.String())
+	}
+	for i, line := range lines {
+		want := "caller_test.go:"
+		fn := "TestCallerSkipsLibraryFrames]"
+		if i == 3 {
+			fn = "logThroughWrapper]"
+		}
+		if !strings.Contains(line, want) || !strings.Contains(line, fn) {
+			t.Errorf("Expected caller in %s, got %s", fn, line)
+		}
+	}
+}
+
+func TestCallerSource(t *testing.T) {
+	for _, format := range []string{"json", "text", "logfmt"} {
+		var buf strings.Builder
+		logger := NewLogger(Config{Writer: &buf, Format: format, CallerSource: true})
+		logger.Info("structured caller")
+		output := buf.String()
+		for _, want := range []string{"function", "TestCallerSource", "file", "caller_test.go", "line"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("%s: expected %q in %s", format, want, output)
+			}
+		}
+		if strings.Contains(output, "[caller_test.go:") {
+			t.Errorf("%s: expected no formatted caller, got %s", format, output)
+		}
+		if format == "json" && !strings.Contains(output, `"source":{"function":"github.com/luojiego/slogx.TestCallerSource","file":"`) {
+			t.Errorf("Expected the slog.Source layout, got %s", output)
+		}
+	}
+}
+
+func TestCallerSourceFormats(t *testing.T) {
+	for format, want := range map[string]string{
+		"console":  "caller_test.go:",
+		"csv":      "caller_test.go:",
+		"template": "caller_test.go:",
+		"protobuf": "caller_test.go:",
+		"msgpack":  "caller_test.go:",
+		"clef":     `"source":{"function":"github.com/luojiego/slogx.TestCallerSourceFormats","file":"`,
+		"rfc5424":  `source.function="github.com/luojiego/slogx.TestCallerSourceFormats"`,
+		"ecs":      `"log.origin.function":"github.com/luojiego/slogx.TestCallerSourceFormats"`,
+	} {
+		var buf strings.Builder
+		logger := NewLogger(Config{Writer: &buf, Format: format, CallerSource: true})
+		logger.Info("structured caller")
+		output := buf.String()
+		if !strings.Contains(output, want) {
+			t.Errorf("%s: expected %q in %q", format, want, output)
+		}
+		if n := strings.Count(output, "caller_test.go"); n != 1 {
+			t.Errorf("%s: expected the caller once, got %d in %q", format, n, output)
+		}
+	}
+}
+
+func TestAddCaller(t *testing.T) {
+	var buf strings.Builder
+	off := false
+	logger := NewLogger(Config{Writer: &buf, Format: "json", AddCaller: &off})
+	logger.Info("no caller")
+	logger.With("k", "v").Warn("still no caller")
+	if strings.Contains(buf.String(), `"source"`) {
+		t.Errorf("Expected no caller, got %s", buf.String())
+	}
+
+	// 级别未开启的记录不获取调用位置，没有任何分配
+	logger = NewLogger(Config{Writer: &buf, Level: slog.LevelInfo})
+	if allocs := testing.AllocsPerRun(100, func() { logger.Debug("dropped") }); allocs != 0 {
+		t.Errorf("Expected no allocations for a disabled level, got %v", allocs)
+	}
+}
+
+func TestRegisterWrapperPackage(t *testing.T) {
+	frame := func(fn, file string) runtime.Frame { return runtime.Frame{Function: fn, File: file} }
+	wrapper := frame("example.com/platform/logging.Info", "/src/logging/logging.go")
+	if isLibraryFrame(wrapper) {
+		t.Fatal("Expected an unregistered package not to be skipped")
+	}
+	saved := wrapperPackages.Load()
+	t.Cleanup(func() { wrapperPackages.Store(saved) })
+	RegisterWrapperPackage("example.com/platform/logging")
+	if !isLibraryFrame(wrapper) || !isLibraryFrame(frame("example.com/platform/logging.(*Logger).Warn", "/src/logging/logger.go")) {
+		t.Error("Expected frames of the registered package to be skipped")
+	}
+	if isLibraryFrame(frame("example.com/platform/logging/sub.Info", "/src/logging/sub/sub.go")) ||
+		isLibraryFrame(frame("example.com/platform/logging.TestInfo", "/src/logging/logging_test.go")) {
+		t.Error("Expected subpackages and test files not to be skipped")
+	}
+	if !isLibraryFrame(frame("github.com/luojiego/slogx.(*Logger).Info", "/src/slogx/log.go")) {
+		t.Error("Expected slogx frames to still be skipped")
+	}
+}
@@ -0,0 +1,293 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// CheckpointMessage 是检查点记录的消息，检查点的字段放在 checkpoint 分组中
+const CheckpointMessage = "checkpoint"
+
+// Checkpoint 是长任务的进度：Offset 是输入中的位置，Processed 是已处理的条目数，
+// LastKey 是最后处理的条目的 key，可作为恢复时的起点（resume token）
+type Checkpoint struct {
+	Job       string
+	Offset    int64
+	Processed int64
+	LastKey   string
+	Time      time.Time // 检查点记录的时间
+}
+
+// LogValue 以 checkpoint 分组的形式输出检查点
+func (c Checkpoint) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("job", c.Job),
+		slog.Int64("offset", c.Offset),
+		slog.Int64("processed", c.Processed),
+		slog.String("last_key", c.LastKey),
+	)
+}
+
+// Checkpointer 定期把长任务的进度写入日志，进程崩溃后可以用 RecoverCheckpoint 从日志中找回最后的进度：
+//
+//	cp := logger.NewCheckpointer("import", 10*time.Second)
+//	defer cp.Close()
+//	for ... {
+//		process(item)
+//		cp.Advance(1, offset, item.Key)
+//	}
+type Checkpointer struct {
+	logger *Logger
+	mu     sync.Mutex
+	cp     Checkpoint
+	dirty  bool
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewCheckpointer 创建任务 job 的检查点，每隔 interval 在进度有变化时输出一条 Info 级别的检查点记录。
+// interval <= 0 时只在 Flush 和 Close 时输出。
+func (l *Logger) NewCheckpointer(job string, interval time.Duration) *Checkpointer {
+	c := &Checkpointer{logger: l, cp: Checkpoint{Job: job}, stop: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		defer close(c.done)
+		if interval <= 0 {
+			<-c.stop
+			return
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.flush(false)
+			case <-c.stop:
+				return
+			}
+		}
+	}()
+	return c
+}
+
+// Resume 从恢复的检查点继续计数，之后的检查点在它的基础上累加
+func (c *Checkpointer) Resume(cp Checkpoint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cp.Offset, c.cp.Processed, c.cp.LastKey = cp.Offset, cp.Processed, cp.LastKey
+}
+
+// Advance 记录又处理了 n 个条目，当前位置为 offset，最后处理的条目为 lastKey
+func (c *Checkpointer) Advance(n int, offset int64, lastKey string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cp.Processed += int64(n)
+	c.cp.Offset = offset
+	c.cp.LastKey = lastKey
+	c.dirty = true
+}
+
+// Current 返回当前进度
+func (c *Checkpointer) Current() Checkpoint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cp
+}
+
+// Flush 立即输出检查点，并等待记录写入输出；Info 级别未启用时返回 ErrNotDelivered
+func (c *Checkpointer) Flush() error {
+	return c.flush(true)
+}
+
+func (c *Checkpointer) flush(force bool) error {
+	c.mu.Lock()
+	if !c.dirty && !force {
+		c.mu.Unlock()
+		return nil
+	}
+	cp := c.cp
+	c.dirty = false
+	c.mu.Unlock()
+	// 检查点是恢复进度的依据，等待它写入所有输出，调用位置没有意义因此不记录
+	return c.logger.deliver(context.Background(), slog.LevelInfo, CheckpointMessage, 0, []any{slog.Any(CheckpointMessage, cp)})
+}
+
+// Close 停止定期输出，并在进度有变化时输出最后一个检查点
+func (c *Checkpointer) Close() error {
+	c.once.Do(func() { close(c.stop) })
+	<-c.done
+	return c.flush(false)
+}
+
+// ReadCheckpoint 从 json 或 text 格式的日志中读取任务 job 最后一个检查点，ok 表示是否找到
+func ReadCheckpoint(r io.Reader, job string) (cp Checkpoint, ok bool, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16<<20)
+	for scanner.Scan() {
+		if c, found := parseCheckpoint(scanner.Bytes()); found && c.Job == job {
+			cp, ok = c, true
+		}
+	}
+	return cp, ok, scanner.Err()
+}
+
+// RecoverCheckpoint 从日志文件 filename 中找回任务 job 最后一个检查点。当前文件中没有时依次查找
+// 按大小滚动出的备份（包括压缩的 .gz 和 .zst），从新到旧，以应对检查点之后恰好发生滚动的情况。
+func RecoverCheckpoint(filename, job string) (Checkpoint, bool, error) {
+	paths := []string{filename}
+	for _, b := range (&rotatingFile{filename: filename}).backups() {
+		paths = append(paths, b.path)
+	}
+	for _, path := range paths {
+		cp, ok, err := readCheckpointFile(path, job)
+		if err != nil && !os.IsNotExist(err) {
+			return Checkpoint{}, false, err
+		}
+		if ok {
+			return cp, true, nil
+		}
+	}
+	return Checkpoint{}, false, nil
+}
+
+func readCheckpointFile(path, job string) (Checkpoint, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Checkpoint{}, false, err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	switch filepath.Ext(path) {
+	case ".gz":
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return Checkpoint{}, false, err
+		}
+		defer gz.Close()
+		r = gz
+	case ".zst":
+		// 使用字典压缩的备份需要相同的字典才能读取，这里只支持不带字典的备份
+		zr, err := zstd.NewReader(f)
+		if err != nil {
+			return Checkpoint{}, false, err
+		}
+		defer zr.Close()
+		r = zr
+	}
+	return ReadCheckpoint(r, job)
+}
+
+// parseCheckpoint 从一行 json 或 text 格式的日志中解析检查点
+func parseCheckpoint(line []byte) (Checkpoint, bool) {
+	if !bytes.Contains(line, []byte(CheckpointMessage)) {
+		return Checkpoint{}, false
+	}
+	var cp Checkpoint
+	if bytes.HasPrefix(line, []byte("{")) {
+		var v struct {
+			Time       string `json:"time"`
+			Checkpoint *struct {
+				Job       string `json:"job"`
+				Offset    int64  `json:"offset"`
+				Processed int64  `json:"processed"`
+				LastKey   string `json:"last_key"`
+			} `json:"checkpoint"`
+		}
+		if json.Unmarshal(line, &v) != nil || v.Checkpoint == nil {
+			return Checkpoint{}, false
+		}
+		c := v.Checkpoint
+		return Checkpoint{Job: c.Job, Offset: c.Offset, Processed: c.Processed, LastKey: c.LastKey, Time: parseLogTime(v.Time)}, true
+	}
+
+	fields := textFields(string(line))
+	job, ok := fields["checkpoint.job"]
+	if !ok {
+		return Checkpoint{}, false
+	}
+	cp.Job = job
+	cp.Offset, _ = strconv.ParseInt(fields["checkpoint.offset"], 10, 64)
+	cp.Processed, _ = strconv.ParseInt(fields["checkpoint.processed"], 10, 64)
+	cp.LastKey = fields["checkpoint.last_key"]
+	cp.Time = parseLogTime(fields["time"])
+	return cp, true
+}
+
+// parseLogTime 解析默认格式（见 timeprec.go，秒以下的位数可变）或 RFC3339 格式的时间，按本地时间解释，失败时返回零值
+func parseLogTime(s string) time.Time {
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999", time.RFC3339Nano} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// textFields 解析 key=value 格式的一行，带引号的值按 Go 字符串字面量解码
+func textFields(line string) map[string]string {
+	fields := make(map[string]string)
+	for _, p := range textPairs([]byte(line)) {
+		fields[p.key] = p.value
+	}
+	return fields
+}
+
+// valueSpan 是一行日志中一个值的位置，value 是解码后的值
+type valueSpan struct {
+	key        string
+	value      string
+	start, end int
+}
+
+// textPairs 返回 key=value 格式的一行中每个值的位置，带引号的值按 Go 字符串字面量解码
+func textPairs(line []byte) []valueSpan {
+	var spans []valueSpan
+	for i := 0; i < len(line); {
+		for i < len(line) && line[i] == ' ' {
+			i++
+		}
+		eq := bytes.IndexByte(line[i:], '=')
+		if eq < 0 {
+			break
+		}
+		key := string(line[i : i+eq])
+		start := i + eq + 1
+		end := start
+		var value string
+		if end < len(line) && line[end] == '"' {
+			end++
+			for end < len(line) && line[end] != '"' {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(line) {
+				break
+			}
+			end++
+			value, _ = strconv.Unquote(string(line[start:end]))
+		} else {
+			for end < len(line) && line[end] != ' ' {
+				end++
+			}
+			value = string(line[start:end])
+		}
+		spans = append(spans, valueSpan{key: key, value: value, start: start, end: end})
+		i = end
+	}
+	return spans
+}
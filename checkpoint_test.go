@@ -0,0 +1,60 @@
+package log
+
+import (
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckpointRecover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "job.log")
+	logger := NewLogger(Config{Filename: path, Format: "json", Level: slog.LevelInfo})
+	cp := logger.NewCheckpointer("import", 0)
+	cp.Advance(10, 1024, "user-10")
+	if err := cp.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	logger.NewCheckpointer("other", 0).Close()
+	cp.Advance(5, 2048, "user-15")
+	cp.Close()
+	logger.Close()
+
+	got, ok, err := RecoverCheckpoint(path, "import")
+	if err != nil || !ok {
+		t.Fatalf("RecoverCheckpoint = %v, %v", ok, err)
+	}
+	if got.Offset != 2048 || got.Processed != 15 || got.LastKey != "user-15" || got.Time.IsZero() {
+		t.Errorf("recovered %+v", got)
+	}
+
+	logger = NewLogger(Config{Filename: path, Format: "json"})
+	defer logger.Close()
+	resumed := logger.NewCheckpointer("import", 0)
+	resumed.Resume(got)
+	resumed.Advance(1, 3000, "user-16")
+	if c := resumed.Current(); c.Processed != 16 || c.Job != "import" {
+		t.Errorf("resumed checkpoint = %+v", c)
+	}
+	resumed.Close()
+}
+
+func TestReadCheckpointText(t *testing.T) {
+	var buf strings.Builder
+	logger := NewLogger(Config{Writer: &buf, Format: "text"})
+	cp := logger.NewCheckpointer("export", 0)
+	cp.Advance(3, 42, `key "with" spaces`)
+	cp.Close()
+	logger.Close()
+
+	got, ok, err := ReadCheckpoint(strings.NewReader(buf.String()), "export")
+	if err != nil || !ok {
+		t.Fatalf("ReadCheckpoint = %v, %v; output: %s", ok, err, buf.String())
+	}
+	if got.Offset != 42 || got.Processed != 3 || got.LastKey != `key "with" spaces` {
+		t.Errorf("read %+v from %s", got, buf.String())
+	}
+	if _, ok, _ := ReadCheckpoint(strings.NewReader(buf.String()), "missing"); ok {
+		t.Error("found checkpoint for unknown job")
+	}
+}
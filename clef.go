@@ -0,0 +1,78 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// newCLEFHandler 创建 Compact Log Event Format 格式的 handler，可直接导入 Seq 等工具：
+//
+//	{"@t":"2024-05-01T12:00:00.123Z","@m":"payment failed","@l":"Error","@x":"card declined","source":"[main.go:42]",...}
+//
+// 按 CLEF 约定 Information 级别省略 @l；值为 error 的第一个属性映射为 @x，stacktrace 属性追加到 @x 之后；
+// 以 @ 开头的属性 key 转义为 @@ 开头，避免与保留字段冲突。
+func newCLEFHandler(w io.Writer, opts *slog.HandlerOptions) *formatHandler {
+	return newFormatHandler(w, opts, func(buf *bytes.Buffer, e *entry) {
+		buf.WriteString(`{"@t":`)
+		ts := e.record.Time
+		if ts.IsZero() {
+			ts = now()
+		}
+		appendJSONString(buf, ts.UTC().Format(time.RFC3339Nano))
+		buf.WriteString(`,"@m":`)
+		appendJSONString(buf, e.message.Value.String())
+		if level := clefLevel(e.record.Level); level != "Information" {
+			buf.WriteString(`,"@l":`)
+			appendJSONString(buf, level)
+		}
+
+		var exception, stack string
+		attrs := make([]slog.Attr, 0, len(e.attrs)+1)
+		for _, a := range e.attrs {
+			if err, ok := a.Value.Any().(error); ok && a.Value.Kind() == slog.KindAny && exception == "" {
+				exception = err.Error()
+				continue
+			}
+			if a.Key == ecsStackKey && a.Value.Kind() == slog.KindString {
+				stack = a.Value.String()
+				continue
+			}
+			if strings.HasPrefix(a.Key, "@") {
+				a.Key = "@" + a.Key
+			}
+			attrs = append(attrs, a)
+		}
+		if stack != "" {
+			exception = strings.TrimPrefix(exception+"\n"+stack, "\n")
+		}
+		if exception != "" {
+			buf.WriteString(`,"@x":`)
+			appendJSONString(buf, exception)
+		}
+		if e.caller != "" {
+			attrs = append(attrs, e.callerAttr())
+		}
+		appendJSONAttrs(buf, attrs, true)
+		buf.WriteString("}\n")
+	})
+}
+
+// clefLevel 将 slog 级别映射为 Seq 使用的级别名称
+func clefLevel(level slog.Level) string {
+	switch {
+	case level < slog.LevelDebug:
+		return "Verbose"
+	case level < slog.LevelInfo:
+		return "Debug"
+	case level < slog.LevelWarn:
+		return "Information"
+	case level < slog.LevelError:
+		return "Warning"
+	case level == slog.LevelError:
+		return "Error"
+	}
+	return "Fatal"
+}
@@ -0,0 +1,55 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestCLEFFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: slog.LevelDebug, Format: "clef", Writer: &buf})
+	logger.Error("payment failed", "err", errors.New("card declined"),
+		"stacktrace", "main.pay()", "@id", 7, slog.Group("order", "id", 42))
+	logger.Info("started")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", buf.String())
+	}
+	var m map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &m); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", lines[0], err)
+	}
+	want := map[string]any{
+		"@m":   "payment failed",
+		"@l":   "Error",
+		"@x":   "card declined\nmain.pay()",
+		"@@id": float64(7),
+	}
+	for k, v := range want {
+		if m[k] != v {
+			t.Errorf("%s = %v, want %v", k, m[k], v)
+		}
+	}
+	if _, ok := m["@t"].(string); !ok {
+		t.Errorf("Expected @t, got %v", m)
+	}
+	if order, _ := m["order"].(map[string]any); order["id"] != float64(42) {
+		t.Errorf("Expected nested group, got %v", m["order"])
+	}
+	if source, _ := m["source"].(string); !strings.HasSuffix(source, "]") {
+		t.Errorf("Expected caller property, got %v", m["source"])
+	}
+
+	m = nil
+	if err := json.Unmarshal([]byte(lines[1]), &m); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", lines[1], err)
+	}
+	if _, ok := m["@l"]; ok {
+		t.Errorf("Expected @l to be omitted for Information, got %v", m)
+	}
+}
@@ -0,0 +1,271 @@
+// slogx-gen 根据事件定义文件生成强类型的日志函数，避免在业务代码中直接书写字符串 key。
+//
+// 用法（通常配合 go:generate）：
+//
+//	//go:generate go run github.com/luojiego/slogx/cmd/slogx-gen -in events.json -out logevents_gen.go
+//
+// 事件定义文件格式：
+//
+//	{
+//	  "package": "logevents",
+//	  "events": [
+//	    {
+//	      "name": "PaymentFailed",
+//	      "level": "error",
+//	      "message": "payment failed",
+//	      "fields": [
+//	        {"name": "provider", "type": "string"},
+//	        {"name": "amount", "type": "int64"}
+//	      ]
+//	    }
+//	  ]
+//	}
+//
+// 生成的函数形如 logevents.PaymentFailed(ctx, provider, amount)。
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Schema 是事件定义文件的顶层结构
+type Schema struct {
+	Package string  `json:"package"`
+	Events  []Event `json:"events"`
+}
+
+// Event 描述一个事件，对应生成一个日志函数
+type Event struct {
+	Name    string  `json:"name"`    // 函数名，必须是导出的 Go 标识符
+	Level   string  `json:"level"`   // 日志级别: trace, debug, info, notice, warn, error, critical
+	Message string  `json:"message"` // 日志消息，默认使用 Name
+	Doc     string  `json:"doc"`     // 可选的函数注释
+	Fields  []Field `json:"fields"`
+}
+
+// Field 描述事件的一个字段
+type Field struct {
+	Name string `json:"name"` // 参数名
+	Key  string `json:"key"`  // 日志中的 key，默认与 Name 相同
+	Type string `json:"type"` // 字段类型，见 fieldTypes
+}
+
+// fieldTypes 定义支持的字段类型以及对应的 slog 构造函数
+var fieldTypes = map[string]string{
+	"string":        "slog.String",
+	"int":           "slog.Int",
+	"int64":         "slog.Int64",
+	"uint64":        "slog.Uint64",
+	"float64":       "slog.Float64",
+	"bool":          "slog.Bool",
+	"time.Duration": "slog.Duration",
+	"time.Time":     "slog.Time",
+	"error":         "slog.Any",
+	"any":           "slog.Any",
+}
+
+// levels 定义级别名称到 slog 和 slogx 常量的映射
+var levels = map[string]string{
+	"trace":    "slogx.LevelTrace",
+	"debug":    "slog.LevelDebug",
+	"info":     "slog.LevelInfo",
+	"notice":   "slogx.LevelNotice",
+	"warn":     "slog.LevelWarn",
+	"error":    "slog.LevelError",
+	"critical": "slogx.LevelCritical",
+}
+
+func main() {
+	in := flag.String("in", "events.json", "事件定义文件")
+	out := flag.String("out", "", "输出文件，默认输出到标准输出")
+	pkg := flag.String("pkg", "", "覆盖定义文件中的包名")
+	flag.Parse()
+
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		fatalf("read %s: %v", *in, err)
+	}
+
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		fatalf("parse %s: %v", *in, err)
+	}
+	if *pkg != "" {
+		schema.Package = *pkg
+	}
+
+	src, err := Generate(schema)
+	if err != nil {
+		fatalf("generate: %v", err)
+	}
+
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(*out, src, 0644); err != nil {
+		fatalf("write %s: %v", *out, err)
+	}
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "slogx-gen: "+format+"\n", args...)
+	os.Exit(1)
+}
+
+// Generate 根据事件定义生成格式化后的 Go 源码
+func Generate(schema Schema) ([]byte, error) {
+	if schema.Package == "" {
+		return nil, fmt.Errorf("package name is required")
+	}
+
+	var body bytes.Buffer
+	// 导入路径到别名，slogx 的包名是 log，使用别名避免与标准库的 log 混淆
+	imports := map[string]string{
+		"context":                   "",
+		"log/slog":                  "",
+		"github.com/luojiego/slogx": "slogx",
+	}
+
+	seen := make(map[string]bool)
+	for _, ev := range schema.Events {
+		if !isExported(ev.Name) {
+			return nil, fmt.Errorf("event name %q must be an exported identifier", ev.Name)
+		}
+		if seen[ev.Name] {
+			return nil, fmt.Errorf("duplicate event %q", ev.Name)
+		}
+		seen[ev.Name] = true
+
+		level, ok := levels[strings.ToLower(ev.Level)]
+		if !ok {
+			return nil, fmt.Errorf("event %s: unknown level %q", ev.Name, ev.Level)
+		}
+		msg := ev.Message
+		if msg == "" {
+			msg = ev.Name
+		}
+
+		params := []string{"ctx context.Context"}
+		attrs := make([]string, 0, len(ev.Fields))
+		names := make(map[string]bool) // 已使用的参数名
+		keys := make(map[string]bool)  // 已使用的日志 key
+		for _, f := range ev.Fields {
+			ctor, ok := fieldTypes[f.Type]
+			if !ok {
+				return nil, fmt.Errorf("event %s: field %s has unsupported type %q", ev.Name, f.Name, f.Type)
+			}
+			if strings.HasPrefix(f.Type, "time.") {
+				imports["time"] = ""
+			}
+			name := paramName(f.Name)
+			if name == "" || name == "ctx" || token.IsKeyword(name) {
+				return nil, fmt.Errorf("event %s: invalid field name %q", ev.Name, f.Name)
+			}
+			if names[name] {
+				return nil, fmt.Errorf("event %s: duplicate field name %q", ev.Name, f.Name)
+			}
+			names[name] = true
+			key := f.Key
+			if key == "" {
+				key = f.Name
+			}
+			if keys[key] {
+				return nil, fmt.Errorf("event %s: duplicate field key %q", ev.Name, key)
+			}
+			keys[key] = true
+			params = append(params, name+" "+f.Type)
+			attrs = append(attrs, fmt.Sprintf("%s(%q, %s)", ctor, key, name))
+		}
+
+		doc := ev.Doc
+		if doc == "" {
+			doc = fmt.Sprintf("记录 %q 事件", msg)
+		}
+		fmt.Fprintf(&body, "\n// %s %s\n", ev.Name, doc)
+		fmt.Fprintf(&body, "func %s(%s) {\n", ev.Name, strings.Join(params, ", "))
+		// 跳过生成的函数本身，调用位置为调用事件函数的业务代码
+		fmt.Fprintf(&body, "\tslogx.GetDefaultLogger().WithCallerSkip(1).LogAttrs(ctx, %s, %q", level, msg)
+		for _, a := range attrs {
+			body.WriteString(", " + a)
+		}
+		body.WriteString(")\n}\n")
+	}
+
+	var src bytes.Buffer
+	src.WriteString("// Code generated by slogx-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\nimport (\n", schema.Package)
+	paths := make([]string, 0, len(imports))
+	for p := range imports {
+		paths = append(paths, p)
+	}
+	// 与 goimports 相同，标准库在前，第三方包在后，两组之间空一行
+	sort.Slice(paths, func(i, j int) bool {
+		if a, b := isStdlib(paths[i]), isStdlib(paths[j]); a != b {
+			return a
+		}
+		return paths[i] < paths[j]
+	})
+	for i, p := range paths {
+		if i > 0 && isStdlib(paths[i-1]) && !isStdlib(p) {
+			src.WriteString("\n")
+		}
+		if alias := imports[p]; alias != "" {
+			fmt.Fprintf(&src, "\t%s %q\n", alias, p)
+		} else {
+			fmt.Fprintf(&src, "\t%q\n", p)
+		}
+	}
+	src.WriteString(")\n")
+	src.Write(body.Bytes())
+
+	return format.Source(src.Bytes())
+}
+
+// isStdlib 判断导入路径是否属于标准库：第一段路径中没有点
+func isStdlib(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+func isExported(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return unicode.IsUpper([]rune(name)[0])
+}
+
+// paramName 将字段名转换为 camelCase 形式的参数名，例如 "order_id" -> "orderId"
+func paramName(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range name {
+		switch {
+		case r == '_' || r == '-' || r == '.' || r == ' ':
+			upper = b.Len() > 0
+		case unicode.IsLetter(r) || (unicode.IsDigit(r) && b.Len() > 0):
+			if upper {
+				r = unicode.ToUpper(r)
+				upper = false
+			} else if b.Len() == 0 {
+				r = unicode.ToLower(r)
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	src, err := Generate(Schema{
+		Package: "logevents",
+		Events: []Event{
+			{
+				Name:    "PaymentFailed",
+				Level:   "error",
+				Message: "payment failed",
+				Fields: []Field{
+					{Name: "provider", Type: "string"},
+					{Name: "amount", Type: "int64"},
+					{Name: "retry_after", Type: "time.Duration"},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	out := string(src)
+	for _, want := range []string{
+		"package logevents",
+		`"time"`,
+		"func PaymentFailed(ctx context.Context, provider string, amount int64, retryAfter time.Duration)",
+		"\t\"time\"\n\n\tslogx \"github.com/luojiego/slogx\"\n",
+		"slogx.GetDefaultLogger().WithCallerSkip(1).LogAttrs(ctx, ",
+		`slog.LevelError, "payment failed", slog.String("provider", provider), slog.Int64("amount", amount), slog.Duration("retry_after", retryAfter))`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("generated code missing %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestGenerateRejectsInvalidSchema(t *testing.T) {
+	cases := map[string]Schema{
+		"no package":    {Events: []Event{{Name: "A", Level: "info"}}},
+		"unexported":    {Package: "p", Events: []Event{{Name: "a", Level: "info"}}},
+		"bad level":     {Package: "p", Events: []Event{{Name: "A", Level: "loud"}}},
+		"bad type":      {Package: "p", Events: []Event{{Name: "A", Level: "info", Fields: []Field{{Name: "x", Type: "chan int"}}}}},
+		"keyword field": {Package: "p", Events: []Event{{Name: "A", Level: "info", Fields: []Field{{Name: "type", Type: "string"}}}}},
+		"duplicate":     {Package: "p", Events: []Event{{Name: "A", Level: "info"}, {Name: "A", Level: "info"}}},
+		"dup field":     {Package: "p", Events: []Event{{Name: "A", Level: "info", Fields: []Field{{Name: "order_id", Type: "string"}, {Name: "orderId", Type: "int"}}}}},
+		"dup key":       {Package: "p", Events: []Event{{Name: "A", Level: "info", Fields: []Field{{Name: "a", Key: "id", Type: "string"}, {Name: "b", Key: "id", Type: "int"}}}}},
+	}
+	for name, schema := range cases {
+		if _, err := Generate(schema); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestGenerateLevels(t *testing.T) {
+	for level, want := range map[string]string{"trace": "slogx.LevelTrace", "notice": "slogx.LevelNotice", "Critical": "slogx.LevelCritical"} {
+		src, err := Generate(Schema{Package: "p", Events: []Event{{Name: "A", Level: level}}})
+		if err != nil {
+			t.Fatalf("%s: Generate failed: %v", level, err)
+		}
+		if !strings.Contains(string(src), want) {
+			t.Errorf("%s: generated code missing %s:\n%s", level, want, src)
+		}
+	}
+}
+
+// TestGeneratedCodeBuilds 在引用本模块的临时模块中编译并运行生成的代码，调用位置应为调用事件函数的代码
+func TestGeneratedCodeBuilds(t *testing.T) {
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	src, err := Generate(Schema{
+		Package: "logevents",
+		Events: []Event{
+			{Name: "OrderCreated", Level: "info", Fields: []Field{{Name: "order_id", Type: "string"}, {Name: "total", Type: "float64"}}},
+			{Name: "PaymentFailed", Level: "error", Fields: []Field{
+				{Name: "err", Type: "error"},
+				{Name: "elapsed", Type: "time.Duration"},
+				{Name: "at", Type: "time.Time"},
+				{Name: "extra", Type: "any"},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	gomod := "module gentest\n\ngo 1.22\n\nrequire github.com/luojiego/slogx v0.0.0\n\nreplace github.com/luojiego/slogx => " + root + "\n"
+	main := `package main
+
+import (
+	"context"
+	"os"
+
+	slogx "github.com/luojiego/slogx"
+	"gentest/logevents"
+)
+
+func main() {
+	slogx.SetDefaultLogger(slogx.NewLogger(slogx.Config{Writer: os.Stdout, Format: "json"}))
+	logevents.OrderCreated(context.Background(), "A1", 9.5)
+}
+`
+	if err := os.Mkdir(filepath.Join(dir, "logevents"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string][]byte{"go.mod": []byte(gomod), "go.sum": sum, "main.go": []byte(main), "logevents/logevents_gen.go": src} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command(gobin, "run", "-mod=mod", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=", "GOPROXY=off")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("generated code does not build: %v\n%s\n%s", err, out, src)
+	}
+	if !strings.Contains(string(out), `"source":"[main.go:13]"`) {
+		t.Errorf("Expected the caller of the event function as source, got %s", out)
+	}
+}
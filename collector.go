@@ -0,0 +1,187 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// collector 线格式：多进程场景下，业务进程（发送端）通过 Unix socket 或 TCP 把日志交给
+// 独立的采集进程。连接建立后双方先交换一次握手协商版本和能力，之后按帧传输：
+//
+//	握手（发送端 -> 采集端）：magic "SLGX" | 最低版本 1B | 最高版本 1B | 能力 4B（大端）
+//	握手（采集端 -> 发送端）：magic "SLGX" | 选定版本 1B（0 表示拒绝）| 双方都支持的能力 4B
+//	帧：类型 1B | 标志 1B | 长度 uvarint | 负载
+//
+// 新版本只能追加帧类型和能力位。读取方跳过不认识的帧类型，未协商的能力不会被使用，
+// 因此旧的发送端和新的采集端（反之亦然）可以互通。
+
+// CollectorMagic 是握手的开头，用于识别误连的其他协议
+const CollectorMagic = "SLGX"
+
+// 本实现支持的线格式版本范围
+const (
+	CollectorMinVersion = 1
+	CollectorMaxVersion = 1
+)
+
+// CollectorCaps 是握手时协商的能力位
+type CollectorCaps uint32
+
+const (
+	CollectorCompression CollectorCaps = 1 << iota // 帧负载可以 gzip 压缩
+	CollectorAcks                                  // 采集端对 FrameRecords 回复 FrameAck
+)
+
+// FrameType 是帧的类型
+type FrameType uint8
+
+const (
+	FrameRecords FrameType = 1 // 负载为一批编码后的记录，格式由使用方约定，通常为 protobuf 格式
+	FrameAck     FrameType = 2 // 负载为 uvarint 编码的已确认 FrameRecords 数量
+)
+
+// frameCompressed 标记负载经过 gzip 压缩
+const frameCompressed = 1
+
+// ErrCollectorVersion 表示双方没有共同支持的线格式版本
+var ErrCollectorVersion = errors.New("slogx: no common collector wire version")
+
+// CollectorConn 是完成握手的 collector 连接，WriteFrame 和 ReadFrame 分别只能由一个 goroutine 调用
+type CollectorConn struct {
+	Version uint8         // 协商出的版本
+	Caps    CollectorCaps // 双方都支持的能力
+
+	r *bufio.Reader
+	w io.Writer
+}
+
+// DialCollector 在已建立的连接上以发送端身份握手，caps 是发送端支持的能力
+func DialCollector(rw io.ReadWriter, caps CollectorCaps) (*CollectorConn, error) {
+	if _, err := rw.Write(appendHello(nil, CollectorMinVersion, CollectorMaxVersion, caps)); err != nil {
+		return nil, err
+	}
+	c := &CollectorConn{r: bufio.NewReader(rw), w: rw}
+	version, _, accepted, err := readHello(c.r, false)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		return nil, ErrCollectorVersion
+	}
+	c.Version, c.Caps = version, accepted&caps
+	return c, nil
+}
+
+// AcceptCollector 在已建立的连接上以采集端身份握手，caps 是采集端支持的能力。
+// 版本不兼容时回复拒绝并返回 ErrCollectorVersion。
+func AcceptCollector(rw io.ReadWriter, caps CollectorCaps) (*CollectorConn, error) {
+	c := &CollectorConn{r: bufio.NewReader(rw), w: rw}
+	min, max, peer, err := readHello(c.r, true)
+	if err != nil {
+		return nil, err
+	}
+	var version uint8
+	if min <= CollectorMaxVersion && max >= CollectorMinVersion {
+		version = max
+		if version > CollectorMaxVersion {
+			version = CollectorMaxVersion
+		}
+	}
+	c.Version, c.Caps = version, caps&peer
+	if _, err := rw.Write(appendHello(nil, version, 0, c.Caps)); err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		return nil, ErrCollectorVersion
+	}
+	return c, nil
+}
+
+// appendHello 编码握手。发送端写入版本范围，采集端只写入选定版本（max 不编码）。
+func appendHello(b []byte, min, max uint8, caps CollectorCaps) []byte {
+	b = append(b, CollectorMagic...)
+	b = append(b, min)
+	if max != 0 {
+		b = append(b, max)
+	}
+	return binary.BigEndian.AppendUint32(b, uint32(caps))
+}
+
+func readHello(r io.Reader, withRange bool) (min, max uint8, caps CollectorCaps, err error) {
+	n := len(CollectorMagic) + 5
+	if withRange {
+		n++
+	}
+	b := make([]byte, n)
+	if _, err = io.ReadFull(r, b); err != nil {
+		return 0, 0, 0, noEOF(err)
+	}
+	if string(b[:len(CollectorMagic)]) != CollectorMagic {
+		return 0, 0, 0, fmt.Errorf("slogx: bad collector handshake %q", b[:len(CollectorMagic)])
+	}
+	b = b[len(CollectorMagic):]
+	min = b[0]
+	if withRange {
+		max, b = b[1], b[1:]
+	}
+	return min, max, CollectorCaps(binary.BigEndian.Uint32(b[1:])), nil
+}
+
+// WriteFrame 写入一帧，协商了压缩时负载使用 gzip 压缩
+func (c *CollectorConn) WriteFrame(typ FrameType, payload []byte) error {
+	var flags byte
+	if c.Caps&CollectorCompression != 0 && len(payload) > 0 {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(payload)
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		payload, flags = buf.Bytes(), frameCompressed
+	}
+	b := binary.AppendUvarint([]byte{byte(typ), flags}, uint64(len(payload)))
+	_, err := c.w.Write(append(b, payload...))
+	return err
+}
+
+// ReadFrame 读取下一个本版本认识的帧，跳过未知类型的帧，连接正常结束时返回 io.EOF
+func (c *CollectorConn) ReadFrame() (FrameType, []byte, error) {
+	for {
+		typ, err := c.r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		flags, err := c.r.ReadByte()
+		if err != nil {
+			return 0, nil, noEOF(err)
+		}
+		n, err := binary.ReadUvarint(c.r)
+		if err != nil {
+			return 0, nil, noEOF(err)
+		}
+		payload := make([]byte, n)
+		if _, err := io.ReadFull(c.r, payload); err != nil {
+			return 0, nil, noEOF(err)
+		}
+		switch FrameType(typ) {
+		case FrameRecords, FrameAck:
+		default:
+			continue
+		}
+		if flags&frameCompressed != 0 {
+			zr, err := gzip.NewReader(bytes.NewReader(payload))
+			if err != nil {
+				return 0, nil, err
+			}
+			if payload, err = io.ReadAll(zr); err != nil {
+				return 0, nil, err
+			}
+		}
+		return FrameType(typ), payload, nil
+	}
+}
@@ -0,0 +1,59 @@
+package log
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestCollectorHandshakeAndFrames(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	done := make(chan *CollectorConn)
+	go func() {
+		c, err := AcceptCollector(server, CollectorCompression)
+		if err != nil {
+			t.Errorf("AcceptCollector failed: %v", err)
+		}
+		done <- c
+	}()
+	cc, err := DialCollector(client, CollectorCompression|CollectorAcks)
+	if err != nil {
+		t.Fatalf("DialCollector failed: %v", err)
+	}
+	sc := <-done
+	if cc.Version != 1 || cc.Caps != CollectorCompression || sc.Caps != CollectorCompression {
+		t.Fatalf("negotiated client %d/%b, server %b", cc.Version, cc.Caps, sc.Caps)
+	}
+
+	go func() {
+		// 来自更新版本的未知帧类型应被跳过
+		cc.w.Write([]byte{99, 0, 3, 'x', 'y', 'z'})
+		cc.WriteFrame(FrameRecords, []byte("records"))
+	}()
+	typ, payload, err := sc.ReadFrame()
+	if err != nil {
+		t.Fatalf("ReadFrame failed: %v", err)
+	}
+	if typ != FrameRecords || string(payload) != "records" {
+		t.Errorf("ReadFrame = %d %q", typ, payload)
+	}
+}
+
+func TestCollectorVersionRejected(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go func() {
+		// 只支持版本 2 以上的发送端
+		client.Write(appendHello(nil, 2, 3, 0))
+		b := make([]byte, len(CollectorMagic)+5)
+		client.Read(b)
+	}()
+	if _, err := AcceptCollector(server, 0); !errors.Is(err, ErrCollectorVersion) {
+		t.Errorf("AcceptCollector error = %v, want ErrCollectorVersion", err)
+	}
+}
@@ -0,0 +1,141 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+	"unicode"
+)
+
+const (
+	consoleTimeLayout  = "15:04:05.000"
+	consoleCallerWidth = 24 // 调用位置列的对齐宽度
+)
+
+// ANSI 颜色，与 zap 开发模式的配色一致
+const (
+	colorReset   = "\x1b[0m"
+	colorRed     = "\x1b[31m"
+	colorYellow  = "\x1b[33m"
+	colorBlue    = "\x1b[34m"
+	colorMagenta = "\x1b[35m"
+	colorFaint   = "\x1b[2m"
+)
+
+// newConsoleHandler 创建面向人阅读的 console 格式 handler：
+//
+//	15:04:05.000 INFO  [main.go:42]             user logged in  user_id=123 ip=10.0.0.1
+//
+// color 为 true 时级别会按颜色区分。
+func newConsoleHandler(w io.Writer, opts *slog.HandlerOptions, color bool) *formatHandler {
+	return newFormatHandler(w, opts, func(buf *bytes.Buffer, e *entry) {
+		if e.time.Key != "" {
+			if color {
+				buf.WriteString(colorFaint)
+			}
+			buf.WriteString(e.timeText(consoleTimeLayout))
+			if color {
+				buf.WriteString(colorReset)
+			}
+			buf.WriteByte(' ')
+		}
+		if e.level.Key != "" {
+			level := e.levelText()
+			if color {
+				buf.WriteString(levelColor(e.record.Level))
+			}
+			fmt.Fprintf(buf, "%-5s", level)
+			if color {
+				buf.WriteString(colorReset)
+			}
+			buf.WriteByte(' ')
+		}
+		if e.caller != "" {
+			fmt.Fprintf(buf, "%-*s ", consoleCallerWidth, e.caller)
+		}
+		if e.message.Key != "" {
+			buf.WriteString(e.message.Value.String())
+		}
+		for _, a := range e.attrs {
+			appendConsoleAttr(buf, "", a, color)
+		}
+		buf.WriteByte('\n')
+	})
+}
+
+func levelColor(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return colorRed
+	case level >= slog.LevelWarn:
+		return colorYellow
+	case level >= slog.LevelInfo:
+		return colorBlue
+	default:
+		return colorMagenta
+	}
+}
+
+// appendConsoleAttr 以 key=value 形式输出属性，分组展开为以点分隔的 key
+func appendConsoleAttr(buf *bytes.Buffer, prefix string, a slog.Attr, color bool) {
+	if a.Value.Kind() == slog.KindGroup {
+		for _, ga := range a.Value.Group() {
+			appendConsoleAttr(buf, prefix+a.Key+".", ga, color)
+		}
+		return
+	}
+	buf.WriteString("  ")
+	if color {
+		buf.WriteString(colorFaint)
+	}
+	buf.WriteString(prefix)
+	buf.WriteString(a.Key)
+	buf.WriteByte('=')
+	if color {
+		buf.WriteString(colorReset)
+	}
+	buf.WriteString(quoteIfNeeded(valueText(a.Value)))
+}
+
+// valueText 返回属性值的文本表示
+func valueText(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return err.Error()
+		}
+	}
+	return v.String()
+}
+
+// quoteIfNeeded 在值包含空白、引号、等号或不可打印字符时加引号
+func quoteIfNeeded(s string) string {
+	if s == "" {
+		return `""`
+	}
+	for _, r := range s {
+		if unicode.IsSpace(r) || r == '"' || r == '=' || !unicode.IsPrint(r) {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
+
+// isTerminal 判断 w 是否为终端，遵循 NO_COLOR 约定（https://no-color.org）
+func isTerminal(w io.Writer) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,48 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestConsoleFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: slog.LevelDebug, Format: "console", Writer: &buf})
+	logger.With("module", "auth").Warn("login failed", "user", "bob smith", "attempts", 3)
+	slog.New(logger.Handler()).WithGroup("http").Info("request", "method", "GET")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", buf.String())
+	}
+
+	pattern := `^\d\d:\d\d:\d\d\.\d{3} WARN  \[.+:\d+\]\s+login failed  module=auth  user="bob smith"  attempts=3$`
+	if !regexp.MustCompile(pattern).MatchString(lines[0]) {
+		t.Errorf("Unexpected console line: %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "INFO  request  http.method=GET") {
+		t.Errorf("Expected grouped attrs as dotted keys, got %q", lines[1])
+	}
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Errorf("Expected no color codes for non-terminal output, got %q", buf.String())
+	}
+}
+
+func TestQuoteIfNeeded(t *testing.T) {
+	cases := map[string]string{
+		"plain":    "plain",
+		"":         `""`,
+		"a b":      `"a b"`,
+		"k=v":      `"k=v"`,
+		"line\nx":  `"line\nx"`,
+		`say "hi"`: `"say \"hi\""`,
+	}
+	for in, want := range cases {
+		if got := quoteIfNeeded(in); got != want {
+			t.Errorf("quoteIfNeeded(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+)
+
+type loggerKey struct{}
+
+// IntoContext 返回携带 logger 的 context，请求范围内绑定了请求属性的 logger 可以随 context 传递到调用栈深处：
+//
+//	logger := log.With("request_id", id, "user", user)
+//	ctx = log.IntoContext(ctx, logger)
+//	...
+//	log.FromContext(ctx).Info("order created")
+func IntoContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext 返回 IntoContext 放入 ctx 的 logger，没有时返回默认 Logger
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerKey{}).(*Logger); ok && l != nil {
+			return l
+		}
+	}
+	return defaultLogger
+}
+
+type attrsKey struct{}
+
+// ContextWithAttrs 返回携带属性的 context，参数与 With 相同。使用 InfoContext 等带 ctx 的方法输出时，
+// 这些属性在输出时才从 ctx 中取出并附加到记录，调用栈深处的代码只要传递 ctx 即可继承请求属性：
+//
+//	ctx = log.ContextWithAttrs(ctx, "user", user, "tenant", tenant)
+//	...
+//	log.InfoContext(ctx, "order created")
+//
+// 多次调用会累积属性，同名属性以后放入的为准；记录或 With 绑定的属性中已有同名 key 时不重复添加。
+func ContextWithAttrs(ctx context.Context, args ...any) context.Context {
+	var r slog.Record
+	r.Add(args...)
+	parent := AttrsFromContext(ctx)
+	attrs := make([]slog.Attr, 0, len(parent)+r.NumAttrs())
+	attrs = append(attrs, parent...)
+	r.Attrs(func(a slog.Attr) bool {
+		for i := range attrs {
+			if attrs[i].Key == a.Key {
+				attrs[i] = a
+				return true
+			}
+		}
+		attrs = append(attrs, a)
+		return true
+	})
+	return context.WithValue(ctx, attrsKey{}, attrs)
+}
+
+// AttrsFromContext 返回 ContextWithAttrs 放入 ctx 的属性，返回的切片不能修改
+func AttrsFromContext(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// NewContextHandler 返回在 Handle 时附加 ContextWithAttrs 放入 ctx 的属性的 handler，
+// 用于不经过 Logger 直接使用 slog 的场景：
+//
+//	slog.SetDefault(slog.New(log.NewContextHandler(slog.NewJSONHandler(os.Stdout, nil))))
+//
+// Logger 已经包含这一层，不需要再包装。
+func NewContextHandler(h slog.Handler) slog.Handler {
+	return &contextHandler{Handler: h}
+}
+
+// contextHandler 附加 ctx 中的属性，记录或 With 绑定的属性中已有同名 key 时不重复添加
+type contextHandler struct {
+	slog.Handler
+	state attrState
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	extra := AttrsFromContext(ctx)
+	if len(extra) == 0 {
+		return h.Handler.Handle(ctx, r)
+	}
+	attrs := h.state.collect(r)
+	var add []slog.Attr
+	for _, a := range extra {
+		if !hasAttr(attrs, a.Key) {
+			add = append(add, a)
+		}
+	}
+	if len(add) > 0 {
+		r = r.Clone()
+		r.AddAttrs(add...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs), state: h.state.withAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name), state: h.state.withGroup(name)}
+}
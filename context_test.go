@@ -0,0 +1,53 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLoggerContext(t *testing.T) {
+	if FromContext(context.Background()) != GetDefaultLogger() {
+		t.Error("Expected the default logger when ctx carries none")
+	}
+
+	var buf strings.Builder
+	logger := NewLogger(Config{Writer: &buf}).With("request_id", "r-1")
+	ctx := IntoContext(context.Background(), logger)
+	FromContext(ctx).Info("handled")
+	if !strings.Contains(buf.String(), "request_id=r-1") {
+		t.Errorf("Expected the request-scoped logger, got %q", buf.String())
+	}
+}
+
+func TestContextAttrs(t *testing.T) {
+	var buf strings.Builder
+	logger := NewLogger(Config{Writer: &buf})
+	ctx := ContextWithAttrs(context.Background(), "user", "alice", "tenant", "acme")
+	ctx = ContextWithAttrs(ctx, "tenant", "globex")
+
+	logger.InfoContext(ctx, "nested")
+	logger.With("user", "bob").InfoContext(ctx, "bound")
+	logger.Info("no ctx")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %q", buf.String())
+	}
+	if !strings.Contains(lines[0], "user=alice") || !strings.Contains(lines[0], "tenant=globex") || strings.Contains(lines[0], "acme") {
+		t.Errorf("Expected the context attrs, got %s", lines[0])
+	}
+	if strings.Count(lines[1], "user=") != 1 || !strings.Contains(lines[1], "user=bob") {
+		t.Errorf("Expected the bound attr to be kept, got %s", lines[1])
+	}
+	if strings.Contains(lines[2], "tenant") {
+		t.Errorf("Expected no context attrs, got %s", lines[2])
+	}
+
+	var plain strings.Builder
+	slog.New(NewContextHandler(slog.NewTextHandler(&plain, nil))).InfoContext(ctx, "plain slog")
+	if !strings.Contains(plain.String(), "user=alice") {
+		t.Errorf("Expected NewContextHandler to add the attrs, got %s", plain.String())
+	}
+}
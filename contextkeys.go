@@ -0,0 +1,57 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+)
+
+// ContextKeyMapping 指定从 context 中取出并输出到每条记录的值：
+//
+//	ContextKeys: []log.ContextKeyMapping{
+//		{Key: auth.UserIDKey{}, Attr: "user_id"},
+//		{Key: tenantKey, Attr: "tenant"},
+//	}
+//
+// 使用 InfoContext 等带 ctx 的方法输出时，ctx.Value(Key) 不为 nil（也不是空字符串）就以 Attr 输出，
+// 记录或 With 绑定的属性中已有 Attr 时不重复添加。调用处不需要任何修改。
+type ContextKeyMapping struct {
+	Key  any    // ctx.Value 使用的 key，通常是业务代码中未导出的 key 类型
+	Attr string // 日志中的 key
+}
+
+// contextKeysHandler 按 ContextKeys 从 ctx 中取值附加到记录
+type contextKeysHandler struct {
+	slog.Handler
+	keys  []ContextKeyMapping
+	state attrState
+}
+
+func (h *contextKeysHandler) Handle(ctx context.Context, r slog.Record) error {
+	var add []slog.Attr
+	var attrs []slog.Attr
+	for _, m := range h.keys {
+		v := ctx.Value(m.Key)
+		if v == nil || v == "" {
+			continue
+		}
+		if attrs == nil {
+			attrs = h.state.collect(r)
+		}
+		if !hasAttr(attrs, m.Attr) && !hasAttr(add, m.Attr) {
+			add = append(add, slog.Any(m.Attr, v))
+		}
+	}
+	if len(add) > 0 {
+		r = r.Clone()
+		r.AddAttrs(add...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextKeysHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextKeysHandler{Handler: h.Handler.WithAttrs(attrs), keys: h.keys, state: h.state.withAttrs(attrs)}
+}
+
+func (h *contextKeysHandler) WithGroup(name string) slog.Handler {
+	return &contextKeysHandler{Handler: h.Handler.WithGroup(name), keys: h.keys, state: h.state.withGroup(name)}
+}
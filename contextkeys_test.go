@@ -0,0 +1,42 @@
+package log
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type tenantKey struct{}
+
+func TestContextKeys(t *testing.T) {
+	var buf strings.Builder
+	logger := NewLogger(Config{
+		Writer: &buf,
+		Format: "json",
+		ContextKeys: []ContextKeyMapping{
+			{Key: contextKey{}, Attr: "user_id"},
+			{Key: tenantKey{}, Attr: "tenant"},
+		},
+	})
+	ctx := context.WithValue(context.Background(), contextKey{}, 42)
+	ctx = context.WithValue(ctx, tenantKey{}, "acme")
+
+	logger.InfoContext(ctx, "with values")
+	logger.Info("without ctx")
+	logger.With("tenant", "bound").InfoContext(ctx, "bound tenant")
+	logger.InfoContext(context.WithValue(context.Background(), tenantKey{}, ""), "empty value")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 lines, got %q", buf.String())
+	}
+	if !strings.Contains(lines[0], `"user_id":42`) || !strings.Contains(lines[0], `"tenant":"acme"`) {
+		t.Errorf("Expected context values, got %s", lines[0])
+	}
+	if strings.Contains(lines[1], "user_id") || strings.Contains(lines[3], "tenant") {
+		t.Errorf("Expected no context values, got %s / %s", lines[1], lines[3])
+	}
+	if strings.Count(lines[2], `"tenant"`) != 1 || !strings.Contains(lines[2], `"tenant":"bound"`) {
+		t.Errorf("Expected the bound tenant to be kept, got %s", lines[2])
+	}
+}
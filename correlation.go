@@ -0,0 +1,195 @@
+package log
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// 跨服务传递关联 ID 使用的 HTTP 头，gRPC metadata 中使用对应的小写形式
+const (
+	RequestIDHeader = "X-Request-Id"
+	TraceIDHeader   = "X-Trace-Id"
+)
+
+// MaxRequestIDLen 是接受的入站请求 ID 的最大长度（字节）
+const MaxRequestIDLen = 128
+
+// 关联 ID 在日志中的 key，trace_id 同时会被 OTLP Sink 识别
+const (
+	RequestIDKey = "request_id"
+	TraceIDKey   = "trace_id"
+)
+
+type correlationKey struct{}
+
+// correlation 是保存在 context 中的关联 ID
+type correlation struct {
+	requestID string
+	traceID   string
+}
+
+func correlationFrom(ctx context.Context) correlation {
+	c, _ := ctx.Value(correlationKey{}).(correlation)
+	return c
+}
+
+// ContextWithRequestID 返回携带请求 ID 的 context
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	c := correlationFrom(ctx)
+	c.requestID = id
+	return context.WithValue(ctx, correlationKey{}, c)
+}
+
+// ContextWithTraceID 返回携带链路 ID 的 context
+func ContextWithTraceID(ctx context.Context, id string) context.Context {
+	c := correlationFrom(ctx)
+	c.traceID = id
+	return context.WithValue(ctx, correlationKey{}, c)
+}
+
+// EnsureRequestID 返回一定携带请求 ID 的 context 和该 ID，ctx 中没有请求 ID 时使用 NewID 生成一个。
+// 用于定时任务、消息消费者等没有入站请求的场景，作为关联链路的起点：
+//
+//	ctx, id := log.EnsureRequestID(ctx)
+func EnsureRequestID(ctx context.Context) (context.Context, string) {
+	if id := RequestIDFromContext(ctx); id != "" {
+		return ctx, id
+	}
+	id := NewID()
+	return ContextWithRequestID(ctx, id), id
+}
+
+// RequestIDFromContext 返回 context 中的请求 ID，没有时返回空字符串
+func RequestIDFromContext(ctx context.Context) string {
+	return correlationFrom(ctx).requestID
+}
+
+// TraceIDFromContext 返回 context 中的链路 ID，没有时返回空字符串
+func TraceIDFromContext(ctx context.Context) string {
+	return correlationFrom(ctx).traceID
+}
+
+// CorrelationArgs 返回 context 中关联 ID 对应的日志参数，可以直接传给日志方法或 With：
+//
+//	log.Info("order created", log.CorrelationArgs(ctx)...)
+func CorrelationArgs(ctx context.Context) []any {
+	c := correlationFrom(ctx)
+	var args []any
+	if c.requestID != "" {
+		args = append(args, RequestIDKey, c.requestID)
+	}
+	if c.traceID != "" {
+		args = append(args, TraceIDKey, c.traceID)
+	}
+	return args
+}
+
+// InjectHTTPHeaders 将 context 中的关联 ID 写入出站请求的 HTTP 头
+func InjectHTTPHeaders(ctx context.Context, h http.Header) {
+	c := correlationFrom(ctx)
+	if c.requestID != "" {
+		h.Set(RequestIDHeader, c.requestID)
+	}
+	if c.traceID != "" {
+		h.Set(TraceIDHeader, c.traceID)
+	}
+}
+
+// CorrelationTransport 返回在每个出站请求的 HTTP 头中写入 req.Context() 中关联 ID 的 RoundTripper，base 为 nil 时使用 http.DefaultTransport：
+//
+//	client := &http.Client{Transport: log.CorrelationTransport(nil)}
+//	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+//	client.Do(req)
+//
+// 请求中已经设置的头不会被覆盖。
+func CorrelationTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return correlationTransport{base}
+}
+
+type correlationTransport struct {
+	base http.RoundTripper
+}
+
+func (t correlationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c := correlationFrom(req.Context())
+	if (c.requestID == "" || req.Header.Get(RequestIDHeader) != "") && (c.traceID == "" || req.Header.Get(TraceIDHeader) != "") {
+		return t.base.RoundTrip(req)
+	}
+	// RoundTripper 不能修改传入的请求
+	req = req.Clone(req.Context())
+	if c.requestID != "" && req.Header.Get(RequestIDHeader) == "" {
+		req.Header.Set(RequestIDHeader, c.requestID)
+	}
+	if c.traceID != "" && req.Header.Get(TraceIDHeader) == "" {
+		req.Header.Set(TraceIDHeader, c.traceID)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// ExtractHTTPHeaders 从入站请求的 HTTP 头读取关联 ID 并放入 context。
+// 上游没有传递请求 ID 时使用 NewID 生成一个，本服务即成为关联链路的起点；
+// 请求 ID 超过 MaxRequestIDLen 或含有字母、数字和 . _ : - 以外的字符时同样重新生成，
+// 避免客户端向日志和响应头注入任意内容。
+func ExtractHTTPHeaders(ctx context.Context, h http.Header) context.Context {
+	return withCorrelation(ctx, h.Get(RequestIDHeader), h.Get(TraceIDHeader))
+}
+
+// InjectMetadata 将 context 中的关联 ID 写入出站 gRPC 调用的 metadata。
+// md 可以直接传入 metadata.MD，例如：
+//
+//	md := metadata.MD{}
+//	log.InjectMetadata(ctx, md)
+//	ctx = metadata.NewOutgoingContext(ctx, md)
+func InjectMetadata(ctx context.Context, md map[string][]string) {
+	c := correlationFrom(ctx)
+	if c.requestID != "" {
+		md[strings.ToLower(RequestIDHeader)] = []string{c.requestID}
+	}
+	if c.traceID != "" {
+		md[strings.ToLower(TraceIDHeader)] = []string{c.traceID}
+	}
+}
+
+// ExtractMetadata 从入站 gRPC 调用的 metadata 读取关联 ID 并放入 context，规则与 ExtractHTTPHeaders 相同
+func ExtractMetadata(ctx context.Context, md map[string][]string) context.Context {
+	first := func(key string) string {
+		if v := md[strings.ToLower(key)]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	return withCorrelation(ctx, first(RequestIDHeader), first(TraceIDHeader))
+}
+
+func withCorrelation(ctx context.Context, requestID, traceID string) context.Context {
+	c := correlationFrom(ctx)
+	c.requestID = requestID
+	if !validRequestID(c.requestID) {
+		c.requestID = NewID()
+	}
+	if traceID != "" {
+		c.traceID = traceID
+	}
+	return context.WithValue(ctx, correlationKey{}, c)
+}
+
+// validRequestID 判断入站请求 ID 是否可以直接使用：非空、不超过 MaxRequestIDLen，只含字母、数字和 . _ : -
+func validRequestID(id string) bool {
+	if id == "" || len(id) > MaxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '.' || c == '_' || c == ':' || c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
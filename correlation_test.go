@@ -0,0 +1,77 @@
+package log
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestCorrelationRoundTrip(t *testing.T) {
+	ctx := ContextWithTraceID(ContextWithRequestID(context.Background(), "req-1"), "trace-1")
+
+	// 上游服务：注入 HTTP 头，下游服务：读取 HTTP 头
+	h := http.Header{}
+	InjectHTTPHeaders(ctx, h)
+	downstream := ExtractHTTPHeaders(context.Background(), h)
+	if RequestIDFromContext(downstream) != "req-1" || TraceIDFromContext(downstream) != "trace-1" {
+		t.Errorf("Expected IDs to survive HTTP hop, got %v", CorrelationArgs(downstream))
+	}
+
+	md := map[string][]string{}
+	InjectMetadata(downstream, md)
+	if md["x-request-id"][0] != "req-1" {
+		t.Errorf("Expected lowercase metadata keys, got %v", md)
+	}
+	next := ExtractMetadata(context.Background(), md)
+	args := CorrelationArgs(next)
+	if len(args) != 4 || args[1] != "req-1" || args[3] != "trace-1" {
+		t.Errorf("Expected IDs to survive gRPC hop, got %v", args)
+	}
+}
+
+func TestCorrelationGeneratesRequestID(t *testing.T) {
+	SetIDGenerator(IDGeneratorFunc(func() string { return "generated" }))
+	defer SetIDGenerator(nil)
+
+	ctx := ExtractHTTPHeaders(context.Background(), http.Header{})
+	if id := RequestIDFromContext(ctx); id != "generated" {
+		t.Errorf("Expected generated request ID, got %q", id)
+	}
+	if TraceIDFromContext(ctx) != "" {
+		t.Errorf("Expected no trace ID, got %q", TraceIDFromContext(ctx))
+	}
+
+	ctx, id := EnsureRequestID(context.Background())
+	if id != "generated" || RequestIDFromContext(ctx) != id {
+		t.Errorf("Expected EnsureRequestID to generate an ID, got %q", id)
+	}
+	if _, id := EnsureRequestID(ContextWithRequestID(ctx, "req-1")); id != "req-1" {
+		t.Errorf("Expected EnsureRequestID to keep the existing ID, got %q", id)
+	}
+}
+
+func TestCorrelationTransport(t *testing.T) {
+	var got http.Header
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		got = req.Header
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	})
+	client := &http.Client{Transport: CorrelationTransport(base)}
+
+	ctx := ContextWithTraceID(ContextWithRequestID(context.Background(), "req-1"), "trace-1")
+	req, _ := http.NewRequestWithContext(ctx, "GET", "http://example.com/", nil)
+	req.Header.Set(TraceIDHeader, "explicit")
+	if _, err := client.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get(RequestIDHeader) != "req-1" || got.Get(TraceIDHeader) != "explicit" {
+		t.Errorf("Unexpected outgoing headers %v", got)
+	}
+	if req.Header.Get(RequestIDHeader) != "" {
+		t.Error("Expected the caller's request to be left unchanged")
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
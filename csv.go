@@ -0,0 +1,66 @@
+package log
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"log/slog"
+)
+
+// DefaultCSVColumns 是 csv 格式的默认列
+var DefaultCSVColumns = []string{"time", "level", "msg", "caller"}
+
+// newCSVHandler 创建 CSV 格式的 handler，便于用表格软件或简单的 ETL 任务处理日志。
+// 列固定为 columns：time、level、msg、caller 表示内置字段，其他列按属性 key 取值（分组属性使用以点分隔的 key），
+// 记录中没有的列输出为空，不在列中的属性被丢弃。header 为 true 时创建 handler 时先写入一行表头。
+func newCSVHandler(w io.Writer, opts *slog.HandlerOptions, columns []string, header bool, timeLayout string) *formatHandler {
+	if len(columns) == 0 {
+		columns = DefaultCSVColumns
+	}
+	if header {
+		cw := csv.NewWriter(w)
+		cw.Write(columns)
+		cw.Flush()
+	}
+	wanted := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		wanted[c] = true
+	}
+	return newFormatHandler(w, opts, func(buf *bytes.Buffer, e *entry) {
+		values := make(map[string]string, len(columns))
+		var walk func(prefix string, a slog.Attr)
+		walk = func(prefix string, a slog.Attr) {
+			key := prefix + a.Key
+			if a.Value.Kind() == slog.KindGroup {
+				for _, ga := range a.Value.Group() {
+					walk(key+".", ga)
+				}
+				return
+			}
+			if wanted[key] {
+				values[key] = valueText(a.Value)
+			}
+		}
+		for _, a := range e.attrs {
+			walk("", a)
+		}
+		if e.time.Key != "" {
+			values["time"] = e.timeText(timeLayout)
+		}
+		if e.level.Key != "" {
+			values["level"] = e.levelText()
+		}
+		if e.message.Key != "" {
+			values["msg"] = e.message.Value.String()
+		}
+		values["caller"] = e.caller
+
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			row[i] = values[c]
+		}
+		cw := csv.NewWriter(buf)
+		cw.Write(row)
+		cw.Flush()
+	})
+}
@@ -0,0 +1,40 @@
+package log
+
+import (
+	"bytes"
+	"encoding/csv"
+	"log/slog"
+	"testing"
+)
+
+func TestCSVFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{
+		Level:         slog.LevelDebug,
+		Format:        "csv",
+		Writer:        &buf,
+		TimePrecision: TimeSecond,
+		CSVColumns:    []string{"level", "msg", "user_id", "http.status", "missing"},
+		CSVHeader:     true,
+	})
+	logger.Info(`order "A", created`, "user_id", 7, slog.Group("http", "status", 201), "ignored", true)
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV %q: %v", buf.String(), err)
+	}
+	want := [][]string{
+		{"level", "msg", "user_id", "http.status", "missing"},
+		{"INFO", `order "A", created`, "7", "201", ""},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("Expected %d rows, got %q", len(want), rows)
+	}
+	for i := range want {
+		for j := range want[i] {
+			if rows[i][j] != want[i][j] {
+				t.Errorf("row %d col %d = %q, want %q", i, j, rows[i][j], want[i][j])
+			}
+		}
+	}
+}
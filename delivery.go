@@ -0,0 +1,164 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultDeliveryTimeout 是 ctx 没有截止时间时，ErrorSync 和 MustDeliver 等待写入的最长时间
+const DefaultDeliveryTimeout = 5 * time.Second
+
+// ErrNotDelivered 表示记录没有被任何输出成功写入，例如级别被过滤或所有输出都失败
+var ErrNotDelivered = errors.New("slogx: record not delivered to any output")
+
+// mustDeliver 是 MustDeliver 属性的值，输出前会被移除
+type mustDeliver struct{}
+
+// MustDeliver 返回一个标记属性，带有该属性的记录与 ErrorSync 一样会等待写入完成，
+// 用于审计、安全等不能丢失的事件。属性本身不会出现在输出中，写入失败或超时时错误输出到 stderr。
+//
+//	log.Warn("permission changed", "user", u, log.MustDeliver())
+func MustDeliver() slog.Attr {
+	return slog.Any("", mustDeliver{})
+}
+
+// stripMustDeliver 移除 args 中的 MustDeliver 标记，返回是否找到
+func stripMustDeliver(args []any) ([]any, bool) {
+	for i := 0; i < len(args); i++ {
+		switch a := args[i].(type) {
+		case string:
+			i++ // 跳过值
+		case slog.Attr:
+			if _, ok := a.Value.Any().(mustDeliver); ok && a.Value.Kind() == slog.KindAny {
+				stripped := make([]any, 0, len(args)-1)
+				stripped = append(stripped, args[:i]...)
+				return append(stripped, args[i+1:]...), true
+			}
+		}
+	}
+	return args, false
+}
+
+type receiptKey struct{}
+
+// receipt 收集一条需要确认的记录在各输出上的写入结果
+type receipt struct {
+	mu        sync.Mutex
+	delivered int
+	errs      []error
+}
+
+func receiptFrom(ctx context.Context) *receipt {
+	rc, _ := ctx.Value(receiptKey{}).(*receipt)
+	return rc
+}
+
+func (rc *receipt) record(err error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if err != nil {
+		rc.errs = append(rc.errs, err)
+		return
+	}
+	rc.delivered++
+}
+
+func (rc *receipt) result() error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.delivered > 0 {
+		return nil
+	}
+	return errors.Join(append([]error{ErrNotDelivered}, rc.errs...)...)
+}
+
+// deliver 输出记录并等待至少一个输出确认持久写入。
+// 记录在后台 goroutine 中写入，超时返回时写入可能仍在进行。
+func (l *Logger) deliver(ctx context.Context, level slog.Level, msg string, pc uintptr, args []any) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultDeliveryTimeout)
+		defer cancel()
+	}
+	// 在 goroutine 之外获取调用栈
+	args = l.withStacktrace(level, args)
+	rc := &receipt{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.logAt(context.WithValue(ctx, receiptKey{}, rc), level, msg, pc, args)
+	}()
+	select {
+	case <-done:
+		return rc.result()
+	case <-ctx.Done():
+		return fmt.Errorf("slogx: waiting for delivery: %w", ctx.Err())
+	}
+}
+
+// deliveryHandler 包装一个输出，对需要确认的记录在写入后执行 sync，并把结果记录到 receipt
+type deliveryHandler struct {
+	slog.Handler
+	sync func() error // 持久化已写入的数据，为 nil 表示写入即完成
+}
+
+func (h *deliveryHandler) Handle(ctx context.Context, r slog.Record) error {
+	err := h.Handler.Handle(ctx, r)
+	rc := receiptFrom(ctx)
+	if rc == nil {
+		return err
+	}
+	if err == nil && h.sync != nil {
+		err = h.sync()
+	}
+	rc.record(err)
+	return err
+}
+
+func (h *deliveryHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &deliveryHandler{Handler: h.Handler.WithAttrs(attrs), sync: h.sync}
+}
+
+func (h *deliveryHandler) WithGroup(name string) slog.Handler {
+	return &deliveryHandler{Handler: h.Handler.WithGroup(name), sync: h.sync}
+}
+
+// syncWriters 返回刷新并落盘 writers 的函数。标准输出和标准错误不需要也往往不支持 Sync，会被跳过。
+func syncWriters(writers []io.Writer) func() error {
+	var syncers []interface{ Sync() error }
+	for _, w := range writers {
+		if s, ok := w.(interface{ Sync() error }); ok && w != os.Stdout && w != os.Stderr {
+			syncers = append(syncers, s)
+		}
+	}
+	if len(syncers) == 0 {
+		return nil
+	}
+	return func() error {
+		var errs []error
+		for _, s := range syncers {
+			errs = append(errs, s.Sync())
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// syncSink 返回刷新 sink 的函数，支持实现了 Flush 或 Sync 的 Sink，例如 OTLPSink
+func syncSink(sink Sink) func() error {
+	switch s := sink.(type) {
+	case interface{ Flush() error }:
+		return s.Flush
+	case interface{ Sync() error }:
+		return s.Sync
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// syncCountingSink 是实现了 Sync 的内存 Sink，记录 Sync 调用次数
+type syncCountingSink struct {
+	memorySink
+	syncs int
+}
+
+func (s *syncCountingSink) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.syncs++
+	return nil
+}
+
+func TestErrorSync(t *testing.T) {
+	sink := &syncCountingSink{}
+	registerTestSink(t, "test-delivery", func(map[string]any) (Sink, error) { return sink, nil })
+	down := &flakyWriter{down: true}
+	logger := NewLogger(Config{Writer: down, Sinks: []SinkConfig{{Type: "test-delivery"}}})
+
+	if err := logger.ErrorSync(context.Background(), "audit", "user", "alice"); err != nil {
+		t.Fatalf("ErrorSync failed with one healthy output: %v", err)
+	}
+	if len(sink.records) != 1 || sink.syncs != 1 {
+		t.Errorf("records = %d, syncs = %d, want 1 and 1", len(sink.records), sink.syncs)
+	}
+	logger.Info("not critical")
+	if sink.syncs != 1 {
+		t.Errorf("Sync called for an ordinary record")
+	}
+
+	only := NewLogger(Config{Writer: down})
+	if err := only.ErrorSync(context.Background(), "audit"); !errors.Is(err, ErrNotDelivered) {
+		t.Errorf("ErrorSync error = %v, want ErrNotDelivered", err)
+	}
+}
+
+func TestErrorSyncTimeout(t *testing.T) {
+	logger := NewLogger(Config{Writer: slowWriter{200 * time.Millisecond}})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := logger.ErrorSync(ctx, "audit"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ErrorSync error = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestMustDeliverStripped(t *testing.T) {
+	var buf syncBuffer
+	logger := NewLogger(Config{Level: slog.LevelInfo, Format: "json", Writer: &buf})
+	logger.Warn("permission changed", "user", "bob", MustDeliver())
+	out := buf.String()
+	if !strings.Contains(out, `"user":"bob"`) || strings.Contains(out, `"":`) {
+		t.Errorf("output = %s", out)
+	}
+}
@@ -0,0 +1,68 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DeprecatedMessage 是弃用警告记录的固定消息，便于在日志平台中统一检索
+const DeprecatedMessage = "deprecated feature used"
+
+var (
+	deprecationSeen     sync.Map // onceKey -> 上次输出的时间
+	deprecationInterval atomic.Int64
+)
+
+// SetDeprecationInterval 设置同一 onceKey 的弃用警告再次输出的最小间隔，
+// 默认 0 表示每个进程只输出一次，设置为 24 * time.Hour 即每天最多输出一次
+func SetDeprecationInterval(d time.Duration) {
+	deprecationInterval.Store(int64(d))
+}
+
+// RemovedIn 返回记录计划移除版本的属性，作为 Deprecated 的附加参数使用
+func RemovedIn(version string) slog.Attr {
+	return slog.String("removed_in", version)
+}
+
+// Deprecated 输出一条标准化的 Warn 级别弃用警告，同一 onceKey 在一个间隔内只输出一次，onceKey 为空时使用 feature：
+//
+//	log.Deprecated("flag --old-mode", "use --new-mode", "old-mode", log.RemovedIn("v3.0"))
+//
+// 记录包含 feature、replacement 和 deprecation_key 属性，产品团队可以据此统计弃用功能的实际使用情况。
+func (l *Logger) Deprecated(feature, replacement, onceKey string, args ...any) {
+	if onceKey == "" {
+		onceKey = feature
+	}
+	if !shouldReportDeprecation(onceKey) {
+		return
+	}
+	args = append([]any{"feature", feature, "replacement", replacement, "deprecation_key", onceKey}, args...)
+	l.log(context.Background(), slog.LevelWarn, DeprecatedMessage, args...)
+}
+
+// Deprecated 使用默认 Logger 输出弃用警告
+func Deprecated(feature, replacement, onceKey string, args ...any) {
+	defaultLogger.Deprecated(feature, replacement, onceKey, args...)
+}
+
+// shouldReportDeprecation 判断 onceKey 是否需要输出，并记录本次输出时间
+func shouldReportDeprecation(onceKey string) bool {
+	t := now()
+	interval := time.Duration(deprecationInterval.Load())
+	for {
+		prev, loaded := deprecationSeen.LoadOrStore(onceKey, t)
+		if !loaded {
+			return true
+		}
+		if interval <= 0 || t.Sub(prev.(time.Time)) < interval {
+			return false
+		}
+		// 多个 goroutine 同时到期时只有一个能替换成功
+		if deprecationSeen.CompareAndSwap(onceKey, prev, t) {
+			return true
+		}
+	}
+}
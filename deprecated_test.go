@@ -0,0 +1,50 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeprecated(t *testing.T) {
+	t.Cleanup(func() { deprecationSeen.Delete("test-old-mode") })
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: slog.LevelDebug, Writer: &buf})
+
+	for i := 0; i < 3; i++ {
+		logger.Deprecated("flag --old-mode", "use --new-mode", "test-old-mode", RemovedIn("v3.0"))
+	}
+
+	output := buf.String()
+	if n := strings.Count(output, DeprecatedMessage); n != 1 {
+		t.Fatalf("Expected exactly 1 deprecation record, got %d: %s", n, output)
+	}
+	for _, want := range []string{"level=WARN", `feature="flag --old-mode"`, `replacement="use --new-mode"`, "deprecation_key=test-old-mode", "removed_in=v3.0"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output, got: %s", want, output)
+		}
+	}
+}
+
+func TestDeprecatedInterval(t *testing.T) {
+	t.Cleanup(func() { deprecationSeen.Delete("test-api-v1") })
+	current := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	SetClock(ClockFunc(func() time.Time { return current }))
+	SetDeprecationInterval(24 * time.Hour)
+	defer SetClock(nil)
+	defer SetDeprecationInterval(0)
+
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: slog.LevelDebug, Writer: &buf})
+	logger.Deprecated("api v1", "use api v2", "test-api-v1")
+	current = current.Add(time.Hour)
+	logger.Deprecated("api v1", "use api v2", "test-api-v1")
+	current = current.Add(24 * time.Hour)
+	logger.Deprecated("api v1", "use api v2", "test-api-v1")
+
+	if n := strings.Count(buf.String(), DeprecatedMessage); n != 2 {
+		t.Errorf("Expected 2 deprecation records over two days, got %d: %s", n, buf.String())
+	}
+}
@@ -0,0 +1,173 @@
+package log
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http/httptrace"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// 连接事件记录的消息
+const (
+	DNSMessage     = "dns lookup"
+	DialMessage    = "dial"
+	TLSMessage     = "tls handshake"
+	ConnGotMessage = "http conn"
+)
+
+// Dialer 包装 net.Dialer，以 Debug 级别记录 DNS 解析结果、每个地址的连接耗时和 TLS 握手信息，
+// 排查不稳定的出站连接时不需要抓包。可以直接用于 http.Transport：
+//
+//	d := &log.Dialer{SampleRate: 10}
+//	client := &http.Client{Transport: &http.Transport{DialContext: d.DialContext, DialTLSContext: d.DialTLSContext}}
+//	resp, err := client.Do(req.WithContext(d.WithConnTrace(ctx))) // 同时记录 HTTP 连接是否复用
+//
+// 失败的连接以及 Config.NeverSample 中的事件不受采样限制，总是记录。
+type Dialer struct {
+	net.Dialer
+	Logger     *Logger     // 为 nil 时使用默认 logger
+	SampleRate int         // 每 SampleRate 次连接记录一次，<= 1 时全部记录
+	TLSConfig  *tls.Config // DialTLSContext 使用的 TLS 配置，ServerName 为空时使用连接的主机名
+
+	count atomic.Uint64
+}
+
+func (d *Dialer) logger() *Logger {
+	if d.Logger != nil {
+		return d.Logger
+	}
+	return defaultLogger
+}
+
+// sampled 判断本次连接是否记录
+func (d *Dialer) sampled() bool {
+	return d.SampleRate <= 1 || (d.count.Add(1)-1)%uint64(d.SampleRate) == 0
+}
+
+// event 输出一条连接事件，采样未命中且没有错误时不输出
+func (d *Dialer) event(ctx context.Context, sampled bool, msg string, err error, attrs ...any) {
+	l := d.logger()
+	if (!sampled && err == nil && !l.storm.exempt.keep(ctx, msg)) || !l.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	l.logAt(ctx, slog.LevelDebug, msg, 0, []any{slog.Group("net", attrs...)})
+}
+
+// DialContext 解析地址并依次连接解析出的 IP，记录解析结果和每次连接的耗时
+func (d *Dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return d.dial(ctx, network, address, d.sampled())
+}
+
+func (d *Dialer) dial(ctx context.Context, network, address string, sampled bool) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || net.ParseIP(host) != nil || !(strings.HasPrefix(network, "tcp") || strings.HasPrefix(network, "udp")) {
+		return d.connect(ctx, network, address, sampled)
+	}
+
+	resolver := d.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	ipNetwork := "ip"
+	if strings.HasSuffix(network, "4") || strings.HasSuffix(network, "6") {
+		ipNetwork += network[len(network)-1:]
+	}
+	start := time.Now()
+	ips, err := resolver.LookupIP(ctx, ipNetwork, host)
+	addrs := make([]string, len(ips))
+	for i, ip := range ips {
+		addrs[i] = ip.String()
+	}
+	d.event(ctx, sampled, DNSMessage, err, "host", host, "addrs", addrs, "duration", time.Since(start))
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, addr := range addrs {
+		conn, err := d.connect(ctx, network, net.JoinHostPort(addr, port), sampled)
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// connect 连接一个地址并记录耗时
+func (d *Dialer) connect(ctx context.Context, network, address string, sampled bool) (net.Conn, error) {
+	start := time.Now()
+	conn, err := d.Dialer.DialContext(ctx, network, address)
+	attrs := []any{"network", network, "addr", address, "duration", time.Since(start)}
+	if conn != nil {
+		attrs = append(attrs, "local", conn.LocalAddr().String())
+	}
+	d.event(ctx, sampled, DialMessage, err, attrs...)
+	return conn, err
+}
+
+// DialTLSContext 连接地址并完成 TLS 握手，记录协议版本、加密套件、ALPN、会话是否复用和服务端证书
+func (d *Dialer) DialTLSContext(ctx context.Context, network, address string) (net.Conn, error) {
+	sampled := d.sampled()
+	conn, err := d.dial(ctx, network, address, sampled)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{}
+	if d.TLSConfig != nil {
+		cfg = d.TLSConfig.Clone()
+	}
+	if cfg.ServerName == "" {
+		host, _, _ := net.SplitHostPort(address)
+		cfg.ServerName = host
+	}
+	tc := tls.Client(conn, cfg)
+	start := time.Now()
+	err = tc.HandshakeContext(ctx)
+	attrs := []any{"server_name", cfg.ServerName, "duration", time.Since(start)}
+	if err == nil {
+		state := tc.ConnectionState()
+		attrs = append(attrs,
+			"version", tls.VersionName(state.Version),
+			"cipher_suite", tls.CipherSuiteName(state.CipherSuite),
+			"alpn", state.NegotiatedProtocol,
+			"resumed", state.DidResume,
+		)
+		if len(state.PeerCertificates) > 0 {
+			cert := state.PeerCertificates[0]
+			attrs = append(attrs, "peer_subject", cert.Subject.String(), "peer_not_after", cert.NotAfter)
+		}
+	}
+	d.event(ctx, sampled, TLSMessage, err, attrs...)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tc, nil
+}
+
+// WithConnTrace 返回带有 httptrace.ClientTrace 的 context，用它发出的 HTTP 请求会记录拿到的连接是否复用、之前空闲了多久
+func (d *Dialer) WithConnTrace(ctx context.Context) context.Context {
+	sampled := d.sampled()
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			d.event(ctx, sampled, ConnGotMessage, nil,
+				"remote", info.Conn.RemoteAddr().String(),
+				"reused", info.Reused,
+				"was_idle", info.WasIdle,
+				"idle_time", info.IdleTime,
+			)
+		},
+	})
+}
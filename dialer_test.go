@@ -0,0 +1,97 @@
+package log
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDialerTLSAndReuse(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, "ok") }))
+	defer srv.Close()
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+
+	var buf syncBuffer
+	d := &Dialer{Logger: NewLogger(Config{Writer: &buf, Level: slog.LevelDebug}), TLSConfig: &tls.Config{InsecureSkipVerify: true}}
+	client := &http.Client{Transport: &http.Transport{DialContext: d.DialContext, DialTLSContext: d.DialTLSContext}}
+	defer client.CloseIdleConnections()
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequestWithContext(d.WithConnTrace(context.Background()), "GET", "https://localhost:"+port, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		`msg="dns lookup" net.host=localhost net.addrs=`,
+		`msg=dial net.network=tcp net.addr=`,
+		`msg="tls handshake" net.server_name=localhost`,
+		`net.version="TLS 1.`,
+		"net.reused=false",
+		"net.reused=true",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("missing %q in:\n%s", want, output)
+		}
+	}
+}
+
+func TestDialerSampling(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedAddr := closed.Addr().String()
+	closed.Close()
+
+	var buf syncBuffer
+	d := &Dialer{Logger: NewLogger(Config{Writer: &buf, Level: slog.LevelDebug}), SampleRate: 3}
+	for i := 0; i < 3; i++ {
+		if conn, err := d.DialContext(context.Background(), "tcp", ln.Addr().String()); err == nil {
+			conn.Close()
+		}
+	}
+	if _, err := d.DialContext(context.Background(), "tcp", closedAddr); err == nil {
+		t.Fatal("dial to a closed port succeeded")
+	}
+
+	output := buf.String()
+	if n := strings.Count(output, "msg=dial"); n != 2 {
+		t.Errorf("got %d dial records, want 1 sampled + 1 failure:\n%s", n, output)
+	}
+	if !strings.Contains(output, "net.error=") {
+		t.Errorf("failed dial not logged:\n%s", output)
+	}
+}
+
+func TestDialerSamplingExempt(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	var buf syncBuffer
+	logger := NewLogger(Config{Writer: &buf, Level: slog.LevelDebug, NeverSample: []string{DialMessage}})
+	d := &Dialer{Logger: logger, SampleRate: 100}
+	for i := 0; i < 3; i++ {
+		if conn, err := d.DialContext(context.Background(), "tcp", ln.Addr().String()); err == nil {
+			conn.Close()
+		}
+	}
+	if n := strings.Count(buf.String(), "msg=dial"); n != 3 {
+		t.Errorf("got %d dial records, want every exempt dial logged:\n%s", n, buf.String())
+	}
+}
@@ -0,0 +1,43 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// OnDone 使用默认 Logger 在 ctx 结束时输出记录，见 Logger.OnDone
+func OnDone(ctx context.Context, msg string, args ...any) (stop func() bool) {
+	return defaultLogger.onDone(ctx, msg, defaultLogger.pc(), args)
+}
+
+// OnDone 在 ctx 被取消或超时时输出一条 Warn 记录，用于追踪被调用方放弃的请求：
+//
+//	stop := log.OnDone(ctx, "request abandoned", "path", r.URL.Path)
+//	defer stop()
+//
+// 记录包含 reason（canceled 或 deadline exceeded）、与 reason 不同时的 cause（context.WithCancelCause 等设置的原因）
+// 以及从调用 OnDone 到 ctx 结束经过的 elapsed，调用位置为 OnDone 的调用处。
+// 请求正常完成后调用返回的 stop 取消监听，stop 返回 false 表示 ctx 已经结束、记录已经输出或正在输出。
+func (l *Logger) OnDone(ctx context.Context, msg string, args ...any) (stop func() bool) {
+	return l.onDone(ctx, msg, l.pc(), args)
+}
+
+func (l *Logger) onDone(ctx context.Context, msg string, pc uintptr, args []any) func() bool {
+	start := time.Now()
+	return context.AfterFunc(ctx, func() {
+		err := ctx.Err()
+		reason := "canceled"
+		if errors.Is(err, context.DeadlineExceeded) {
+			reason = "deadline exceeded"
+		}
+		attrs := append([]any{slog.String("reason", reason)}, args...)
+		if cause := context.Cause(ctx); cause != nil && cause != err {
+			attrs = append(attrs, slog.Any("cause", cause))
+		}
+		attrs = append(attrs, slog.Duration("elapsed", time.Since(start)))
+		// ctx 已经结束，输出时不再携带取消信号，否则 Sink 可能放弃写入
+		l.logAt(context.WithoutCancel(ctx), slog.LevelWarn, msg, pc, attrs)
+	})
+}
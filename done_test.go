@@ -0,0 +1,51 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOnDone(t *testing.T) {
+	var buf syncBuffer
+	logger := NewLogger(Config{Writer: &buf, Format: "json"})
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	logger.OnDone(ctx, "request abandoned", "path", "/orders")
+	cancel(errors.New("client went away"))
+
+	deadline, cancelDeadline := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancelDeadline()
+	logger.OnDone(deadline, "request timed out")
+
+	finished, finish := context.WithCancel(context.Background())
+	stop := logger.OnDone(finished, "never logged")
+	if !stop() {
+		t.Error("Expected stop to cancel the watch")
+	}
+	finish()
+
+	var lines []string
+	for i := 0; i < 100 && len(lines) < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+		lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	}
+	output := buf.String()
+	if len(lines) != 2 || strings.Contains(output, "never logged") {
+		t.Fatalf("Expected 2 records, got %q", output)
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, `"level":"WARN"`) || !strings.Contains(line, "done_test.go:") || !strings.Contains(line, `"elapsed":`) {
+			t.Errorf("Unexpected record %s", line)
+		}
+		if strings.Contains(line, "request abandoned") &&
+			(!strings.Contains(line, `"reason":"canceled"`) || !strings.Contains(line, `"cause":"client went away"`) || !strings.Contains(line, `"path":"/orders"`)) {
+			t.Errorf("Unexpected cancellation record %s", line)
+		}
+		if strings.Contains(line, "request timed out") && (!strings.Contains(line, `"reason":"deadline exceeded"`) || strings.Contains(line, "cause")) {
+			t.Errorf("Unexpected deadline record %s", line)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// EarlyBootLimit 是 Init 之前最多缓存的记录数，超出后缓存的记录立即按默认配置输出，不再缓存
+const EarlyBootLimit = 1000
+
+// DefaultEarlyBootTimeout 是开启早期启动缓存后等待 Init 的时间，超时后缓存的记录按默认配置输出。
+//
+// 早期启动缓存默认关闭：没有调用 Init 的程序如果在超时前退出，缓存的记录会全部丢失。
+// 环境变量 LOG_EARLY_BOOT=1 时开启，LOG_EARLY_BOOT_TIMEOUT（Go 时长格式）修改等待时间并同样开启，为 0 时不缓存。
+const DefaultEarlyBootTimeout = time.Second
+
+// boot 是包初始化时创建的默认 logger 的早期启动缓存
+var boot *earlyBoot
+
+// earlyBoot 缓存程序配置 logger 之前（包括其他包的 init 函数中）输出的记录，
+// Init 时用最终的配置补写，避免早期的诊断信息丢失或以不同的格式输出
+type earlyBoot struct {
+	mu       sync.Mutex
+	records  []earlyRecord
+	done     bool    // 不再缓存
+	fallback *Logger // 按环境变量创建的默认 logger，没有调用 Init 时缓存的记录由它输出
+	target   *Logger // Init 创建的 logger
+	timer    *time.Timer
+}
+
+type earlyRecord struct {
+	ctx     context.Context
+	handler *earlyHandler
+	r       slog.Record
+}
+
+// earlyHandler 是早期启动阶段的 handler，记录 WithAttrs 和 WithGroup 以便在 Init 后应用到新的 logger 上。
+// 在 init 函数中创建的包级 logger（例如 var log = slogx.With("pkg", "db")）在 Init 后也输出到新的 logger。
+type earlyHandler struct {
+	boot   *earlyBoot
+	next   slog.Handler // 默认 logger 的 handler，已应用 ops
+	parent *earlyHandler
+	attrs  []slog.Attr
+	group  string
+
+	once     sync.Once
+	resolved slog.Handler // 应用到 target 上的 handler
+}
+
+// newEarlyBoot 包装 fallback，返回在 Init 或 timeout 之前缓存记录的 logger
+func newEarlyBoot(fallback *Logger, timeout time.Duration) (*Logger, *earlyBoot) {
+	b := &earlyBoot{fallback: fallback}
+	b.mu.Lock()
+	b.timer = time.AfterFunc(timeout, func() { b.flush() })
+	b.mu.Unlock()
+	early := *fallback
+	early.Logger = slog.New(&earlyHandler{boot: b, next: fallback.Handler()})
+	early.boot = b
+	return &early, b
+}
+
+// startEarlyBoot 按环境变量为包初始化时创建的默认 logger 开启早期启动缓存，见 DefaultEarlyBootTimeout
+func startEarlyBoot() {
+	timeout := time.Duration(0)
+	if os.Getenv("LOG_EARLY_BOOT") == "1" {
+		timeout = DefaultEarlyBootTimeout
+	}
+	if v, err := time.ParseDuration(os.Getenv("LOG_EARLY_BOOT_TIMEOUT")); err == nil {
+		timeout = v
+	}
+	if timeout <= 0 {
+		return
+	}
+	defaultLogger, boot = newEarlyBoot(defaultLogger, timeout)
+}
+
+// Init 用 cfg 创建 logger 并设为默认 logger。Init 之前通过默认 logger 输出的记录按新的配置补写，
+// 调用位置按新的格式重新生成；包初始化时创建的默认 logger 随后被关闭。
+func Init(cfg Config) *Logger {
+	l := NewLogger(cfg)
+	if boot != nil {
+		boot.handoff(l)
+	}
+	SetDefaultLogger(l)
+	return l
+}
+
+// handoff 将缓存的记录交给 target 输出，之后早期创建的 logger 也输出到 target
+func (b *earlyBoot) handoff(target *Logger) {
+	b.mu.Lock()
+	first := b.target == nil
+	b.target = target
+	records := b.records
+	b.records, b.done = nil, true
+	b.timer.Stop()
+	for _, rec := range records {
+		// 只有缓存中的记录需要 target 上的 handler，新的 target 不复用旧的结果
+		target.replay(rec.ctx, rec.handler.resolve(target), rec.r, b.fallback.callerKey)
+	}
+	b.mu.Unlock()
+	if first {
+		b.fallback.Close()
+	}
+}
+
+// flush 将缓存的记录按默认配置输出，之后不再缓存
+func (b *earlyBoot) flush() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.flushLocked()
+}
+
+func (b *earlyBoot) flushLocked() {
+	if b.done {
+		return
+	}
+	b.done = true
+	b.timer.Stop()
+	for _, rec := range b.records {
+		if rec.handler.next.Enabled(rec.ctx, rec.r.Level) {
+			rec.handler.next.Handle(rec.ctx, rec.r)
+		}
+	}
+	b.records = nil
+}
+
+func (h *earlyHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	b := h.boot
+	b.mu.Lock()
+	done, target := b.done, b.target
+	b.mu.Unlock()
+	switch {
+	case !done:
+		return true // 最终的级别还不确定，先全部缓存
+	case target != nil:
+		return h.resolveOnce(target).Enabled(ctx, level)
+	default:
+		return h.next.Enabled(ctx, level)
+	}
+}
+
+func (h *earlyHandler) Handle(ctx context.Context, r slog.Record) error {
+	b := h.boot
+	b.mu.Lock()
+	if !b.done {
+		if len(b.records) < EarlyBootLimit {
+			b.records = append(b.records, earlyRecord{ctx: ctx, handler: h, r: r.Clone()})
+			b.mu.Unlock()
+			return nil
+		}
+		b.flushLocked()
+	}
+	target := b.target
+	b.mu.Unlock()
+	if target != nil {
+		return target.replay(ctx, h.resolveOnce(target), r, b.fallback.callerKey)
+	}
+	if !h.next.Enabled(ctx, r.Level) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *earlyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &earlyHandler{boot: h.boot, next: h.next.WithAttrs(attrs), parent: h, attrs: attrs}
+}
+
+func (h *earlyHandler) WithGroup(name string) slog.Handler {
+	return &earlyHandler{boot: h.boot, next: h.next.WithGroup(name), parent: h, group: name}
+}
+
+// resolve 在 target 的 handler 上依次应用这个 handler 记录的属性和分组
+func (h *earlyHandler) resolve(target *Logger) slog.Handler {
+	if h.parent == nil {
+		return target.Handler()
+	}
+	handler := h.parent.resolve(target)
+	if h.group != "" {
+		return handler.WithGroup(h.group)
+	}
+	return handler.WithAttrs(h.attrs)
+}
+
+// resolveOnce 与 resolve 相同，但缓存结果，用于 Init 之后的每条记录
+func (h *earlyHandler) resolveOnce(target *Logger) slog.Handler {
+	h.once.Do(func() { h.resolved = h.resolve(target) })
+	return h.resolved
+}
+
+// replay 以 l 的配置通过 handler 输出早期缓存的记录：去掉按默认配置生成的调用位置（key 为 callerKey），按 PC 重新生成
+func (l *Logger) replay(ctx context.Context, handler slog.Handler, r slog.Record, callerKey string) error {
+	if !handler.Enabled(ctx, r.Level) {
+		return nil
+	}
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key != callerKey {
+			nr.AddAttrs(a)
+		}
+		return true
+	})
+	if !l.noCaller && r.PC != 0 {
+		nr.AddAttrs(slog.Attr{Key: l.callerKey, Value: l.callerValue(r.PC)})
+	}
+	if l.recordIDKey != "" {
+		nr.AddAttrs(slog.String(l.recordIDKey, NewID()))
+	}
+	return handler.Handle(ctx, nr)
+}
@@ -0,0 +1,74 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEarlyBootReplay(t *testing.T) {
+	var before, after bytes.Buffer
+	early, b := newEarlyBoot(NewLogger(Config{Writer: &before, Format: "text", Level: slog.LevelInfo}), time.Hour)
+	pkg := early.With("pkg", "db")
+	pkg.Debug("connecting")
+	early.Info("booting")
+
+	target := NewLogger(Config{Writer: &after, Format: "json", Level: slog.LevelDebug, CallerKey: "src"})
+	b.handoff(target)
+	pkg.Info("connected")
+
+	if before.Len() != 0 {
+		t.Errorf("fallback logger wrote %q", before.String())
+	}
+	lines := strings.Split(strings.TrimSpace(after.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3: %s", len(lines), after.String())
+	}
+	for i, want := range []string{`"msg":"connecting","pkg":"db"`, `"msg":"booting"`, `"msg":"connected","pkg":"db"`} {
+		if !strings.Contains(lines[i], want) || !strings.Contains(lines[i], `"src":"`) {
+			t.Errorf("line %d = %s, want %s with the new caller key", i, lines[i], want)
+		}
+	}
+}
+
+func TestEarlyBootTimeout(t *testing.T) {
+	var buf syncBuffer
+	early, _ := newEarlyBoot(NewLogger(Config{Writer: &buf, Format: "text", Level: slog.LevelInfo}), 10*time.Millisecond)
+	early.Debug("filtered")
+	early.Info("waiting for init")
+	if buf.String() != "" {
+		t.Fatalf("record written before timeout: %s", buf.String())
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(buf.String(), "waiting for init") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	early.Info("direct")
+	output := buf.String()
+	if !strings.Contains(output, "waiting for init") || !strings.Contains(output, "direct") || strings.Contains(output, "filtered") {
+		t.Errorf("unexpected output after timeout: %s", output)
+	}
+}
+
+func TestEarlyBootOptIn(t *testing.T) {
+	saved, savedBoot := defaultLogger, boot
+	defer func() { defaultLogger, boot = saved, savedBoot }()
+
+	t.Setenv("LOG_EARLY_BOOT", "")
+	t.Setenv("LOG_EARLY_BOOT_TIMEOUT", "")
+	boot = nil
+	startEarlyBoot()
+	if boot != nil || defaultLogger != saved {
+		t.Fatal("Expected records not to be held without LOG_EARLY_BOOT")
+	}
+
+	t.Setenv("LOG_EARLY_BOOT", "1")
+	startEarlyBoot()
+	if boot == nil || defaultLogger.boot != boot {
+		t.Fatal("Expected LOG_EARLY_BOOT=1 to hold records")
+	}
+	boot.flush()
+}
@@ -0,0 +1,71 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ECSVersion 是输出中 ecs.version 字段的值
+const ECSVersion = "8.11.0"
+
+// ecsStackKey 是按 ECS 映射为 error.stack_trace 的属性 key
+const ecsStackKey = "stacktrace"
+
+// newECSHandler 创建 Elastic Common Schema 格式的 handler，日志无需 ingest pipeline 即可直接写入 Elastic：
+//
+//	{"@timestamp":"...","log.level":"info","message":"...","ecs.version":"8.11.0",
+//	 "log.origin.file.name":"main.go","log.origin.file.line":42,"log.origin.function":"main.main",...}
+//
+// 值为 error 的第一个属性映射为 error.message/error.type，stacktrace 属性映射为 error.stack_trace，
+// 其他属性按原样输出，分组输出为嵌套对象。
+func newECSHandler(w io.Writer, opts *slog.HandlerOptions) *formatHandler {
+	return newFormatHandler(w, opts, func(buf *bytes.Buffer, e *entry) {
+		buf.WriteByte('{')
+		buf.WriteString(`"@timestamp":`)
+		ts := e.record.Time
+		if ts.IsZero() {
+			ts = now()
+		}
+		appendJSONString(buf, ts.UTC().Format(time.RFC3339Nano))
+		buf.WriteString(`,"log.level":`)
+		appendJSONString(buf, strings.ToLower(e.levelText()))
+		buf.WriteString(`,"message":`)
+		appendJSONString(buf, e.message.Value.String())
+		buf.WriteString(`,"ecs.version":"` + ECSVersion + `"`)
+
+		if frame, ok := frameForPC(e.record.PC); ok {
+			buf.WriteString(`,"log.origin.file.name":`)
+			appendJSONString(buf, frame.File)
+			buf.WriteString(`,"log.origin.file.line":`)
+			buf.WriteString(strconv.Itoa(frame.Line))
+			buf.WriteString(`,"log.origin.function":`)
+			appendJSONString(buf, frame.Function)
+		}
+
+		attrs := make([]slog.Attr, 0, len(e.attrs))
+		errorSeen := false
+		for _, a := range e.attrs {
+			if err, ok := a.Value.Any().(error); ok && a.Value.Kind() == slog.KindAny && !errorSeen {
+				errorSeen = true
+				buf.WriteString(`,"error.message":`)
+				appendJSONString(buf, err.Error())
+				buf.WriteString(`,"error.type":`)
+				appendJSONString(buf, fmt.Sprintf("%T", err))
+				continue
+			}
+			if a.Key == ecsStackKey && a.Value.Kind() == slog.KindString {
+				buf.WriteString(`,"error.stack_trace":`)
+				appendJSONString(buf, a.Value.String())
+				continue
+			}
+			attrs = append(attrs, a)
+		}
+		appendJSONAttrs(buf, attrs, true)
+		buf.WriteString("}\n")
+	})
+}
@@ -0,0 +1,65 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestECSFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: slog.LevelDebug, Format: "ecs", Writer: &buf})
+	logger.Error("payment failed", "err", errors.New("card declined"),
+		"stacktrace", "main.pay()\n\tmain.go:10", slog.Group("order", "id", 42))
+
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"log.level":         "error",
+		"message":           "payment failed",
+		"ecs.version":       ECSVersion,
+		"error.message":     "card declined",
+		"error.type":        "*errors.errorString",
+		"error.stack_trace": "main.pay()\n\tmain.go:10",
+	}
+	for k, v := range want {
+		if m[k] != v {
+			t.Errorf("%s = %v, want %v", k, m[k], v)
+		}
+	}
+	if _, ok := m["@timestamp"].(string); !ok {
+		t.Errorf("Expected @timestamp, got %v", m)
+	}
+	if name, _ := m["log.origin.file.name"].(string); !strings.HasSuffix(name, ".go") {
+		t.Errorf("Expected log.origin.file.name, got %v", m["log.origin.file.name"])
+	}
+	if order, _ := m["order"].(map[string]any); order["id"] != float64(42) {
+		t.Errorf("Expected nested group, got %v", m["order"])
+	}
+	if _, ok := m["source"]; ok {
+		t.Errorf("Expected caller to be mapped to log.origin, got %v", m)
+	}
+}
+
+func TestAppendJSONString(t *testing.T) {
+	cases := map[string]string{
+		"plain":       `"plain"`,
+		`q"b\`:        `"q\"b\\"`,
+		"a\nb\t\x01":  `"a\nb\t\u0001"`,
+		"<html>&":     `"<html>&"`,
+		"中文":          `"中文"`,
+		"bad\xffutf8": `"bad\ufffdutf8"`,
+	}
+	for in, want := range cases {
+		var buf bytes.Buffer
+		appendJSONString(&buf, in)
+		if buf.String() != want {
+			t.Errorf("appendJSONString(%q) = %s, want %s", in, buf.String(), want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package log
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+)
+
+// Fingerprint 返回消息的指纹（FNV-1a 64 位哈希的十六进制），可以代替完整消息写入 Config.NeverSample，
+// 避免在配置中重复很长的消息
+func Fingerprint(msg string) string {
+	h := fnv.New64a()
+	h.Write([]byte(msg))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// exemptions 是永不采样、限流的消息集合，风暴模式等所有丢弃记录的机制都需要先检查它
+type exemptions map[string]struct{}
+
+func newExemptions(entries []string) exemptions {
+	if len(entries) == 0 {
+		return nil
+	}
+	e := make(exemptions, len(entries))
+	for _, entry := range entries {
+		e[entry] = struct{}{}
+	}
+	return e
+}
+
+// match 判断消息本身或其指纹是否在豁免列表中
+func (e exemptions) match(msg string) bool {
+	if len(e) == 0 {
+		return false
+	}
+	if _, ok := e[msg]; ok {
+		return true
+	}
+	_, ok := e[Fingerprint(msg)]
+	return ok
+}
+
+// keep 判断记录是否不能被采样或丢弃：消息在豁免列表中，或者是 ErrorSync、MustDeliver 等需要确认写入的记录
+func (e exemptions) keep(ctx context.Context, msg string) bool {
+	return e.match(msg) || receiptFrom(ctx) != nil
+}
@@ -0,0 +1,37 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNeverSampleInStorm(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{
+		Level:       slog.LevelDebug,
+		Writer:      &buf,
+		NeverSample: []string{"login failed", Fingerprint("payment declined")},
+	})
+
+	logger.StartStorm(time.Hour, StormOptions{SampleRate: 1000})
+	for i := 0; i < 10; i++ {
+		logger.Info("login failed")
+		logger.Info("payment declined")
+		logger.Info("noisy")
+	}
+	logger.StopStorm()
+
+	output := buf.String()
+	if n := strings.Count(output, `msg="login failed"`); n != 10 {
+		t.Errorf("Expected all 10 exempt messages, got %d", n)
+	}
+	if n := strings.Count(output, `msg="payment declined"`); n != 10 {
+		t.Errorf("Expected all 10 fingerprinted messages, got %d", n)
+	}
+	if n := strings.Count(output, "msg=noisy"); n != 0 {
+		t.Errorf("Expected noisy messages to be sampled out, got %d", n)
+	}
+}
@@ -0,0 +1,803 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// 记录变换使用的表达式语言。它只能读取当前记录、没有循环和赋值，
+// 编译和求值都有上限，可以放心地从配置文件加载：
+//
+//	bucket(duration_ms, [10, 50, 100, 500])       // "<10"、"10-50"、……、">=500"
+//	level == "DEBUG" && startswith(msg, "health")
+//	http.status >= 500 ? "server" : "client"
+//	lower(user.region) in ["cn", "hk"]
+//
+// 变量是记录的属性，分组属性用点分隔（http.status），msg 和 level 是消息和级别名称，
+// key 中含有特殊字符时用 attr("x-request-id") 读取。不存在的属性为 null。
+// 数值统一按 float64 计算，time.Duration 转换为毫秒。
+//
+// 运算符按优先级从低到高：?:、||、&&、== != < <= > >= in、+ -、* / %、! -（一元）。
+// 函数：bucket、len、lower、upper、contains、startswith、endswith、matches（第二个参数必须是字符串字面量）、
+// has、attr、str、num、round。
+const (
+	MaxExprLength    = 1024 // 表达式源码的最大长度
+	MaxExprNodes     = 256  // 表达式语法树的最大节点数，没有循环，每个节点最多求值一次，因此也限制了求值耗时
+	MaxExprStringLen = 1024 // 求值过程中产生的字符串的最大长度，限制内存
+)
+
+// ErrExprLimit 表示表达式超出了长度、节点数或字符串长度的上限
+var ErrExprLimit = errors.New("slogx: expression limit exceeded")
+
+// Expr 是编译后的表达式，可以被多个 goroutine 同时使用
+type Expr struct {
+	src  string
+	root exprNode
+}
+
+// CompileExpr 编译表达式，语法见 expr.go 的说明
+func CompileExpr(src string) (*Expr, error) {
+	if len(src) > MaxExprLength {
+		return nil, fmt.Errorf("%w: expression longer than %d bytes", ErrExprLimit, MaxExprLength)
+	}
+	toks, err := tokenizeExpr(src)
+	if err != nil {
+		return nil, fmt.Errorf("slogx: expression %q: %w", src, err)
+	}
+	p := &exprParser{toks: toks}
+	root, err := p.parseCond()
+	if err == nil && p.peek().kind != exprEOF {
+		err = p.errorf("unexpected %q", p.peek().text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("slogx: expression %q: %w", src, err)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Eval 以 vars 作为变量对表达式求值，结果为 float64、string、bool、[]any 或 nil
+func (e *Expr) Eval(vars map[string]any) (any, error) {
+	return e.eval(mapEnv(vars))
+}
+
+func (e *Expr) eval(env exprEnv) (any, error) {
+	return e.root.eval(&exprState{env: env})
+}
+
+// exprEnv 为表达式提供变量
+type exprEnv interface {
+	lookup(name string) (any, bool)
+}
+
+type mapEnv map[string]any
+
+func (m mapEnv) lookup(name string) (any, bool) {
+	v, ok := m[name]
+	return exprValue(v), ok
+}
+
+// exprValue 把 Go 的值转换为表达式使用的类型
+func exprValue(v any) any {
+	switch v := v.(type) {
+	case nil, string, bool, float64:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, x := range v {
+			out[i] = exprValue(x)
+		}
+		return out
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(v)
+}
+
+type exprState struct {
+	env exprEnv
+}
+
+// checkString 限制求值过程中产生的字符串长度
+func checkString(v string) (any, error) {
+	if len(v) > MaxExprStringLen {
+		return nil, fmt.Errorf("%w: string longer than %d bytes", ErrExprLimit, MaxExprStringLen)
+	}
+	return v, nil
+}
+
+type exprNode interface {
+	eval(s *exprState) (any, error)
+}
+
+type litNode struct{ v any }
+
+func (n litNode) eval(*exprState) (any, error) {
+	return n.v, nil
+}
+
+type identNode struct{ name string }
+
+func (n identNode) eval(s *exprState) (any, error) {
+	v, _ := s.env.lookup(n.name)
+	return v, nil
+}
+
+type listNode struct{ items []exprNode }
+
+func (n listNode) eval(s *exprState) (any, error) {
+	out := make([]any, len(n.items))
+	for i, item := range n.items {
+		v, err := item.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+type unaryNode struct {
+	op string
+	x  exprNode
+}
+
+func (n unaryNode) eval(s *exprState) (any, error) {
+	v, err := n.x.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "!" {
+		b, err := truthy(v)
+		return !b, err
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return nil, fmt.Errorf("cannot negate %s", exprType(v))
+	}
+	return -f, nil
+}
+
+type condNode struct{ cond, then, els exprNode }
+
+func (n condNode) eval(s *exprState) (any, error) {
+	v, err := n.cond.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	b, err := truthy(v)
+	if err != nil {
+		return nil, err
+	}
+	if b {
+		return n.then.eval(s)
+	}
+	return n.els.eval(s)
+}
+
+type binaryNode struct {
+	op   string
+	x, y exprNode
+}
+
+func (n binaryNode) eval(s *exprState) (any, error) {
+	x, err := n.x.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	// && 和 || 短路求值
+	if n.op == "&&" || n.op == "||" {
+		b, err := truthy(x)
+		if err != nil || b == (n.op == "||") {
+			return b, err
+		}
+		y, err := n.y.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		return truthy(y)
+	}
+	y, err := n.y.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return exprEqual(x, y), nil
+	case "!=":
+		return !exprEqual(x, y), nil
+	case "in":
+		list, ok := y.([]any)
+		if !ok {
+			return nil, fmt.Errorf("right side of in must be a list, got %s", exprType(y))
+		}
+		for _, item := range list {
+			if exprEqual(x, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "<", "<=", ">", ">=":
+		c, err := exprCompare(x, y)
+		if err != nil {
+			return nil, err
+		}
+		switch n.op {
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		}
+		return c >= 0, nil
+	case "+":
+		if xs, ok := x.(string); ok {
+			if ys, ok := y.(string); ok {
+				return checkString(xs + ys)
+			}
+		}
+	}
+	xf, xok := x.(float64)
+	yf, yok := y.(float64)
+	if !xok || !yok {
+		return nil, fmt.Errorf("invalid operands for %s: %s and %s", n.op, exprType(x), exprType(y))
+	}
+	switch n.op {
+	case "+":
+		return xf + yf, nil
+	case "-":
+		return xf - yf, nil
+	case "*":
+		return xf * yf, nil
+	}
+	if yf == 0 {
+		return nil, errors.New("division by zero")
+	}
+	if n.op == "/" {
+		return xf / yf, nil
+	}
+	return math.Mod(xf, yf), nil
+}
+
+type callNode struct {
+	name string
+	fn   exprFunc
+	args []exprNode
+	re   *regexp.Regexp // matches 的模式，编译时确定
+}
+
+func (n callNode) eval(s *exprState) (any, error) {
+	args := make([]any, len(n.args))
+	for i, a := range n.args {
+		v, err := a.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	if n.re != nil {
+		str, ok := args[0].(string)
+		return ok && n.re.MatchString(str), nil
+	}
+	v, err := n.fn.call(s, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	return v, nil
+}
+
+func truthy(v any) (bool, error) {
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case nil:
+		return false, nil
+	}
+	return false, fmt.Errorf("expected bool, got %s", exprType(v))
+}
+
+func exprEqual(x, y any) bool {
+	switch x := x.(type) {
+	case []any:
+		ys, ok := y.([]any)
+		if !ok || len(x) != len(ys) {
+			return false
+		}
+		for i := range x {
+			if !exprEqual(x[i], ys[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	if _, ok := y.([]any); ok {
+		return false
+	}
+	return x == y
+}
+
+func exprCompare(x, y any) (int, error) {
+	switch x := x.(type) {
+	case float64:
+		if y, ok := y.(float64); ok {
+			switch {
+			case x < y:
+				return -1, nil
+			case x > y:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	case string:
+		if y, ok := y.(string); ok {
+			return strings.Compare(x, y), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %s and %s", exprType(x), exprType(y))
+}
+
+func exprType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case []any:
+		return "list"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// exprFunc 是表达式中可以调用的函数
+type exprFunc struct {
+	minArgs, maxArgs int
+	call             func(s *exprState, args []any) (any, error)
+}
+
+var exprFuncs map[string]exprFunc
+
+func init() {
+	str1 := func(f func(string) any) exprFunc {
+		return exprFunc{1, 1, func(_ *exprState, args []any) (any, error) {
+			s, ok := args[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("expected string, got %s", exprType(args[0]))
+			}
+			if _, err := checkString(s); err != nil {
+				return nil, err
+			}
+			return f(s), nil
+		}}
+	}
+	str2 := func(f func(string, string) bool) exprFunc {
+		return exprFunc{2, 2, func(_ *exprState, args []any) (any, error) {
+			a, ok1 := args[0].(string)
+			b, ok2 := args[1].(string)
+			if !ok1 || !ok2 {
+				return nil, fmt.Errorf("expected strings, got %s and %s", exprType(args[0]), exprType(args[1]))
+			}
+			return f(a, b), nil
+		}}
+	}
+	exprFuncs = map[string]exprFunc{
+		"bucket":     {2, 2, exprBucket},
+		"lower":      str1(func(s string) any { return strings.ToLower(s) }),
+		"upper":      str1(func(s string) any { return strings.ToUpper(s) }),
+		"contains":   str2(strings.Contains),
+		"startswith": str2(strings.HasPrefix),
+		"endswith":   str2(strings.HasSuffix),
+		"matches":    {2, 2, nil}, // 由 callNode.re 处理
+		"len": {1, 1, func(_ *exprState, args []any) (any, error) {
+			switch v := args[0].(type) {
+			case string:
+				return float64(utf8.RuneCountInString(v)), nil
+			case []any:
+				return float64(len(v)), nil
+			case nil:
+				return 0.0, nil
+			}
+			return nil, fmt.Errorf("expected string or list, got %s", exprType(args[0]))
+		}},
+		"has": {1, 1, func(s *exprState, args []any) (any, error) {
+			name, ok := args[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("expected attribute name, got %s", exprType(args[0]))
+			}
+			_, found := s.env.lookup(name)
+			return found, nil
+		}},
+		"attr": {1, 1, func(s *exprState, args []any) (any, error) {
+			name, ok := args[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("expected attribute name, got %s", exprType(args[0]))
+			}
+			v, _ := s.env.lookup(name)
+			return v, nil
+		}},
+		"str": {1, 1, func(_ *exprState, args []any) (any, error) {
+			switch v := args[0].(type) {
+			case nil:
+				return "", nil
+			case float64:
+				return formatNumber(v), nil
+			case string:
+				return v, nil
+			}
+			return checkString(fmt.Sprint(args[0]))
+		}},
+		"num": {1, 1, func(_ *exprState, args []any) (any, error) {
+			switch v := args[0].(type) {
+			case float64:
+				return v, nil
+			case string:
+				if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					return f, nil
+				}
+			case bool:
+				if v {
+					return 1.0, nil
+				}
+				return 0.0, nil
+			}
+			return nil, nil
+		}},
+		"round": {1, 1, func(_ *exprState, args []any) (any, error) {
+			f, ok := args[0].(float64)
+			if !ok {
+				return nil, fmt.Errorf("expected number, got %s", exprType(args[0]))
+			}
+			return math.Round(f), nil
+		}},
+	}
+}
+
+// exprBucket 返回 x 所在区间的标签，bounds 必须升序，例如 bucket(75, [10, 50, 100]) 为 "50-100"
+func exprBucket(_ *exprState, args []any) (any, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+	x, ok := args[0].(float64)
+	if !ok {
+		return nil, fmt.Errorf("expected number, got %s", exprType(args[0]))
+	}
+	list, ok := args[1].([]any)
+	if !ok || len(list) == 0 {
+		return nil, errors.New("bounds must be a non-empty list of numbers")
+	}
+	bounds := make([]float64, len(list))
+	for i, b := range list {
+		f, ok := b.(float64)
+		if !ok || (i > 0 && f <= bounds[i-1]) {
+			return nil, errors.New("bounds must be ascending numbers")
+		}
+		bounds[i] = f
+	}
+	if x < bounds[0] {
+		return "<" + formatNumber(bounds[0]), nil
+	}
+	for i := 1; i < len(bounds); i++ {
+		if x < bounds[i] {
+			return formatNumber(bounds[i-1]) + "-" + formatNumber(bounds[i]), nil
+		}
+	}
+	return ">=" + formatNumber(bounds[len(bounds)-1]), nil
+}
+
+const (
+	exprEOF = iota
+	exprNum
+	exprStr
+	exprIdent
+	exprOp
+)
+
+type exprToken struct {
+	kind int
+	text string
+	num  float64
+	pos  int
+}
+
+// exprOps 按长度从长到短排列，保证 <= 不会被拆成 < 和 =
+var exprOps = []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "+", "-", "*", "/", "%", "!", "(", ")", "[", "]", ",", "?", ":"}
+
+func tokenizeExpr(src string) ([]exprToken, error) {
+	var toks []exprToken
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(src) && (src[j] >= '0' && src[j] <= '9' || src[j] == '.' || src[j] == 'e' || src[j] == 'E' ||
+				(src[j] == '-' || src[j] == '+') && (src[j-1] == 'e' || src[j-1] == 'E')) {
+				j++
+			}
+			f, err := strconv.ParseFloat(src[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at offset %d", src[i:j], i)
+			}
+			toks = append(toks, exprToken{kind: exprNum, text: src[i:j], num: f, pos: i})
+			i = j
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && src[j] != c {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			lit := src[i : j+1]
+			if c == '\'' {
+				lit = strconv.Quote(strings.ReplaceAll(src[i+1:j], `\'`, `'`))
+			}
+			s, err := strconv.Unquote(lit)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at offset %d", i)
+			}
+			toks = append(toks, exprToken{kind: exprStr, text: s, pos: i})
+			i = j + 1
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			j := i
+			for j < len(src) && (src[j] == '_' || src[j] == '.' || src[j] >= 'a' && src[j] <= 'z' ||
+				src[j] >= 'A' && src[j] <= 'Z' || src[j] >= '0' && src[j] <= '9') {
+				j++
+			}
+			toks = append(toks, exprToken{kind: exprIdent, text: src[i:j], pos: i})
+			i = j
+		default:
+			op := ""
+			for _, o := range exprOps {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+			}
+			toks = append(toks, exprToken{kind: exprOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(toks, exprToken{kind: exprEOF, pos: len(src)}), nil
+}
+
+// exprParser 是按优先级递归下降的解析器
+type exprParser struct {
+	toks  []exprToken
+	pos   int
+	nodes int
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.toks[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	t := p.toks[p.pos]
+	if t.kind != exprEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *exprParser) isOp(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind == exprOp || t.kind == exprIdent && t.text == "in" {
+		for _, op := range ops {
+			if t.text == op {
+				return op, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (p *exprParser) expect(op string) error {
+	if _, ok := p.isOp(op); !ok {
+		return p.errorf("expected %q", op)
+	}
+	p.next()
+	return nil
+}
+
+func (p *exprParser) errorf(format string, args ...any) error {
+	return fmt.Errorf(format+" at offset %d", append(args, p.peek().pos)...)
+}
+
+// node 统计语法树节点数
+func (p *exprParser) node(n exprNode) (exprNode, error) {
+	if p.nodes++; p.nodes > MaxExprNodes {
+		return nil, fmt.Errorf("%w: more than %d nodes", ErrExprLimit, MaxExprNodes)
+	}
+	return n, nil
+}
+
+func (p *exprParser) parseCond() (exprNode, error) {
+	cond, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.isOp("?"); !ok {
+		return cond, nil
+	}
+	p.next()
+	then, err := p.parseCond()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	els, err := p.parseCond()
+	if err != nil {
+		return nil, err
+	}
+	return p.node(condNode{cond: cond, then: then, els: els})
+}
+
+// exprLevels 是二元运算符的优先级，从低到高
+var exprLevels = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">=", "in"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *exprParser) parseBinary(level int) (exprNode, error) {
+	if level == len(exprLevels) {
+		return p.parseUnary()
+	}
+	x, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.isOp(exprLevels[level]...)
+		if !ok {
+			return x, nil
+		}
+		p.next()
+		y, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		if x, err = p.node(binaryNode{op: op, x: x, y: y}); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if op, ok := p.isOp("!", "-"); ok {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return p.node(unaryNode{op: op, x: x})
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	t := p.next()
+	switch t.kind {
+	case exprNum:
+		return p.node(litNode{t.num})
+	case exprStr:
+		return p.node(litNode{t.text})
+	case exprIdent:
+		switch t.text {
+		case "true", "false":
+			return p.node(litNode{t.text == "true"})
+		case "null":
+			return p.node(litNode{nil})
+		}
+		if _, ok := p.isOp("("); ok {
+			return p.parseCall(t)
+		}
+		return p.node(identNode{t.text})
+	case exprOp:
+		switch t.text {
+		case "(":
+			x, err := p.parseCond()
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		case "[":
+			items, err := p.parseArgs("]")
+			if err != nil {
+				return nil, err
+			}
+			return p.node(listNode{items})
+		}
+	}
+	if t.kind == exprEOF {
+		return nil, p.errorf("unexpected end of expression")
+	}
+	p.pos--
+	return nil, p.errorf("unexpected %q", t.text)
+}
+
+// parseArgs 解析以逗号分隔、以 end 结尾的表达式列表
+func (p *exprParser) parseArgs(end string) ([]exprNode, error) {
+	var items []exprNode
+	for {
+		if _, ok := p.isOp(end); ok {
+			p.next()
+			return items, nil
+		}
+		if len(items) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		item, err := p.parseCond()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+}
+
+func (p *exprParser) parseCall(name exprToken) (exprNode, error) {
+	fn, ok := exprFuncs[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at offset %d", name.text, name.pos)
+	}
+	p.next() // (
+	args, err := p.parseArgs(")")
+	if err != nil {
+		return nil, err
+	}
+	if len(args) < fn.minArgs || len(args) > fn.maxArgs {
+		return nil, fmt.Errorf("%s expects %d arguments, got %d at offset %d", name.text, fn.minArgs, len(args), name.pos)
+	}
+	call := callNode{name: name.text, fn: fn, args: args}
+	if name.text == "matches" {
+		lit, ok := args[1].(litNode)
+		pattern, isStr := lit.v.(string)
+		if !ok || !isStr {
+			return nil, fmt.Errorf("matches pattern must be a string literal at offset %d", name.pos)
+		}
+		if call.re, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("matches: %w", err)
+		}
+	}
+	return p.node(call)
+}
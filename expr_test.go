@@ -0,0 +1,79 @@
+package log
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExprEval(t *testing.T) {
+	vars := map[string]any{
+		"duration_ms": 75,
+		"status":      503,
+		"region":      "CN",
+		"msg":         "health check ok",
+		"tags":        []any{"a", "b"},
+	}
+	for src, want := range map[string]any{
+		`bucket(duration_ms, [10, 50, 100, 500])`:      "50-100",
+		`bucket(5, [10, 50])`:                          "<10",
+		`bucket(5000, [10, 50])`:                       ">=50",
+		`status >= 500 ? "server" : "client"`:          "server",
+		`lower(region) in ["cn", "hk"]`:                true,
+		`startswith(msg, "health") && !has("user")`:    true,
+		`missing == null || missing > 1`:               true,
+		`(1 + 2) * 3 - 10 % 4`:                         7.0,
+		`"a" + 'b' + str(1.5)`:                         "ab1.5",
+		`len(tags) == 2 && len("日志") == 2`:             true,
+		`matches(region, "^[A-Z]{2}$")`:                true,
+		`num("42") / 2`:                                21.0,
+		`round(2.5) == 3 && -duration_ms < 0`:          true,
+		`attr("status") != 200 && status <= 503.0`:     true,
+		`upper("x") + lower("Y") == "Xy"`:              true,
+		`contains(msg, "check") && endswith(msg, "k")`: true,
+	} {
+		expr, err := CompileExpr(src)
+		if err != nil {
+			t.Errorf("CompileExpr(%s): %v", src, err)
+			continue
+		}
+		if got, err := expr.Eval(vars); err != nil || got != want {
+			t.Errorf("%s = %v (%v), want %v", src, got, err, want)
+		}
+	}
+}
+
+func TestExprErrors(t *testing.T) {
+	for _, src := range []string{
+		`1 +`,
+		`(1`,
+		`foo(1)`,
+		`len(1, 2)`,
+		`matches(msg, pattern)`,
+		`"unterminated`,
+		`1 2`,
+		`@`,
+		strings.Repeat("1+", MaxExprLength),
+		strings.Repeat("1+", MaxExprNodes) + "1",
+	} {
+		if _, err := CompileExpr(src); err == nil {
+			t.Errorf("CompileExpr(%.40q) succeeded, want error", src)
+		}
+	}
+
+	for src, vars := range map[string]map[string]any{
+		`msg > 1`:             {"msg": "x"},
+		`1 / zero`:            {"zero": 0},
+		`"x" + 1`:             nil,
+		`s + s + s + s + s`:   {"s": strings.Repeat("x", MaxExprStringLen/4)},
+		`bucket(1, [50, 10])`: nil,
+		`1 in "abc"`:          nil,
+	} {
+		expr, err := CompileExpr(src)
+		if err != nil {
+			t.Fatalf("CompileExpr(%s): %v", src, err)
+		}
+		if v, err := expr.Eval(vars); err == nil {
+			t.Errorf("%s = %v, want error", src, v)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrNoWriterAvailable 表示故障转移链中所有输出都写入失败
+var ErrNoWriterAvailable = errors.New("slogx: no writer available in failover chain")
+
+// FailoverWriter 按优先级依次尝试多个输出：优先写入第一个（例如网络采集端），
+// 失败时切换到下一个（例如本地文件）；失败的输出在 probeInterval 后会用下一次写入重新探测，
+// 探测成功即切回，采集端宕机期间日志不会丢失。
+type FailoverWriter struct {
+	mu            sync.Mutex
+	writers       []io.Writer
+	retryAt       []time.Time // 每个输出下次允许重试的时间，零值表示健康
+	probeInterval time.Duration
+}
+
+// NewFailoverWriter 创建故障转移写入器，writers 按优先级从高到低排列
+func NewFailoverWriter(probeInterval time.Duration, writers ...io.Writer) *FailoverWriter {
+	return &FailoverWriter{
+		writers:       writers,
+		retryAt:       make([]time.Time, len(writers)),
+		probeInterval: probeInterval,
+	}
+}
+
+// Write 写入优先级最高的可用输出
+func (w *FailoverWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	t := now()
+	var errs []error
+	attempted := false
+	for i, writer := range w.writers {
+		if !w.retryAt[i].IsZero() && t.Before(w.retryAt[i]) {
+			continue
+		}
+		attempted = true
+		n, err := w.try(i, writer, p, t)
+		if err == nil {
+			return n, nil
+		}
+		errs = append(errs, err)
+	}
+	// 所有输出都处于等待探测状态时，不直接丢弃，按优先级再强制尝试一遍
+	if !attempted {
+		for i, writer := range w.writers {
+			n, err := w.try(i, writer, p, t)
+			if err == nil {
+				return n, nil
+			}
+			errs = append(errs, err)
+		}
+	}
+	return 0, errors.Join(append([]error{ErrNoWriterAvailable}, errs...)...)
+}
+
+// try 写入第 i 个输出并更新它的健康状态
+func (w *FailoverWriter) try(i int, writer io.Writer, p []byte, t time.Time) (int, error) {
+	n, err := writer.Write(p)
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		w.retryAt[i] = t.Add(w.probeInterval)
+		return n, err
+	}
+	w.retryAt[i] = time.Time{}
+	return n, nil
+}
+
+// Active 返回当前被视为健康的最高优先级输出的下标，全部不可用时返回 -1
+func (w *FailoverWriter) Active() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, t := range w.retryAt {
+		if t.IsZero() {
+			return i
+		}
+	}
+	return -1
+}
+
+// Close 关闭所有实现了 io.Closer 的输出
+func (w *FailoverWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var errs []error
+	for _, writer := range w.writers {
+		if c, ok := writer.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
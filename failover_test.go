@@ -0,0 +1,63 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+// flakyWriter 在 down 为 true 时写入失败
+type flakyWriter struct {
+	bytes.Buffer
+	down bool
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	if w.down {
+		return 0, errors.New("collector unavailable")
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestFailoverWriter(t *testing.T) {
+	primary := &flakyWriter{}
+	secondary := &flakyWriter{}
+	w := NewFailoverWriter(20*time.Millisecond, primary, secondary)
+
+	w.Write([]byte("a\n"))
+	primary.down = true
+	w.Write([]byte("b\n"))
+	if w.Active() != 1 {
+		t.Errorf("Expected secondary to be active, got %d", w.Active())
+	}
+
+	// 主输出恢复后，在探测间隔内仍写备用输出
+	primary.down = false
+	w.Write([]byte("c\n"))
+	time.Sleep(30 * time.Millisecond)
+	w.Write([]byte("d\n"))
+
+	if got := primary.String(); got != "a\nd\n" {
+		t.Errorf("Unexpected primary content: %q", got)
+	}
+	if got := secondary.String(); got != "b\nc\n" {
+		t.Errorf("Unexpected secondary content: %q", got)
+	}
+	if w.Active() != 0 {
+		t.Errorf("Expected primary to be active after fail back, got %d", w.Active())
+	}
+}
+
+func TestFailoverWriterAllDown(t *testing.T) {
+	primary := &flakyWriter{down: true}
+	w := NewFailoverWriter(time.Hour, primary)
+	if _, err := w.Write([]byte("a\n")); !errors.Is(err, ErrNoWriterAvailable) {
+		t.Errorf("Expected ErrNoWriterAvailable, got %v", err)
+	}
+	// 处于探测等待期时仍会强制尝试，恢复后立即可写
+	primary.down = false
+	if _, err := w.Write([]byte("b\n")); err != nil {
+		t.Errorf("Expected write to succeed after recovery, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+)
+
+// FieldMap 重命名 text/json 格式中的内置字段并控制它们的输出顺序，无需自己编写 ReplaceAttr：
+//
+//	FieldMap{Message: "message", Level: "severity", Order: []string{"level", "time", "caller", "msg"}}
+//
+// 调用位置的 key 仍由 Config.CallerKey 设置。
+type FieldMap struct {
+	Time    string   // 时间的 key，默认 "time"
+	Level   string   // 级别的 key，默认 "level"
+	Message string   // 消息的 key，默认 "msg"
+	Order   []string // 内置字段的顺序，使用 "time"、"level"、"msg"、"caller" 表示；未列出的字段按默认顺序输出，未列出的 caller 输出在属性之后
+}
+
+func (m FieldMap) isZero() bool {
+	return m.Time == "" && m.Level == "" && m.Message == "" && len(m.Order) == 0
+}
+
+// fieldOrder 返回属性之前和之后输出的内置字段
+func (m FieldMap) fieldOrder() (before, after []string) {
+	seen := make(map[string]bool)
+	for _, f := range m.Order {
+		switch f {
+		case "time", "level", "msg", "caller":
+			if !seen[f] {
+				seen[f] = true
+				before = append(before, f)
+			}
+		}
+	}
+	for _, f := range []string{"time", "level", "msg"} {
+		if !seen[f] {
+			before = append(before, f)
+		}
+	}
+	if !seen["caller"] {
+		after = []string{"caller"}
+	}
+	return before, after
+}
+
+// fieldMapHandler 按配置创建 text 或 json 格式的 FieldMap handler。slog 的 handler 无法调整内置字段的顺序，
+// 设置了 FieldMap 时 text/json 改用自己的编码
+func fieldMapHandler(w io.Writer, opts *slog.HandlerOptions, cfg Config, callerKey string) slog.Handler {
+	h := newFieldMapHandler(w, opts, cfg.FieldMap, cfg.Format == "json", cfg.TimePrecision.layout())
+	h.callerKey = callerKey
+	return h
+}
+
+// newFieldMapHandler 创建按 FieldMap 输出内置字段的 text 或 json 格式 handler
+func newFieldMapHandler(w io.Writer, opts *slog.HandlerOptions, m FieldMap, json bool, timeLayout string) *formatHandler {
+	keys := map[string]string{"time": m.Time, "level": m.Level, "msg": m.Message}
+	for f, k := range keys {
+		if k == "" {
+			keys[f] = f
+		}
+	}
+	before, after := m.fieldOrder()
+	return newFormatHandler(w, opts, func(buf *bytes.Buffer, e *entry) {
+		// first 表示当前字段是否为 JSON 对象的第一个字段
+		first := true
+		field := func(f string) {
+			var key, value string
+			switch f {
+			case "time":
+				if e.time.Key == "" {
+					return
+				}
+				key, value = keys[f], e.timeText(timeLayout)
+			case "level":
+				if e.level.Key == "" {
+					return
+				}
+				key, value = keys[f], e.levelText()
+			case "msg":
+				if e.message.Key == "" {
+					return
+				}
+				key, value = keys[f], e.message.Value.String()
+			case "caller":
+				if e.caller == "" {
+					return
+				}
+				key, value = e.callerKey, e.caller
+			}
+			if !json {
+				appendLogfmtPair(buf, key, value)
+				return
+			}
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			appendJSONString(buf, key)
+			buf.WriteByte(':')
+			appendJSONString(buf, value)
+		}
+
+		if json {
+			buf.WriteByte('{')
+		}
+		for _, f := range before {
+			field(f)
+		}
+		if json {
+			appendJSONAttrs(buf, e.attrs, !first)
+			first = first && len(e.attrs) == 0
+		} else {
+			for _, a := range e.attrs {
+				appendLogfmtAttr(buf, "", a)
+			}
+		}
+		for _, f := range after {
+			field(f)
+		}
+		if json {
+			buf.WriteByte('}')
+		}
+		buf.WriteByte('\n')
+	})
+}
@@ -0,0 +1,44 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestFieldMapJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{
+		Level:    slog.LevelDebug,
+		Format:   "json",
+		Writer:   &buf,
+		FieldMap: FieldMap{Message: "message", Level: "severity", Order: []string{"level", "msg"}},
+	})
+	logger.Info("hello", "user", "alice")
+
+	pattern := `^\{"severity":"INFO","message":"hello","time":"[^"]+","user":"alice","source":"[^"]+"\}\n$`
+	if !regexp.MustCompile(pattern).MatchString(buf.String()) {
+		t.Errorf("Output %q does not match %s", buf.String(), pattern)
+	}
+}
+
+func TestFieldMapText(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{
+		Level:         slog.LevelDebug,
+		Writer:        &buf,
+		TimePrecision: TimeOmit,
+		FieldMap:      FieldMap{Message: "message", Order: []string{"caller", "msg"}},
+	})
+	logger.Warn("disk almost full", "used", "95%")
+
+	output := buf.String()
+	if !strings.HasPrefix(output, "source=") || !strings.Contains(output, ` message="disk almost full" level=WARN used=95%`) {
+		t.Errorf("Unexpected output: %q", output)
+	}
+	if strings.Contains(output, "time=") {
+		t.Errorf("Expected time to be omitted, got %q", output)
+	}
+}
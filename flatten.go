@@ -0,0 +1,54 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+)
+
+// flattenHandler 将分组（WithGroup 和 slog.Group 属性）展开为以点分隔的 key，例如 http.method，
+// 用于不能很好处理嵌套 JSON 的日志后端
+type flattenHandler struct {
+	handler slog.Handler
+	state   attrState
+}
+
+func newFlattenHandler(h slog.Handler) *flattenHandler {
+	return &flattenHandler{handler: h}
+}
+
+func (h *flattenHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *flattenHandler) Handle(ctx context.Context, r slog.Record) error {
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	for _, a := range h.state.collect(r) {
+		nr.AddAttrs(flattenAttr("", a)...)
+	}
+	return h.handler.Handle(ctx, nr)
+}
+
+func (h *flattenHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &flattenHandler{handler: h.handler, state: h.state.withAttrs(attrs)}
+}
+
+func (h *flattenHandler) WithGroup(name string) slog.Handler {
+	return &flattenHandler{handler: h.handler, state: h.state.withGroup(name)}
+}
+
+// flattenAttr 展开分组属性，LogValuer 先解析再展开；与 slog 一致，空名分组的属性直接内联
+func flattenAttr(prefix string, a slog.Attr) []slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup {
+		a.Key = prefix + a.Key
+		return []slog.Attr{a}
+	}
+	if a.Key != "" {
+		prefix += a.Key + "."
+	}
+	var out []slog.Attr
+	for _, ga := range a.Value.Group() {
+		out = append(out, flattenAttr(prefix, ga)...)
+	}
+	return out
+}
@@ -0,0 +1,34 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestFlattenGroups(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: slog.LevelDebug, Format: "json", FlattenGroups: true, Writer: &buf})
+	logger.With("service", "api").WithGroup("http").With("method", "GET").
+		Info("request", slog.Group("response", "status", 200), slog.Group("", "inline", true))
+
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"service":              "api",
+		"http.method":          "GET",
+		"http.response.status": float64(200),
+		"http.inline":          true,
+	}
+	for k, v := range want {
+		if m[k] != v {
+			t.Errorf("%s = %v, want %v (output %s)", k, m[k], v, buf.String())
+		}
+	}
+	if _, ok := m["http"]; ok {
+		t.Errorf("Expected no nested objects, got %s", buf.String())
+	}
+}
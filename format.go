@@ -0,0 +1,228 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// entry 是交给自定义格式编码的一条日志。内置字段已经过 ReplaceAttr 处理，
+// Key 为空表示该字段被丢弃；attrs 中的 LogValuer 已解析，WithGroup 的分组以嵌套 group 表示。
+type entry struct {
+	record    slog.Record
+	time      slog.Attr
+	level     slog.Attr
+	message   slog.Attr
+	caller    string     // 调用位置，已从 attrs 中取出；CallerSource 时为 file:line
+	source    slog.Value // CallerSource 时调用位置的分组（function、file、line），其他情况为零值
+	callerKey string
+	attrs     []slog.Attr
+}
+
+// callerAttr 返回调用位置属性，CallerSource 时保留分组结构
+func (e *entry) callerAttr() slog.Attr {
+	if e.source.Kind() == slog.KindGroup {
+		return slog.Attr{Key: e.callerKey, Value: e.source}
+	}
+	return slog.String(e.callerKey, e.caller)
+}
+
+// levelText 返回级别的文本表示
+func (e *entry) levelText() string {
+	if l, ok := e.level.Value.Any().(slog.Level); ok {
+		return levelString(l)
+	}
+	return e.level.Value.String()
+}
+
+// timeText 按 layout 格式化时间，ReplaceAttr 已把时间替换为其他类型时按原样输出
+func (e *entry) timeText(layout string) string {
+	if e.time.Value.Kind() == slog.KindTime {
+		return e.time.Value.Time().Format(layout)
+	}
+	return e.time.Value.String()
+}
+
+// formatHandler 是自定义格式 handler 的公共实现，负责级别判断、WithAttrs/WithGroup、
+// ReplaceAttr 和并发写入，具体的编码交给 encode
+type formatHandler struct {
+	opts      slog.HandlerOptions
+	mu        *sync.Mutex
+	w         io.Writer
+	state     attrState
+	callerKey string
+	encode    func(buf *bytes.Buffer, e *entry)
+}
+
+func newFormatHandler(w io.Writer, opts *slog.HandlerOptions, encode func(*bytes.Buffer, *entry)) *formatHandler {
+	h := &formatHandler{mu: &sync.Mutex{}, w: w, callerKey: "source", encode: encode}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+func (h *formatHandler) Enabled(_ context.Context, level slog.Level) bool {
+	min := slog.LevelInfo
+	if h.opts.Level != nil {
+		min = h.opts.Level.Level()
+	}
+	return level >= min
+}
+
+func (h *formatHandler) Handle(_ context.Context, r slog.Record) error {
+	e := &entry{record: r, callerKey: h.callerKey}
+	if !r.Time.IsZero() {
+		e.time = h.replaceBuiltin(slog.Time(slog.TimeKey, r.Time.Round(0)))
+	}
+	e.level = h.replaceBuiltin(slog.Any(slog.LevelKey, r.Level))
+	e.message = h.replaceBuiltin(slog.String(slog.MessageKey, r.Message))
+
+	attrs := h.state.collect(r)
+	if h.callerKey != "" {
+		for i, a := range attrs {
+			if a.Key != h.callerKey {
+				continue
+			}
+			if a.Value.Kind() == slog.KindString {
+				e.caller = a.Value.String()
+			} else if file, line, ok := sourceFileLine(a.Value); ok {
+				e.caller = file + ":" + strconv.FormatInt(line, 10)
+				e.source = a.Value
+			} else {
+				continue
+			}
+			attrs = append(attrs[:i:i], attrs[i+1:]...)
+			break
+		}
+	}
+	e.attrs = h.prepare(nil, attrs)
+
+	buf := new(bytes.Buffer)
+	h.encode(buf, e)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf.Bytes())
+	return err
+}
+
+// sourceFileLine 从 CallerSource 的分组（见 sourceValue）中取出文件和行号，v 不是这种分组时 ok 为 false
+func sourceFileLine(v slog.Value) (file string, line int64, ok bool) {
+	if v.Kind() != slog.KindGroup {
+		return "", 0, false
+	}
+	var hasFile, hasLine bool
+	for _, a := range v.Group() {
+		switch {
+		case a.Key == "file" && a.Value.Kind() == slog.KindString:
+			file, hasFile = a.Value.String(), true
+		case a.Key == "line" && a.Value.Kind() == slog.KindInt64:
+			line, hasLine = a.Value.Int64(), true
+		}
+	}
+	return file, line, hasFile && hasLine
+}
+
+// replaceBuiltin 对内置字段调用 ReplaceAttr
+func (h *formatHandler) replaceBuiltin(a slog.Attr) slog.Attr {
+	if h.opts.ReplaceAttr == nil {
+		return a
+	}
+	return h.opts.ReplaceAttr(nil, a)
+}
+
+// prepare 解析 LogValuer、应用 ReplaceAttr，并按 slog 的规则丢弃空 key 和空分组、内联空名分组
+func (h *formatHandler) prepare(groups []string, attrs []slog.Attr) []slog.Attr {
+	out := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Value.Kind() == slog.KindGroup {
+			sub := h.prepare(append(groups[:len(groups):len(groups)], a.Key), a.Value.Group())
+			if len(sub) == 0 {
+				continue
+			}
+			if a.Key == "" {
+				out = append(out, sub...)
+				continue
+			}
+			out = append(out, slog.Attr{Key: a.Key, Value: slog.GroupValue(sub...)})
+			continue
+		}
+		if h.opts.ReplaceAttr != nil {
+			a = h.opts.ReplaceAttr(groups, a)
+			a.Value = a.Value.Resolve()
+		}
+		if a.Key == "" {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+func (h *formatHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.state = h.state.withAttrs(attrs)
+	return &h2
+}
+
+func (h *formatHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.state = h.state.withGroup(name)
+	return &h2
+}
+
+// FormatFactory 创建写入 w 的 handler。opts 中的 Level 由 Logger 控制，ReplaceAttr 按 Config.TimePrecision 格式化时间；
+// 调用位置以普通属性（key 为 Config.CallerKey）出现在记录中。
+type FormatFactory func(w io.Writer, opts *slog.HandlerOptions) slog.Handler
+
+// builtinFormats 是 NewLogger 内置的格式，不能被注册覆盖
+var builtinFormats = []string{"clef", "console", "csv", "ecs", "json", "logfmt", "msgpack", "protobuf", "rfc5424", "template", "text"}
+
+var (
+	formatMu        sync.RWMutex
+	formatFactories = make(map[string]FormatFactory)
+)
+
+// RegisterFormat 注册一个自定义格式，之后可以在 Config.Format 中通过 name 引用，便于从配置文件选择自己的 slog handler。
+// 与 RegisterSink 一样，重复注册、与内置格式同名或 factory 为 nil 时会 panic。
+func RegisterFormat(name string, factory FormatFactory) {
+	formatMu.Lock()
+	defer formatMu.Unlock()
+	if factory == nil {
+		panic("slogx: RegisterFormat factory is nil")
+	}
+	if _, dup := formatFactories[name]; dup || slices.Contains(builtinFormats, name) {
+		panic("slogx: RegisterFormat called twice for format " + name)
+	}
+	formatFactories[name] = factory
+}
+
+// Formats 返回所有可用的格式名称，包括内置格式
+func Formats() []string {
+	formatMu.RLock()
+	defer formatMu.RUnlock()
+	names := append([]string(nil), builtinFormats...)
+	for name := range formatFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupFormat 返回注册的格式
+func lookupFormat(name string) (FormatFactory, bool) {
+	formatMu.RLock()
+	defer formatMu.RUnlock()
+	factory, ok := formatFactories[name]
+	return factory, ok
+}
@@ -0,0 +1,60 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// registerTestFormat 注册测试用的格式，测试结束时取消注册，测试可以重复运行
+func registerTestFormat(t *testing.T, name string, factory FormatFactory) {
+	t.Helper()
+	RegisterFormat(name, factory)
+	t.Cleanup(func() {
+		formatMu.Lock()
+		defer formatMu.Unlock()
+		delete(formatFactories, name)
+	})
+}
+
+func TestRegisterFormat(t *testing.T) {
+	registerTestFormat(t, "test-upper", func(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+		opts.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.MessageKey {
+				a.Value = slog.StringValue(strings.ToUpper(a.Value.String()))
+			}
+			return a
+		}
+		return slog.NewJSONHandler(w, opts)
+	})
+
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: slog.LevelInfo, Format: "test-upper", Writer: &buf})
+	logger.Debug("hidden")
+	logger.Info("hello", "k", "v")
+	output := buf.String()
+	if !strings.Contains(output, `"msg":"HELLO"`) || !strings.Contains(output, `"source":`) || strings.Contains(output, "hidden") {
+		t.Errorf("Expected custom format with level and caller, got: %s", output)
+	}
+
+	found := false
+	for _, name := range Formats() {
+		found = found || name == "test-upper"
+	}
+	if !found {
+		t.Errorf("Expected registered format in Formats(), got %v", Formats())
+	}
+
+	for _, name := range []string{"test-upper", "json"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected panic when registering %q", name)
+				}
+			}()
+			RegisterFormat(name, func(w io.Writer, opts *slog.HandlerOptions) slog.Handler { return slog.NewTextHandler(w, opts) })
+		}()
+	}
+}
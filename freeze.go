@@ -0,0 +1,158 @@
+package log
+
+import (
+	"context"
+	"sync"
+)
+
+// freezer 是可以暂停滚动的文件输出，rotatingFile、timeRotator 和 BufferedWriter 都实现了它
+type freezer interface {
+	// freeze 落盘已写入的数据，暂停滚动，等待正在执行的压缩、归档和清理完成
+	freeze(ctx context.Context) error
+	// thaw 取消一次 freeze，冻结期间推迟的滚动和后台任务随后执行
+	thaw()
+}
+
+// FreezeForBackup 冻结默认 Logger 的日志文件，见 Logger.FreezeForBackup
+func FreezeForBackup(ctx context.Context) (release func(), err error) {
+	return defaultLogger.FreezeForBackup(ctx)
+}
+
+// FreezeForBackup 将缓冲的日志写入文件并落盘，暂停滚动和后台压缩、归档、清理，在文件处于一致状态后返回，
+// 主机上的备份程序可以在 release 之前对日志目录做快照，不会拍到写了一半的缓冲或滚动到一半的文件：
+//
+//	release, err := log.FreezeForBackup(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	defer release()
+//	snapshot("/var/log/app")
+//
+// 冻结期间日志照常写入当前文件，每条记录一次 write，快照中的记录总是完整的；超过 MaxSize、到达新周期
+// 和手动 Rotate 都推迟到 release 之后。ctx 在等待后台任务时结束则取消冻结并返回 ctx.Err()。
+// release 可以重复调用，只有第一次生效；FreezeForBackup 可以嵌套，全部 release 后才恢复滚动。
+func (l *Logger) FreezeForBackup(ctx context.Context) (release func(), err error) {
+	f, ok := l.rotator.(freezer)
+	if ok {
+		if err := f.freeze(ctx); err != nil {
+			return nil, err
+		}
+	}
+	var once sync.Once
+	release = func() {
+		if ok {
+			once.Do(f.thaw)
+		}
+	}
+	// 其他输出和 Sink 只刷新，文件已在 freeze 中落盘
+	if err := l.Sync(); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+func (f *rotatingFile) freeze(ctx context.Context) error {
+	f.mu.Lock()
+	f.frozen.Add(1)
+	var err error
+	if f.file != nil {
+		err = f.file.Sync()
+	}
+	f.mu.Unlock()
+	if err != nil {
+		f.thaw()
+		return err
+	}
+	// 已冻结，不会再启动新的任务 goroutine，正在执行的任务完成后它就会退出
+	f.mill.Lock()
+	idle := f.idle
+	f.mill.Unlock()
+	if idle == nil {
+		return nil
+	}
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		f.thaw()
+		return ctx.Err()
+	}
+}
+
+func (f *rotatingFile) thaw() {
+	f.mu.Lock()
+	var err error
+	if f.frozen.Add(-1) == 0 && f.rotateDue && !f.closed {
+		f.rotateDue = false
+		err = f.rotate()
+	}
+	f.mu.Unlock()
+	if err != nil {
+		fileError(f.cfg, err)
+	}
+	f.mill.Lock()
+	f.runTasks()
+	f.mill.Unlock()
+}
+
+func (r *timeRotator) freeze(ctx context.Context) error {
+	r.mu.Lock()
+	if r.current == nil {
+		// 还没有写入过，先确定当前周期的文件，冻结期间的写入不会切换周期
+		if err := r.switchPeriod(now()); err != nil {
+			r.mu.Unlock()
+			return err
+		}
+	}
+	r.frozen++
+	current, finished := r.current, r.finished
+	r.mu.Unlock()
+	if err := current.(freezer).freeze(ctx); err != nil {
+		r.unfreeze()
+		return err
+	}
+	if finished == nil {
+		return nil
+	}
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		r.thaw()
+		return ctx.Err()
+	}
+}
+
+func (r *timeRotator) thaw() {
+	r.mu.Lock()
+	current := r.current
+	r.mu.Unlock()
+	if current != nil {
+		current.(freezer).thaw()
+	}
+	r.unfreeze()
+}
+
+// unfreeze 恢复周期切换，下一次写入时发现周期已结束则切换
+func (r *timeRotator) unfreeze() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.frozen--
+}
+
+func (w *BufferedWriter) freeze(ctx context.Context) error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if f, ok := w.w.(freezer); ok {
+		return f.freeze(ctx)
+	}
+	return nil
+}
+
+func (w *BufferedWriter) thaw() {
+	if f, ok := w.w.(freezer); ok {
+		f.thaw()
+	}
+}
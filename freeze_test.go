@@ -0,0 +1,88 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFreezeForBackup(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	logger := NewLogger(Config{
+		Level:         slog.LevelDebug,
+		Filename:      filename,
+		MaxSize:       1,
+		BufferSize:    64 * 1024,
+		FlushInterval: time.Hour,
+		NoStdout:      true,
+	})
+	defer logger.Close()
+
+	logger.Info("before freeze")
+	release, err := logger.FreezeForBackup(context.Background())
+	if err != nil {
+		t.Fatalf("FreezeForBackup failed: %v", err)
+	}
+	if content, _ := os.ReadFile(filename); !strings.Contains(string(content), "before freeze") {
+		t.Errorf("Expected buffered record flushed on freeze, got %q", content)
+	}
+
+	// 冻结期间超过 MaxSize 和手动 Rotate 都不滚动
+	big := strings.Repeat("x", 600<<10)
+	logger.Info(big)
+	logger.Info(big)
+	if err := logger.Rotate(); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	logger.Sync()
+	if backups, _ := filepath.Glob(filepath.Join(dir, "app-*.log")); len(backups) != 0 {
+		t.Fatalf("Expected no rotation while frozen, got %v", backups)
+	}
+
+	release()
+	release()
+	if backups, _ := filepath.Glob(filepath.Join(dir, "app-*.log")); len(backups) != 1 {
+		t.Errorf("Expected deferred Rotate to run on release, got %v", backups)
+	}
+}
+
+func TestFreezeForBackupWaitsForBackgroundTasks(t *testing.T) {
+	f := newFileOutput(Config{}, filepath.Join(t.TempDir(), "app.log"))
+	defer f.Close()
+	started, unblock := make(chan struct{}), make(chan struct{})
+	f.background(func() {
+		close(started)
+		<-unblock
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := f.freeze(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("freeze = %v, want deadline exceeded while a task runs", err)
+	}
+	if f.frozen.Load() != 0 {
+		t.Fatal("Expected failed freeze to be undone")
+	}
+
+	ran := make(chan struct{})
+	f.background(func() { close(ran) })
+	done := make(chan error)
+	go func() { done <- f.freeze(context.Background()) }()
+	close(unblock)
+	if err := <-done; err != nil {
+		t.Fatalf("freeze failed: %v", err)
+	}
+	f.thaw()
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("Expected queued task to run after thaw")
+	}
+}
@@ -1,5 +1,5 @@
 module github.com/luojiego/slogx
 
-go 1.21
+go 1.22
 
-require gopkg.in/natefinch/lumberjack.v2 v2.2.1
+require github.com/klauspost/compress v1.18.0
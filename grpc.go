@@ -0,0 +1,60 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// grpcServerErrors 是表示服务端故障的 gRPC 状态码，其他非 OK 状态码通常由调用方引起
+var grpcServerErrors = map[string]bool{
+	"Unknown":          true,
+	"DeadlineExceeded": true,
+	"Unimplemented":    true,
+	"Internal":         true,
+	"Unavailable":      true,
+	"DataLoss":         true,
+}
+
+// StartRPC 使用默认 Logger 开始记录一次 RPC，见 Logger.StartRPC
+func StartRPC(ctx context.Context, method, peer string, md map[string][]string) (context.Context, func(code string, err error)) {
+	return defaultLogger.StartRPC(ctx, method, peer, md)
+}
+
+// StartRPC 开始记录一次 RPC：从 md 读取关联 ID（没有请求 ID 时生成一个），
+// 将绑定了 method、peer 和 request_id 的子 logger 通过 IntoContext 放入返回的 context。
+// 调用返回的函数结束记录，输出包含 code 和 duration 的一条记录：OK 为 Info，
+// Internal、Unavailable 等服务端错误为 Error，其他状态码为 Warn。
+// 设置了 Config.TailSampling 时 RPC 内的记录按尾部采样缓存，服务端错误视为失败。
+//
+// 完成记录在 gRPC 内部的栈帧中输出，不带调用位置。
+// slogx 不依赖 gRPC，现成的拦截器见 github.com/luojiego/slogx/grpcx 模块，
+// handler 中使用 log.FromContext(ctx) 获取 logger。
+func (l *Logger) StartRPC(ctx context.Context, method, peer string, md map[string][]string) (context.Context, func(code string, err error)) {
+	start := time.Now()
+	ctx = ExtractMetadata(ctx, md)
+	args := []any{"method", method}
+	if peer != "" {
+		args = append(args, "peer", peer)
+	}
+	logger := l.With(append(args, CorrelationArgs(ctx)...)...)
+	ctx = IntoContext(ctx, logger)
+	tailDone := func(bool) {}
+	if l.tail != nil {
+		ctx, tailDone = l.TailSample(ctx, *l.tail)
+	}
+	return ctx, func(code string, err error) {
+		tailDone(grpcServerErrors[code])
+		level := slog.LevelInfo
+		if grpcServerErrors[code] {
+			level = slog.LevelError
+		} else if code != "OK" {
+			level = slog.LevelWarn
+		}
+		args := []any{slog.String("code", code), slog.Duration("duration", time.Since(start))}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+		}
+		logger.logAt(ctx, level, "gRPC call", 0, args)
+	}
+}
@@ -0,0 +1,36 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStartRPC(t *testing.T) {
+	var buf strings.Builder
+	logger := NewLogger(Config{Writer: &buf, Format: "json"})
+	md := map[string][]string{"x-request-id": {"req-1"}}
+
+	ctx, done := logger.StartRPC(context.Background(), "/orders.Orders/Create", "10.0.0.1:5000", md)
+	FromContext(ctx).Info("handling")
+	done("OK", nil)
+	_, done = logger.StartRPC(context.Background(), "/orders.Orders/Get", "", nil)
+	done("Unavailable", errors.New("db down"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %q", buf.String())
+	}
+	for _, want := range []string{`"method":"/orders.Orders/Create"`, `"peer":"10.0.0.1:5000"`, `"request_id":"req-1"`} {
+		if !strings.Contains(lines[0], want) || !strings.Contains(lines[1], want) {
+			t.Errorf("Expected %s in both records, got:\n%s", want, buf.String())
+		}
+	}
+	if !strings.Contains(lines[1], `"level":"INFO"`) || !strings.Contains(lines[1], `"code":"OK"`) || !strings.Contains(lines[1], `"duration":`) {
+		t.Errorf("Unexpected completion record %s", lines[1])
+	}
+	if !strings.Contains(lines[2], `"level":"ERROR"`) || !strings.Contains(lines[2], `"error":"db down"`) || !strings.Contains(lines[2], `"request_id":"`) {
+		t.Errorf("Unexpected failure record %s", lines[2])
+	}
+}
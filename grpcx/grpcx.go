@@ -0,0 +1,61 @@
+// Package grpcx 提供记录 gRPC 调用的服务端拦截器，拦截器通过 slogx 的 StartRPC 记录每次调用。
+//
+// 拦截器放在单独的模块中，slogx 本身不依赖 gRPC：
+//
+//	srv := grpc.NewServer(
+//		grpc.ChainUnaryInterceptor(grpcx.UnaryServerInterceptor()),
+//		grpc.ChainStreamInterceptor(grpcx.StreamServerInterceptor()),
+//	)
+//
+// handler 中使用 log.FromContext(ctx) 获取绑定了 method、peer 和 request_id 的 logger。
+package grpcx
+
+import (
+	"context"
+
+	log "github.com/luojiego/slogx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor 返回记录一元调用的拦截器，使用调用时的默认 Logger，见 log.StartRPC
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, done := start(ctx, info.FullMethod)
+		resp, err := handler(ctx, req)
+		done(status.Code(err).String(), err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor 返回记录流式调用的拦截器，handler 从 ServerStream.Context 获取的 context 带有 logger
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, done := start(ss.Context(), info.FullMethod)
+		err := handler(srv, &loggedStream{ServerStream: ss, ctx: ctx})
+		done(status.Code(err).String(), err)
+		return err
+	}
+}
+
+// start 从 ctx 中取出元数据和对端地址，开始记录一次调用
+func start(ctx context.Context, method string) (context.Context, func(code string, err error)) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var addr string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		addr = p.Addr.String()
+	}
+	return log.GetDefaultLogger().StartRPC(ctx, method, addr, md)
+}
+
+// loggedStream 替换 ServerStream 的 context
+type loggedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *loggedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,157 @@
+package grpcx
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	log "github.com/luojiego/slogx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// syncBuffer 是并发安全的 bytes.Buffer，服务端在自己的 goroutine 中输出日志
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// useLogger 将默认 Logger 替换为输出 JSON 到缓冲区的 Logger，测试结束时恢复
+func useLogger(t *testing.T) *syncBuffer {
+	t.Helper()
+	buf := &syncBuffer{}
+	saved := log.GetDefaultLogger()
+	log.SetDefaultLogger(log.NewLogger(log.Config{Writer: buf, Format: "json"}))
+	t.Cleanup(func() { log.SetDefaultLogger(saved) })
+	return buf
+}
+
+// startServer 在内存连接上启动带有拦截器的 health 服务，返回客户端
+func startServer(t *testing.T) healthpb.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(StreamServerInterceptor()),
+	)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	buf := useLogger(t)
+	client := startServer(t)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-42")
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("Check error = %v, want NotFound", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected one record per call, got %q", buf.String())
+	}
+	for _, want := range []string{`"level":"INFO"`, `"msg":"gRPC call"`, `"method":"/grpc.health.v1.Health/Check"`, `"request_id":"req-42"`, `"code":"OK"`, `"peer":"bufconn"`} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("Expected %s in %s", want, lines[0])
+		}
+	}
+	if strings.Contains(lines[0], `"source"`) {
+		t.Errorf("Expected no caller inside gRPC internals, got %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"level":"WARN"`) || !strings.Contains(lines[1], `"code":"NotFound"`) {
+		t.Errorf("Expected a Warn record for NotFound, got %s", lines[1])
+	}
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	buf := useLogger(t)
+	client := startServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Recv failed: %v", err)
+	}
+	cancel()
+
+	// 流在服务端感知到取消后才结束
+	const want = `"method":"/grpc.health.v1.Health/Watch"`
+	for deadline := time.Now().Add(2 * time.Second); !strings.Contains(buf.String(), want) && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+	}
+	out := buf.String()
+	if !strings.Contains(out, want) || !strings.Contains(out, `"code":"Canceled"`) {
+		t.Errorf("Expected a record for the canceled stream, got %q", out)
+	}
+}
+
+// fakeStream 是只提供 Context 的 ServerStream
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamContextCarriesLogger(t *testing.T) {
+	buf := useLogger(t)
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"}
+	err := StreamServerInterceptor()(nil, fakeStream{ctx: context.Background()}, info, func(_ any, ss grpc.ServerStream) error {
+		log.FromContext(ss.Context()).InfoContext(ss.Context(), "inside handler")
+		return status.Error(codes.Internal, "boom")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("error = %v, want Internal", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"method":"/test.Service/Stream"`) {
+		t.Fatalf("Expected the handler record to carry the method, got %q", buf.String())
+	}
+	if !strings.Contains(lines[1], `"level":"ERROR"`) || !strings.Contains(lines[1], `"code":"Internal"`) {
+		t.Errorf("Expected an Error record for Internal, got %s", lines[1])
+	}
+}
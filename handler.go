@@ -0,0 +1,148 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// multiHandler 将记录分发给多个 handler，例如主输出和各个 Sink
+type multiHandler struct {
+	handlers []slog.Handler
+}
+
+// newMultiHandler 只有一个 handler 时直接返回它，避免多一层分发
+func newMultiHandler(handlers ...slog.Handler) slog.Handler {
+	if len(handlers) == 1 {
+		return handlers[0]
+	}
+	return &multiHandler{handlers: handlers}
+}
+
+func (h *multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, hh := range h.handlers {
+		if hh.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, hh := range h.handlers {
+		if !hh.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := hh.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, hh := range h.handlers {
+		handlers[i] = hh.WithAttrs(attrs)
+	}
+	return &multiHandler{handlers: handlers}
+}
+
+func (h *multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, hh := range h.handlers {
+		handlers[i] = hh.WithGroup(name)
+	}
+	return &multiHandler{handlers: handlers}
+}
+
+// minLevelHandler 只放行达到 min 的记录，用于单个输出或 Sink 自己的最低级别
+type minLevelHandler struct {
+	slog.Handler
+	min slog.Leveler
+}
+
+func (h *minLevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.min.Level() && h.Handler.Enabled(ctx, level)
+}
+
+func (h *minLevelHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.min.Level() {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *minLevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &minLevelHandler{Handler: h.Handler.WithAttrs(attrs), min: h.min}
+}
+
+func (h *minLevelHandler) WithGroup(name string) slog.Handler {
+	return &minLevelHandler{Handler: h.Handler.WithGroup(name), min: h.min}
+}
+
+// groupOrAttrs 记录 WithGroup/WithAttrs 的调用顺序，group 非空时表示一次 WithGroup
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// attrState 保存 handler 通过 WithAttrs/WithGroup 累积的属性，
+// 供需要在 Handle 时拿到完整属性列表的 handler 复用
+type attrState struct {
+	goas []groupOrAttrs
+}
+
+func (s attrState) withAttrs(attrs []slog.Attr) attrState {
+	if len(attrs) == 0 {
+		return s
+	}
+	return attrState{goas: append(s.goas[:len(s.goas):len(s.goas)], groupOrAttrs{attrs: attrs})}
+}
+
+func (s attrState) withGroup(name string) attrState {
+	if name == "" {
+		return s
+	}
+	return attrState{goas: append(s.goas[:len(s.goas):len(s.goas)], groupOrAttrs{group: name})}
+}
+
+// collect 返回记录的完整属性列表，WithGroup 的分组以嵌套的 group 属性表示；
+// 与 slog 内置 handler 一致，不包含任何属性的分组会被省略
+func (s attrState) collect(r slog.Record) []slog.Attr {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	for i := len(s.goas) - 1; i >= 0; i-- {
+		goa := s.goas[i]
+		if goa.group != "" {
+			if len(attrs) == 0 {
+				continue
+			}
+			attrs = []slog.Attr{{Key: goa.group, Value: slog.GroupValue(attrs...)}}
+			continue
+		}
+		attrs = append(append([]slog.Attr(nil), goa.attrs...), attrs...)
+	}
+	return attrs
+}
+
+// hasAttr 判断顶层属性中是否有名为 key 的属性
+func hasAttr(attrs []slog.Attr, key string) bool {
+	for _, a := range attrs {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// flatten 返回带有完整属性的新记录，用于把记录交给不感知 WithAttrs 的下游
+func (s attrState) flatten(r slog.Record) slog.Record {
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	nr.AddAttrs(s.collect(r)...)
+	return nr
+}
@@ -0,0 +1,121 @@
+package log
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"runtime/debug"
+	"sort"
+)
+
+// HeaderMessage 是流头记录的消息，头记录的字段放在 header 分组中
+const HeaderMessage = "stream header"
+
+// HeaderSchemaVersion 是头记录所描述的记录结构的版本，内置字段或头记录本身的结构变化时递增
+const HeaderSchemaVersion = 1
+
+// StreamHeader 是 Config.StreamHeader 开启时写在每个日志文件和输出流开头的头记录，
+// 下游解析程序读取历史日志时可以据此适应格式和字段的变化，而不需要事先知道写入时的配置
+type StreamHeader struct {
+	SchemaVersion int               // 记录结构的版本，见 HeaderSchemaVersion
+	Format        string            // 输出格式，例如 json、text
+	Fields        map[string]string // 内置字段（time、level、msg、caller、logger、record_id）实际使用的 key
+	Static        map[string]string // Config.HeaderFields 中的静态字段，例如 service、region
+	ConfigHash    string            // 影响输出格式的配置项的摘要，格式配置变化时随之变化
+	Build         map[string]string // 程序的构建信息：Go 版本、模块路径和版本、VCS 修订号
+}
+
+// LogValue 以 header 分组的形式输出头记录，map 按 key 排序
+func (h StreamHeader) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("schema_version", h.SchemaVersion),
+		slog.String("format", h.Format),
+		slog.Attr{Key: "fields", Value: sortedGroup(h.Fields)},
+		slog.Attr{Key: "static", Value: sortedGroup(h.Static)},
+		slog.String("config_hash", h.ConfigHash),
+		slog.Attr{Key: "build", Value: sortedGroup(h.Build)},
+	)
+}
+
+// sortedGroup 将 map 转换为按 key 排序的分组
+func sortedGroup(m map[string]string) slog.Value {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	attrs := make([]slog.Attr, len(keys))
+	for i, k := range keys {
+		attrs[i] = slog.String(k, m[k])
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// newStreamHeader 按配置生成头记录的内容
+func newStreamHeader(cfg Config, callerKey string) StreamHeader {
+	format := cfg.Format
+	if format == "" {
+		format = "text"
+	}
+	fields := map[string]string{
+		"time":   "time",
+		"level":  "level",
+		"msg":    "msg",
+		"caller": callerKey,
+		"logger": LoggerKey,
+	}
+	for f, k := range map[string]string{"time": cfg.FieldMap.Time, "level": cfg.FieldMap.Level, "msg": cfg.FieldMap.Message} {
+		if k != "" {
+			fields[f] = k
+		}
+	}
+	if cfg.RecordIDKey != "" {
+		fields["record_id"] = cfg.RecordIDKey
+	}
+	return StreamHeader{
+		SchemaVersion: HeaderSchemaVersion,
+		Format:        format,
+		Fields:        fields,
+		Static:        cfg.HeaderFields,
+		ConfigHash:    configHash(cfg, callerKey),
+		Build:         buildInfo(),
+	}
+}
+
+// configHash 返回影响输出格式的配置项的 SHA-256 摘要（前 16 个十六进制字符）。
+// fmt 输出 map 时按 key 排序，相同的配置总是得到相同的摘要
+func configHash(cfg Config, callerKey string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%q|%+v|%q|%q|%d|%d|%v|%q|%q|%v|%v|%v|%q|%v|%v",
+		cfg.Format, cfg.FieldMap, callerKey, cfg.CallerTemplate, cfg.CallerFormat, cfg.TimePrecision,
+		cfg.LevelNames, cfg.LineTemplate, cfg.CSVColumns, cfg.CSVHeader, cfg.PrettyJSON, cfg.FlattenGroups,
+		cfg.RecordIDKey, cfg.HeaderFields, cfg.CallerSource)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// buildInfo 返回程序的构建信息，二进制中没有构建信息时只有 Go 版本
+func buildInfo() map[string]string {
+	info := map[string]string{"go": runtime.Version()}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info["go"] = bi.GoVersion
+	info["path"] = bi.Main.Path
+	info["version"] = bi.Main.Version
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision", "vcs.time", "vcs.modified":
+			info[s.Key] = s.Value
+		}
+	}
+	return info
+}
+
+// record 返回头记录，时间为生成头记录（创建 Logger）的时间
+func (h StreamHeader) record() slog.Record {
+	r := slog.NewRecord(now(), slog.LevelInfo, HeaderMessage, 0)
+	r.AddAttrs(slog.Any("header", h))
+	return r
+}
@@ -0,0 +1,82 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStreamHeaderInEachFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	logger := NewLogger(Config{
+		Filename:     filename,
+		Format:       "json",
+		FieldMap:     FieldMap{Message: "message"},
+		StreamHeader: true,
+		HeaderFields: map[string]string{"service": "api"},
+		NoStdout:     true,
+	})
+	defer logger.Close()
+
+	logger.Info("first")
+	if err := logger.Rotate(); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	logger.Info("second")
+
+	backups, _ := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	if len(backups) != 1 {
+		t.Fatalf("backups = %v, want 1", backups)
+	}
+	for _, path := range append(backups, filename) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("%s has %d lines, want header and one record: %q", path, len(lines), content)
+		}
+		var rec struct {
+			Message string `json:"message"`
+			Header  struct {
+				SchemaVersion int               `json:"schema_version"`
+				Format        string            `json:"format"`
+				Fields        map[string]string `json:"fields"`
+				Static        map[string]string `json:"static"`
+				ConfigHash    string            `json:"config_hash"`
+				Build         map[string]string `json:"build"`
+			} `json:"header"`
+		}
+		if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+			t.Fatalf("header is not JSON: %v", err)
+		}
+		h := rec.Header
+		if rec.Message != HeaderMessage || h.SchemaVersion != HeaderSchemaVersion || h.Format != "json" {
+			t.Errorf("Unexpected header in %s: %s", path, lines[0])
+		}
+		if h.Fields["msg"] != "message" || h.Fields["caller"] != "source" || h.Static["service"] != "api" {
+			t.Errorf("Unexpected header fields in %s: %s", path, lines[0])
+		}
+		if len(h.ConfigHash) != 16 || h.Build["go"] == "" {
+			t.Errorf("Expected config hash and build info in %s: %s", path, lines[0])
+		}
+	}
+}
+
+func TestStreamHeaderOnWriter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Writer: &buf, Format: "logfmt", StreamHeader: true})
+	logger.Info("hello")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `msg="stream header"`) || !strings.Contains(lines[1], "msg=hello") {
+		t.Fatalf("Expected header before the first record, got %q", buf.String())
+	}
+	if configHash(Config{Format: "json"}, "source") == configHash(Config{Format: "logfmt"}, "source") {
+		t.Error("Expected config hash to change with the format")
+	}
+}
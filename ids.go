@@ -0,0 +1,181 @@
+package log
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// IDGenerator 生成唯一 ID，请求 ID、记录 ID 等所有自动生成的 ID 都通过它获取。
+// 确定性仿真测试或有特殊要求的环境（例如必须使用 FIPS 认证的随机数源）可以通过 SetIDGenerator 替换。
+type IDGenerator interface {
+	NewID() string
+}
+
+// Clock 提供当前时间，记录的时间戳、按时间轮转、故障探测等都通过它获取时间
+type Clock interface {
+	Now() time.Time
+}
+
+// IDGeneratorFunc 将普通函数适配为 IDGenerator
+type IDGeneratorFunc func() string
+
+func (f IDGeneratorFunc) NewID() string { return f() }
+
+// ClockFunc 将普通函数适配为 Clock
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time { return f() }
+
+// SystemClock 是使用 time.Now 的默认时钟
+var SystemClock Clock = ClockFunc(time.Now)
+
+var (
+	idGenerator  atomic.Pointer[IDGenerator]
+	globalClock  atomic.Pointer[Clock]
+	crockford32  = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	defaultIDGen = NewULIDGenerator(nil, nil)
+)
+
+// SetIDGenerator 替换全局 ID 生成器，传入 nil 时恢复默认的 ULID 生成器
+func SetIDGenerator(g IDGenerator) {
+	if g == nil {
+		g = defaultIDGen
+	}
+	idGenerator.Store(&g)
+}
+
+// SetClock 替换全局时钟，传入 nil 时恢复 SystemClock
+func SetClock(c Clock) {
+	if c == nil {
+		c = SystemClock
+	}
+	globalClock.Store(&c)
+}
+
+// NewID 使用全局 ID 生成器生成一个 ID
+func NewID() string {
+	if g := idGenerator.Load(); g != nil {
+		return (*g).NewID()
+	}
+	return defaultIDGen.NewID()
+}
+
+// now 返回全局时钟的当前时间
+func now() time.Time {
+	if c := globalClock.Load(); c != nil {
+		return (*c).Now()
+	}
+	return time.Now()
+}
+
+// ulidGenerator 生成 ULID：48 位毫秒时间戳 + 80 位随机数，Crockford base32 编码，按时间字典序可排序
+type ulidGenerator struct {
+	clock Clock
+	rand  io.Reader
+}
+
+// NewULIDGenerator 创建 ULID 生成器，clock 为 nil 时使用全局时钟，rand 为 nil 时使用 crypto/rand
+func NewULIDGenerator(clock Clock, rand io.Reader) IDGenerator {
+	return &ulidGenerator{clock: clock, rand: rand}
+}
+
+func (g *ulidGenerator) NewID() string {
+	var t time.Time
+	if g.clock != nil {
+		t = g.clock.Now()
+	} else {
+		t = now()
+	}
+	var b [16]byte
+	ms := uint64(t.UnixMilli())
+	b[0], b[1], b[2], b[3], b[4], b[5] = byte(ms>>40), byte(ms>>32), byte(ms>>24), byte(ms>>16), byte(ms>>8), byte(ms)
+	readRandom(g.rand, b[6:])
+	return encodeULID(b)
+}
+
+// encodeULID 将 128 位数据编码为 26 个字符的 Crockford base32
+func encodeULID(b [16]byte) string {
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	var out [26]byte
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = crockford32[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// uuidGenerator 生成随机的 UUID v4
+type uuidGenerator struct {
+	rand io.Reader
+}
+
+// NewUUIDGenerator 创建 UUID v4 生成器，rand 为 nil 时使用 crypto/rand
+func NewUUIDGenerator(rand io.Reader) IDGenerator {
+	return &uuidGenerator{rand: rand}
+}
+
+func (g *uuidGenerator) NewID() string {
+	var b [16]byte
+	readRandom(g.rand, b[:])
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return formatUUID(b)
+}
+
+// uuidV7Generator 生成 UUID v7：48 位毫秒时间戳 + 版本和变体 + 74 位随机数，与 ULID 一样按时间排序，
+// 但使用标准的 UUID 格式，可以直接存入数据库的 uuid 列
+type uuidV7Generator struct {
+	clock Clock
+	rand  io.Reader
+}
+
+// NewUUIDv7Generator 创建 UUID v7 生成器，clock 为 nil 时使用全局时钟，rand 为 nil 时使用 crypto/rand
+func NewUUIDv7Generator(clock Clock, rand io.Reader) IDGenerator {
+	return &uuidV7Generator{clock: clock, rand: rand}
+}
+
+func (g *uuidV7Generator) NewID() string {
+	var t time.Time
+	if g.clock != nil {
+		t = g.clock.Now()
+	} else {
+		t = now()
+	}
+	var b [16]byte
+	ms := uint64(t.UnixMilli())
+	b[0], b[1], b[2], b[3], b[4], b[5] = byte(ms>>40), byte(ms>>32), byte(ms>>24), byte(ms>>16), byte(ms>>8), byte(ms)
+	readRandom(g.rand, b[6:])
+	b[6] = b[6]&0x0f | 0x70 // version 7
+	b[8] = b[8]&0x3f | 0x80 // RFC 9562 variant
+	return formatUUID(b)
+}
+
+func formatUUID(b [16]byte) string {
+	var out [36]byte
+	hex.Encode(out[0:8], b[0:4])
+	out[8] = '-'
+	hex.Encode(out[9:13], b[4:6])
+	out[13] = '-'
+	hex.Encode(out[14:18], b[6:8])
+	out[18] = '-'
+	hex.Encode(out[19:23], b[8:10])
+	out[23] = '-'
+	hex.Encode(out[24:], b[10:])
+	return string(out[:])
+}
+
+// readRandom 从 r 读取随机数，r 为 nil 时使用 crypto/rand。读取失败说明系统随机数源不可用，直接 panic
+func readRandom(r io.Reader, b []byte) {
+	if r == nil {
+		r = rand.Reader
+	}
+	if _, err := io.ReadFull(r, b); err != nil {
+		panic("slogx: failed to read random bytes: " + err.Error())
+	}
+}
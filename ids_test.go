@@ -0,0 +1,60 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestULIDGenerator(t *testing.T) {
+	fixed := ClockFunc(func() time.Time { return time.UnixMilli(1714521600000) })
+	zeros := bytes.NewReader(make([]byte, 10))
+	id := NewULIDGenerator(fixed, zeros).NewID()
+	if id != "01HWRQ6W000000000000000000" {
+		t.Errorf("Unexpected deterministic ULID: %s", id)
+	}
+
+	g := NewULIDGenerator(nil, nil)
+	a, b := g.NewID(), g.NewID()
+	if len(a) != 26 || a == b {
+		t.Errorf("Expected unique 26-char ULIDs, got %s %s", a, b)
+	}
+}
+
+func TestUUIDGenerator(t *testing.T) {
+	id := NewUUIDGenerator(nil).NewID()
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(id) {
+		t.Errorf("Invalid UUID v4: %s", id)
+	}
+}
+
+func TestUUIDv7Generator(t *testing.T) {
+	fixed := ClockFunc(func() time.Time { return time.UnixMilli(1714521600000) })
+	id := NewUUIDv7Generator(fixed, bytes.NewReader(make([]byte, 10))).NewID()
+	if id != "018f3173-7000-7000-8000-000000000000" {
+		t.Errorf("Unexpected deterministic UUID v7: %s", id)
+	}
+	id = NewUUIDv7Generator(nil, nil).NewID()
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(id) {
+		t.Errorf("Invalid UUID v7: %s", id)
+	}
+}
+
+func TestInjectedClockAndIDGenerator(t *testing.T) {
+	SetClock(ClockFunc(func() time.Time { return time.Date(2024, 5, 1, 8, 0, 0, 0, time.Local) }))
+	SetIDGenerator(IDGeneratorFunc(func() string { return "id-1" }))
+	defer SetClock(nil)
+	defer SetIDGenerator(nil)
+
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: slog.LevelDebug, Writer: &buf, RecordIDKey: "record_id"})
+	logger.Info("deterministic")
+
+	output := buf.String()
+	if !strings.Contains(output, `time="2024-05-01 08:00:00.000000"`) || !strings.Contains(output, "record_id=id-1") {
+		t.Errorf("Expected injected clock and id generator to be used, got: %s", output)
+	}
+}
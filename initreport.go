@@ -0,0 +1,53 @@
+package log
+
+import (
+	"log/slog"
+	"os"
+	"sort"
+)
+
+// InitReport 描述 Logger 的初始化结果，Config.ReportInit 为 true 时以 init 分组输出为一条记录，
+// 代替零散的 stderr 提示，便于机器解析
+type InitReport struct {
+	Outputs   []string          // 打开的输出，例如 file:logs/app.log、stdout、writer、sink:otlp
+	Fallbacks []string          // 初始化中采取的回退措施，例如未知格式改用 text、Sink 创建失败被跳过
+	Sources   map[string]string // 配置项的来源：env 表示来自环境变量，default 表示使用默认值，只有默认 logger 填写
+}
+
+// LogValue 将报告输出为 outputs、fallbacks、sources 三个属性
+func (r InitReport) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.Any("outputs", r.Outputs),
+		slog.Any("fallbacks", r.Fallbacks),
+	}
+	if len(r.Sources) > 0 {
+		keys := make([]string, 0, len(r.Sources))
+		for k := range r.Sources {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		sources := make([]any, 0, 2*len(keys))
+		for _, k := range keys {
+			sources = append(sources, k, r.Sources[k])
+		}
+		attrs = append(attrs, slog.Group("sources", sources...))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// InitReport 返回 Logger 的初始化报告
+func (l *Logger) InitReport() InitReport {
+	return l.report
+}
+
+// envSources 返回环境变量配置项的来源，已设置的为 env，否则为 default
+func envSources(keys ...string) map[string]string {
+	sources := make(map[string]string, len(keys))
+	for _, k := range keys {
+		sources[k] = "default"
+		if os.Getenv(k) != "" {
+			sources[k] = "env"
+		}
+	}
+	return sources
+}
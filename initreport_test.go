@@ -0,0 +1,44 @@
+package log
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInitReport(t *testing.T) {
+	var buf syncBuffer
+	logger := NewLogger(Config{
+		Format:     "no-such-format",
+		Writer:     &buf,
+		Sinks:      []SinkConfig{{Type: "no-such-sink"}},
+		ReportInit: true,
+		Quiet:      true,
+	})
+	report := logger.InitReport()
+	if len(report.Outputs) != 1 || report.Outputs[0] != "writer" {
+		t.Errorf("Outputs = %v, want [writer]", report.Outputs)
+	}
+	if len(report.Fallbacks) != 2 {
+		t.Errorf("Fallbacks = %q, want sink and format fallbacks", report.Fallbacks)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, `msg="slogx initialized"`) || !strings.Contains(out, "init.outputs=[writer]") {
+		t.Errorf("init record = %s", out)
+	}
+	if !strings.Contains(out, `unknown format \"no-such-format\", using text`) {
+		t.Errorf("init record missing fallback: %s", out)
+	}
+	if strings.Contains(out, "source=") {
+		t.Errorf("init record has an empty caller: %s", out)
+	}
+}
+
+func TestEnvSources(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "0")
+	t.Setenv("LOG_MAX_AGE", "")
+	got := envSources("LOG_LEVEL", "LOG_MAX_AGE")
+	if got["LOG_LEVEL"] != "env" || got["LOG_MAX_AGE"] != "default" {
+		t.Errorf("envSources = %v", got)
+	}
+}
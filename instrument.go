@@ -0,0 +1,164 @@
+package log
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// 流量统计记录的消息
+const (
+	StreamMessage         = "stream closed"
+	StreamProgressMessage = "stream progress"
+)
+
+// DefaultStreamProgressInterval 是长时间传输时输出进度记录的间隔
+const DefaultStreamProgressInterval = 10 * time.Second
+
+// streamMeter 统计一个流的传输量，在 Close 时输出汇总，传输时间较长时每隔一段时间输出一次进度。
+// 进度在 Read/Write 中检查，不启动额外的 goroutine。
+type streamMeter struct {
+	logger    *Logger
+	name      string
+	direction string // read 或 write
+
+	mu       sync.Mutex
+	start    time.Time
+	last     time.Time // 上次输出进度的时间
+	interval time.Duration
+	bytes    int64
+	errors   int
+	err      error // 最近一次错误，读取到 io.EOF 不算错误
+	closed   bool
+}
+
+func (l *Logger) newStreamMeter(name, direction string) *streamMeter {
+	t := now()
+	return &streamMeter{logger: l, name: name, direction: direction, start: t, last: t, interval: DefaultStreamProgressInterval}
+}
+
+// add 记录一次读写的结果，需要时输出进度
+func (m *streamMeter) add(n int, err error) {
+	m.mu.Lock()
+	m.bytes += int64(n)
+	if err != nil && err != io.EOF {
+		m.errors++
+		m.err = err
+	}
+	t := now()
+	progress := m.interval > 0 && t.Sub(m.last) >= m.interval
+	var attrs []any
+	if progress {
+		m.last = t
+		attrs = m.attrs(t)
+	}
+	m.mu.Unlock()
+	if progress {
+		m.logger.logAt(context.Background(), slog.LevelDebug, StreamProgressMessage, callerPC(0), attrs)
+	}
+}
+
+// close 输出汇总，只输出一次；有错误时使用 Warn 级别
+func (m *streamMeter) close(pc uintptr) {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	attrs := m.attrs(now())
+	level := slog.LevelInfo
+	if m.err != nil {
+		level = slog.LevelWarn
+		attrs = append(attrs, "error", m.err)
+	}
+	m.mu.Unlock()
+	m.logger.logAt(context.Background(), level, StreamMessage, pc, attrs)
+}
+
+func (m *streamMeter) attrs(t time.Time) []any {
+	elapsed := t.Sub(m.start)
+	var throughput float64
+	if elapsed > 0 {
+		throughput = float64(m.bytes) / elapsed.Seconds()
+	}
+	return []any{slog.Group("stream",
+		"name", m.name,
+		"direction", m.direction,
+		"bytes", m.bytes,
+		"duration", elapsed,
+		"bytes_per_sec", int64(throughput),
+		"errors", m.errors,
+	)}
+}
+
+// InstrumentedReader 统计读取的字节数、吞吐量和错误，Close 时输出一条汇总记录
+type InstrumentedReader struct {
+	r     io.Reader
+	meter *streamMeter
+}
+
+// InstrumentReader 包装 r，Close 时用默认 logger 输出读取的字节数、耗时、吞吐量和错误，
+// 传输时间较长时每隔 DefaultStreamProgressInterval 输出一条 Debug 级别的进度。r 实现了 io.Closer 时一并关闭。
+//
+//	body := log.InstrumentReader(resp.Body, "download "+url)
+//	defer body.Close()
+func InstrumentReader(r io.Reader, name string) *InstrumentedReader {
+	return defaultLogger.InstrumentReader(r, name)
+}
+
+// InstrumentReader 与包级别的 InstrumentReader 相同，使用 l 输出
+func (l *Logger) InstrumentReader(r io.Reader, name string) *InstrumentedReader {
+	return &InstrumentedReader{r: r, meter: l.newStreamMeter(name, "read")}
+}
+
+func (r *InstrumentedReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.meter.add(n, err)
+	return n, err
+}
+
+// Close 输出汇总并关闭底层的 Reader，重复调用只输出一次
+func (r *InstrumentedReader) Close() error {
+	r.meter.close(callerPC(0))
+	if c, ok := r.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// InstrumentedWriter 统计写入的字节数、吞吐量和错误，Close 时输出一条汇总记录
+type InstrumentedWriter struct {
+	w     io.Writer
+	meter *streamMeter
+}
+
+// InstrumentWriter 包装 w，行为与 InstrumentReader 相同。w 实现了 io.Closer 时 Close 一并关闭，关闭的错误也计入汇总。
+func InstrumentWriter(w io.Writer, name string) *InstrumentedWriter {
+	return defaultLogger.InstrumentWriter(w, name)
+}
+
+// InstrumentWriter 与包级别的 InstrumentWriter 相同，使用 l 输出
+func (l *Logger) InstrumentWriter(w io.Writer, name string) *InstrumentedWriter {
+	return &InstrumentedWriter{w: w, meter: l.newStreamMeter(name, "write")}
+}
+
+func (w *InstrumentedWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.meter.add(n, err)
+	return n, err
+}
+
+// Close 关闭底层的 Writer 并输出汇总，重复调用只输出一次
+func (w *InstrumentedWriter) Close() error {
+	var err error
+	if c, ok := w.w.(io.Closer); ok {
+		if err = c.Close(); err != nil {
+			w.meter.add(0, err)
+		}
+	}
+	w.meter.close(callerPC(0))
+	return err
+}
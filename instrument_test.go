@@ -0,0 +1,71 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+type failAfterWriter struct{ n int }
+
+func (w *failAfterWriter) Write(p []byte) (int, error) {
+	if w.n <= 0 {
+		return 0, errors.New("connection reset")
+	}
+	w.n--
+	return len(p), nil
+}
+
+func TestInstrumentReader(t *testing.T) {
+	current := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	SetClock(ClockFunc(func() time.Time { return current }))
+	defer SetClock(nil)
+
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Writer: &buf, Level: slog.LevelDebug})
+	r := logger.InstrumentReader(strings.NewReader(strings.Repeat("x", 4096)), "download")
+	p := make([]byte, 1024)
+	for {
+		_, err := r.Read(p)
+		if err == io.EOF {
+			break
+		}
+		current = current.Add(5 * time.Second)
+	}
+	r.Close()
+	r.Close()
+
+	output := buf.String()
+	if n := strings.Count(output, "msg=\""+StreamProgressMessage+"\""); n != 2 {
+		t.Errorf("got %d progress records, want 2: %s", n, output)
+	}
+	if n := strings.Count(output, "msg=\""+StreamMessage+"\""); n != 1 {
+		t.Fatalf("got %d summary records, want 1: %s", n, output)
+	}
+	for _, want := range []string{"level=INFO", "stream.name=download", "stream.direction=read", "stream.bytes=4096", "stream.duration=20s", "stream.bytes_per_sec=204 stream.errors=0"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("missing %q in %s", want, output)
+		}
+	}
+}
+
+func TestInstrumentWriterError(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Writer: &buf})
+	w := logger.InstrumentWriter(&failAfterWriter{n: 2}, "upload")
+	for i := 0; i < 3; i++ {
+		w.Write([]byte("chunk"))
+	}
+	w.Close()
+
+	output := buf.String()
+	for _, want := range []string{"level=WARN", "stream.direction=write", "stream.bytes=10", "stream.errors=1", `error="connection reset"`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("missing %q in %s", want, output)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package log
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// 以下是自定义 JSON 类格式（ecs、clef 等）共用的编码函数，值的表示与 slog.JSONHandler 保持一致
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString 输出带引号的 JSON 字符串，不转义 HTML 字符
+func appendJSONString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			buf.WriteString(s[start:i])
+			switch c {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(c)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[c>>4])
+				buf.WriteByte(hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`\ufffd`)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf.WriteString(s[start:])
+	buf.WriteByte('"')
+}
+
+// appendJSONValue 输出属性值，分组输出为对象
+func appendJSONValue(buf *bytes.Buffer, v slog.Value) {
+	switch v.Kind() {
+	case slog.KindString:
+		appendJSONString(buf, v.String())
+	case slog.KindInt64:
+		buf.WriteString(strconv.FormatInt(v.Int64(), 10))
+	case slog.KindUint64:
+		buf.WriteString(strconv.FormatUint(v.Uint64(), 10))
+	case slog.KindFloat64:
+		f := v.Float64()
+		// JSON 不支持 NaN 和 Inf，与 slog 一样以字符串输出
+		if b, err := json.Marshal(f); err == nil {
+			buf.Write(b)
+		} else {
+			appendJSONString(buf, strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	case slog.KindBool:
+		buf.WriteString(strconv.FormatBool(v.Bool()))
+	case slog.KindDuration:
+		buf.WriteString(strconv.FormatInt(int64(v.Duration()), 10))
+	case slog.KindTime:
+		appendJSONString(buf, v.Time().Format(time.RFC3339Nano))
+	case slog.KindGroup:
+		buf.WriteByte('{')
+		appendJSONAttrs(buf, v.Group(), false)
+		buf.WriteByte('}')
+	default:
+		appendJSONAny(buf, v.Any())
+	}
+}
+
+func appendJSONAny(buf *bytes.Buffer, a any) {
+	switch x := a.(type) {
+	case json.Marshaler:
+		if b, err := x.MarshalJSON(); err == nil {
+			buf.Write(b)
+			return
+		}
+	case error:
+		appendJSONString(buf, x.Error())
+		return
+	case encoding.TextMarshaler:
+		if b, err := x.MarshalText(); err == nil {
+			appendJSONString(buf, string(b))
+			return
+		}
+	}
+	if b, err := json.Marshal(a); err == nil {
+		buf.Write(b)
+		return
+	}
+	appendJSONString(buf, fmt.Sprintf("%+v", a))
+}
+
+// appendJSONAttrs 输出对象内的 "key":value 列表，leadingComma 表示前面已有字段
+func appendJSONAttrs(buf *bytes.Buffer, attrs []slog.Attr, leadingComma bool) {
+	for i, a := range attrs {
+		if i > 0 || leadingComma {
+			buf.WriteByte(',')
+		}
+		appendJSONString(buf, a.Key)
+		buf.WriteByte(':')
+		appendJSONValue(buf, a.Value)
+	}
+}
@@ -0,0 +1,116 @@
+package log
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// latencyBounds 是延迟直方图的桶上界，从 1µs 开始每个桶翻倍，最后一个桶收纳超过 ~1s 的记录
+var latencyBounds = func() []time.Duration {
+	bounds := make([]time.Duration, 21)
+	for i := range bounds {
+		bounds[i] = time.Microsecond << i
+	}
+	return bounds
+}()
+
+// latencyHistogram 统计日志管道自身的延迟：从调用日志方法到所有输出（包括 Sink）写完的时间
+type latencyHistogram struct {
+	rate    uint64
+	counter atomic.Uint64
+	count   atomic.Uint64
+	sum     atomic.Int64
+	max     atomic.Int64
+	buckets []atomic.Uint64 // len(latencyBounds)+1，最后一个桶为 +Inf
+}
+
+func newLatencyHistogram(rate int) *latencyHistogram {
+	return &latencyHistogram{rate: uint64(rate), buckets: make([]atomic.Uint64, len(latencyBounds)+1)}
+}
+
+// sample 判断本条记录是否需要计时
+func (h *latencyHistogram) sample() bool {
+	return h != nil && h.counter.Add(1)%h.rate == 0
+}
+
+func (h *latencyHistogram) observe(d time.Duration) {
+	h.count.Add(1)
+	h.sum.Add(int64(d))
+	for {
+		max := h.max.Load()
+		if int64(d) <= max || h.max.CompareAndSwap(max, int64(d)) {
+			break
+		}
+	}
+	i := 0
+	for i < len(latencyBounds) && d > latencyBounds[i] {
+		i++
+	}
+	h.buckets[i].Add(1)
+}
+
+// LatencyBucket 是直方图的一个桶，Count 为延迟不超过 UpperBound 的记录数（累计值，与 Prometheus 一致），
+// 最后一个桶的 UpperBound 为 0，表示 +Inf
+type LatencyBucket struct {
+	UpperBound time.Duration
+	Count      uint64
+}
+
+// LatencyStats 是日志管道延迟的统计快照
+type LatencyStats struct {
+	Count   uint64        // 计时的记录数
+	Sum     time.Duration // 延迟总和
+	Max     time.Duration // 最大延迟
+	Buckets []LatencyBucket
+}
+
+// Mean 返回平均延迟
+func (s LatencyStats) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Sum / time.Duration(s.Count)
+}
+
+// Quantile 按桶估算分位数（返回所在桶的上界），q 取值 0~1，例如 0.99
+func (s LatencyStats) Quantile(q float64) time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	rank := uint64(q * float64(s.Count))
+	if rank == 0 {
+		rank = 1
+	}
+	for _, b := range s.Buckets {
+		if b.Count >= rank {
+			if b.UpperBound == 0 {
+				return s.Max
+			}
+			return b.UpperBound
+		}
+	}
+	return s.Max
+}
+
+// PipelineLatency 返回日志管道延迟的统计快照，未设置 Config.LatencySampleRate 时返回零值
+func (l *Logger) PipelineLatency() LatencyStats {
+	h := l.latency
+	if h == nil {
+		return LatencyStats{}
+	}
+	s := LatencyStats{
+		Count:   h.count.Load(),
+		Sum:     time.Duration(h.sum.Load()),
+		Max:     time.Duration(h.max.Load()),
+		Buckets: make([]LatencyBucket, len(h.buckets)),
+	}
+	var cumulative uint64
+	for i := range h.buckets {
+		cumulative += h.buckets[i].Load()
+		s.Buckets[i].Count = cumulative
+		if i < len(latencyBounds) {
+			s.Buckets[i].UpperBound = latencyBounds[i]
+		}
+	}
+	return s
+}
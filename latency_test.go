@@ -0,0 +1,40 @@
+package log
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// slowWriter 模拟写入较慢的输出
+type slowWriter struct{ delay time.Duration }
+
+func (w slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	return len(p), nil
+}
+
+func TestPipelineLatency(t *testing.T) {
+	logger := NewLogger(Config{Level: slog.LevelDebug, Writer: slowWriter{2 * time.Millisecond}, LatencySampleRate: 2})
+	for i := 0; i < 10; i++ {
+		logger.Info("hello")
+	}
+
+	s := logger.PipelineLatency()
+	if s.Count != 5 {
+		t.Fatalf("Expected 5 sampled records, got %d", s.Count)
+	}
+	if s.Mean() < 2*time.Millisecond || s.Max < 2*time.Millisecond {
+		t.Errorf("Expected latency to include the slow write, got mean=%v max=%v", s.Mean(), s.Max)
+	}
+	if last := s.Buckets[len(s.Buckets)-1]; last.UpperBound != 0 || last.Count != s.Count {
+		t.Errorf("Expected cumulative +Inf bucket with all records, got %+v", last)
+	}
+	if p99 := s.Quantile(0.99); p99 < 2*time.Millisecond {
+		t.Errorf("Expected p99 >= 2ms, got %v", p99)
+	}
+
+	if s := NewLogger(Config{Writer: slowWriter{}}).PipelineLatency(); s.Count != 0 {
+		t.Errorf("Expected no stats when disabled, got %+v", s)
+	}
+}
@@ -0,0 +1,82 @@
+package log
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// leakWait 是 VerifyNoLeaks 等待 goroutine 退出的最长时间
+var leakWait = 2 * time.Second
+
+// pkgPath 是本包的导入路径，用于识别本包启动的 goroutine
+var pkgPath = reflect.TypeOf(Logger{}).PkgPath()
+
+// VerifyNoLeaks 记录调用时已存在的 goroutine，在测试结束时检查本包启动的 goroutine
+// （信号监听、缓冲刷新、遥测、OTLP 发送、投递确认等）是否都已退出，未退出时测试失败。
+// 应在创建 Logger 之前调用，并确保测试中创建的 Logger 都已 Close：
+//
+//	func TestSomething(t *testing.T) {
+//		log.VerifyNoLeaks(t)
+//		logger := log.NewLogger(cfg)
+//		defer logger.Close()
+//		...
+//	}
+//
+// 默认 logger 在包初始化时创建且不会关闭，它的 goroutine 在调用前已存在，不会被报告。
+func VerifyNoLeaks(t testing.TB) {
+	t.Helper()
+	before := make(map[string]bool)
+	for _, g := range packageGoroutines() {
+		before[g.id] = true
+	}
+	t.Cleanup(func() {
+		deadline := time.Now().Add(leakWait)
+		for {
+			var leaked []string
+			for _, g := range packageGoroutines() {
+				if !before[g.id] {
+					leaked = append(leaked, g.stack)
+				}
+			}
+			if len(leaked) == 0 {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Errorf("slogx: %d goroutine(s) outlived the test:\n\n%s", len(leaked), strings.Join(leaked, "\n\n"))
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+}
+
+type goroutine struct {
+	id    string
+	stack string
+}
+
+// packageGoroutines 返回调用栈中包含本包函数的 goroutine，不包括当前 goroutine
+func packageGoroutines() []goroutine {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	var gs []goroutine
+	// 第一个是当前 goroutine
+	for _, stack := range strings.Split(string(buf), "\n\n")[1:] {
+		header, _, _ := strings.Cut(stack, "\n")
+		id, _, _ := strings.Cut(strings.TrimPrefix(header, "goroutine "), " ")
+		if strings.Contains(stack, "\n"+pkgPath+".") {
+			gs = append(gs, goroutine{id: id, stack: stack})
+		}
+	}
+	return gs
+}
@@ -0,0 +1,51 @@
+package log
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// recordingTB 收集 Cleanup 和 Errorf，用于检查 VerifyNoLeaks 的结果
+type recordingTB struct {
+	testing.TB
+	cleanups []func()
+	errors   []string
+}
+
+func (tb *recordingTB) Helper()          {}
+func (tb *recordingTB) Cleanup(f func()) { tb.cleanups = append(tb.cleanups, f) }
+func (tb *recordingTB) Errorf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func (tb *recordingTB) finish() {
+	for i := len(tb.cleanups) - 1; i >= 0; i-- {
+		tb.cleanups[i]()
+	}
+}
+
+func TestVerifyNoLeaksAfterClose(t *testing.T) {
+	VerifyNoLeaks(t)
+	logger := NewLogger(Config{
+		Filename:          filepath.Join(t.TempDir(), "app.log"),
+		BufferSize:        1024,
+		TelemetryInterval: time.Millisecond,
+	})
+	logger.Info("hello")
+	logger.Close()
+}
+
+func TestVerifyNoLeaksReportsOpenLogger(t *testing.T) {
+	defer func(d time.Duration) { leakWait = d }(leakWait)
+	leakWait = 50 * time.Millisecond
+	tb := &recordingTB{TB: t}
+	VerifyNoLeaks(tb)
+	logger := NewLogger(Config{Filename: filepath.Join(t.TempDir(), "app.log"), BufferSize: 1024})
+	defer logger.Close()
+	tb.finish()
+	if len(tb.errors) != 1 {
+		t.Errorf("VerifyNoLeaks reported %d errors for an unclosed logger, want 1", len(tb.errors))
+	}
+}
@@ -0,0 +1,261 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// LevelTrace 是比 Debug 更详细的级别，用于逐条请求、逐个循环这类默认不需要的诊断信息
+	LevelTrace = slog.LevelDebug - 4
+	// LevelNotice 介于 Info 和 Warn 之间，对应 syslog 的 notice：正常但值得注意的事件
+	LevelNotice = slog.LevelInfo + 2
+	// LevelCritical 介于 Error 和 Fatal 之间，对应 syslog 的 crit：需要立即处理的严重错误
+	LevelCritical = slog.LevelError + 2
+	// LevelPanic 是 Panic 输出的级别，记录后触发 panic
+	LevelPanic = slog.LevelError + 4
+	// LevelFatal 是 Fatal 输出的级别，记录后退出程序
+	LevelFatal = slog.LevelError + 8
+)
+
+// levelString 返回级别名称，与 slog.Level.String 相同，只是 Debug 以下的级别以 TRACE 为基准，
+// Notice、Critical 及 Error 之上的级别以 NOTICE、CRITICAL、PANIC、FATAL 为基准，例如 TRACE+2、NOTICE+1、FATAL
+func levelString(l slog.Level) string {
+	base, name := LevelTrace, "TRACE"
+	switch {
+	case l >= LevelFatal:
+		base, name = LevelFatal, "FATAL"
+	case l >= LevelPanic:
+		base, name = LevelPanic, "PANIC"
+	case l >= LevelCritical:
+		base, name = LevelCritical, "CRITICAL"
+	case l >= slog.LevelWarn:
+		return l.String()
+	case l >= LevelNotice:
+		base, name = LevelNotice, "NOTICE"
+	case l >= slog.LevelDebug:
+		return l.String()
+	}
+	if l == base {
+		return name
+	}
+	return fmt.Sprintf("%s%+d", name, int(l-base))
+}
+
+// customLevel 判断级别是否使用 slog 没有内置的名称
+func customLevel(l slog.Level) bool {
+	return l < slog.LevelDebug || (l >= LevelNotice && l < slog.LevelWarn) || l >= LevelCritical
+}
+
+// ParseLevel 解析级别名称，不区分大小写，支持 TRACE、DEBUG、INFO、NOTICE、WARN（WARNING）、ERROR、CRITICAL（CRIT）、
+// PANIC、FATAL、带偏移量的写法（例如 DEBUG+2、TRACE-1）和整数
+func ParseLevel(s string) (slog.Level, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if n, err := strconv.Atoi(s); err == nil {
+		return slog.Level(n), nil
+	}
+	switch s {
+	case "WARNING":
+		return slog.LevelWarn, nil
+	case "CRIT":
+		return LevelCritical, nil
+	}
+	for name, base := range map[string]slog.Level{
+		"TRACE": LevelTrace, "NOTICE": LevelNotice, "CRITICAL": LevelCritical, "PANIC": LevelPanic, "FATAL": LevelFatal,
+	} {
+		rest, ok := strings.CutPrefix(s, name)
+		if !ok {
+			continue
+		}
+		if rest == "" {
+			return base, nil
+		}
+		if n, err := strconv.Atoi(rest); err == nil && (rest[0] == '+' || rest[0] == '-') {
+			return base + slog.Level(n), nil
+		}
+		return 0, fmt.Errorf("slogx: invalid level %q", s)
+	}
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("slogx: invalid level %q", s)
+	}
+	return l, nil
+}
+
+// DefaultLevelSignals 返回 Config.LevelSignals 为 nil 时使用的映射：SIGHUP 切换到 Debug，SIGUSR1 切换到 Info，
+// SIGUSR2 切换到 Warn。SIGHUP 通常表示重新打开文件，可以复制后删除它再设置到 Config.LevelSignals：
+//
+//	signals := log.DefaultLevelSignals()
+//	delete(signals, syscall.SIGHUP)
+//	logger := log.NewLogger(log.Config{LevelSignals: signals, ReopenSignal: syscall.SIGHUP})
+func DefaultLevelSignals() map[os.Signal]slog.Level {
+	return map[os.Signal]slog.Level{
+		syscall.SIGHUP:  slog.LevelDebug,
+		syscall.SIGUSR1: slog.LevelInfo,
+		syscall.SIGUSR2: slog.LevelWarn,
+	}
+}
+
+// Trace 输出 Trace 级别的日志
+func (l *Logger) Trace(msg string, args ...any) {
+	l.log(context.Background(), LevelTrace, msg, args...)
+}
+
+// Tracef 按 fmt.Sprintf 格式化消息后输出 Trace 级别的日志，级别未开启时不会格式化
+func (l *Logger) Tracef(format string, args ...any) {
+	if !l.Enabled(context.Background(), LevelTrace) {
+		return
+	}
+	l.logAt(context.Background(), LevelTrace, fmt.Sprintf(format, args...), l.pc(), nil)
+}
+
+// Trace 使用默认 Logger 输出 Trace 级别的日志
+func Trace(msg string, args ...any) {
+	defaultLogger.Trace(msg, args...)
+}
+
+// Tracef 使用默认 Logger 输出格式化的 Trace 级别日志
+func Tracef(format string, args ...any) {
+	l := defaultLogger
+	if !l.Enabled(context.Background(), LevelTrace) {
+		return
+	}
+	l.logAt(context.Background(), LevelTrace, fmt.Sprintf(format, args...), l.pc(), nil)
+}
+
+// Notice 输出 Notice 级别的日志
+func (l *Logger) Notice(msg string, args ...any) {
+	l.log(context.Background(), LevelNotice, msg, args...)
+}
+
+// Critical 输出 Critical 级别的日志，与 Fatal 不同，不会退出程序
+func (l *Logger) Critical(msg string, args ...any) {
+	l.log(context.Background(), LevelCritical, msg, args...)
+}
+
+// Notice 使用默认 Logger 输出 Notice 级别的日志
+func Notice(msg string, args ...any) {
+	defaultLogger.Notice(msg, args...)
+}
+
+// Critical 使用默认 Logger 输出 Critical 级别的日志
+func Critical(msg string, args ...any) {
+	defaultLogger.Critical(msg, args...)
+}
+
+// SetLevel 在运行时修改 Logger 的级别。命名 logger 上调用时等同于 SetNamedLevel(l.Name(), level)，
+// 否则修改 Logger 及其 With 派生出的所有 logger 共享的级别
+func (l *Logger) SetLevel(level slog.Level) {
+	if l.name != "" {
+		l.named.set(l.name, level)
+		return
+	}
+	l.level.Set(level)
+}
+
+// Level 返回 Logger 当前生效的级别，命名 logger 没有单独设置时返回上一级名称或根 Logger 的级别
+func (l *Logger) Level() slog.Level {
+	if l.name != "" {
+		if level, ok := l.named.level(l.name); ok {
+			return level
+		}
+	}
+	return l.level.Level()
+}
+
+// GetLevel 返回名为 name 的 logger 当前生效的级别，name 为空时返回默认 Logger 的级别
+func GetLevel(name string) slog.Level {
+	if name != "" {
+		if level, ok := defaultLogger.named.level(name); ok {
+			return level
+		}
+	}
+	return defaultLogger.level.Level()
+}
+
+// tempLevels 保存 SetLevelFor 设置的临时级别，key 为根 Logger 的 *slog.LevelVar 或命名 logger 的 namedKey
+var tempLevels struct {
+	mu     sync.Mutex
+	active map[any]*tempLevel
+}
+
+// namedKey 是命名 logger 在 tempLevels 中的 key，不同 Logger 下的同名 logger 互不影响
+type namedKey struct {
+	levels *namedLevels
+	name   string
+}
+
+// tempLevel 是一个未到期的临时级别
+type tempLevel struct {
+	timer *time.Timer
+	level slog.Level // 临时级别
+	prev  slog.Level // 到期后恢复的级别
+	reset bool       // 命名 logger 原来没有单独设置级别，到期后调用 ResetLevel
+}
+
+// SetLevelFor 在 d 时间内将默认 Logger 的级别设为 level，到期后自动恢复，见 Logger.SetLevelFor
+func SetLevelFor(level slog.Level, d time.Duration) {
+	defaultLogger.SetLevelFor(level, d)
+}
+
+// SetLevelFor 临时修改 Logger 的级别，d 之后自动恢复为原来的级别，避免线上打开 Debug 后忘记关闭：
+//
+//	logger.SetLevelFor(slog.LevelDebug, 10*time.Minute)
+//
+// 到期前再次调用时重新计时，到期后仍恢复为第一次调用之前的级别；期间用 SetLevel 改成了其他级别时到期不再恢复。
+// d <= 0 时等同于 SetLevel，并取消未到期的临时级别。
+func (l *Logger) SetLevelFor(level slog.Level, d time.Duration) {
+	var key any = l.level
+	if l.name != "" {
+		key = namedKey{l.named, l.name}
+	}
+	tempLevels.mu.Lock()
+	defer tempLevels.mu.Unlock()
+	t, ok := tempLevels.active[key]
+	if ok {
+		t.timer.Stop()
+		delete(tempLevels.active, key)
+	}
+	if d <= 0 {
+		l.SetLevel(level)
+		return
+	}
+	if !ok {
+		t = &tempLevel{prev: l.Level()}
+		if l.name != "" {
+			t.reset = !l.named.has(l.name)
+		}
+	}
+	t.level = level
+	t.timer = time.AfterFunc(d, func() { l.restoreLevel(key, t) })
+	if tempLevels.active == nil {
+		tempLevels.active = make(map[any]*tempLevel)
+	}
+	tempLevels.active[key] = t
+	l.SetLevel(level)
+}
+
+// restoreLevel 在临时级别到期时恢复原来的级别
+func (l *Logger) restoreLevel(key any, t *tempLevel) {
+	tempLevels.mu.Lock()
+	defer tempLevels.mu.Unlock()
+	if tempLevels.active[key] != t {
+		return // 已被新的 SetLevelFor 取代
+	}
+	delete(tempLevels.active, key)
+	if l.Level() != t.level {
+		return // 期间被 SetLevel 改过，保留手动设置的级别
+	}
+	if t.reset {
+		l.named.reset(l.name)
+		return
+	}
+	l.SetLevel(t.prev)
+}
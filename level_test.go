@@ -0,0 +1,197 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]slog.Level{
+		"trace":      LevelTrace,
+		"TRACE+1":    LevelTrace + 1,
+		"Debug":      slog.LevelDebug,
+		"info+2":     slog.LevelInfo + 2,
+		"warning":    slog.LevelWarn,
+		" ERROR ":    slog.LevelError,
+		"-8":         LevelTrace,
+		"notice":     LevelNotice,
+		"crit":       LevelCritical,
+		"CRITICAL-1": LevelCritical - 1,
+	} {
+		if got, err := ParseLevel(in); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "verbose", "trace2", "TRACE+x"} {
+		if _, err := ParseLevel(in); err == nil {
+			t.Errorf("ParseLevel(%q) succeeded, want error", in)
+		}
+	}
+	for l, want := range map[slog.Level]string{
+		LevelTrace + 2:      "TRACE+2",
+		LevelNotice:         "NOTICE",
+		LevelNotice + 1:     "NOTICE+1",
+		slog.LevelInfo + 1:  "INFO+1",
+		slog.LevelError + 1: "ERROR+1",
+		LevelCritical:       "CRITICAL",
+		LevelPanic - 1:      "CRITICAL+1",
+	} {
+		if s := levelString(l); s != want {
+			t.Errorf("levelString(%d) = %q, want %q", int(l), s, want)
+		}
+	}
+}
+
+func TestNoticeAndCritical(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Writer: &buf, Level: LevelNotice})
+	logger.Info("info")
+	logger.Notice("disk usage high")
+	logger.Critical("database unreachable")
+	out := buf.String()
+	if strings.Contains(out, "msg=info") || !strings.Contains(out, "level=NOTICE msg=\"disk usage high\"") ||
+		!strings.Contains(out, "level=CRITICAL") {
+		t.Errorf("Unexpected output %q", out)
+	}
+	if syslogSeverity(LevelNotice) != 5 || syslogSeverity(LevelCritical) != 2 || syslogSeverity(slog.LevelError+1) != 3 {
+		t.Error("Expected NOTICE and CRITICAL to map onto syslog notice and crit")
+	}
+}
+
+func TestTrace(t *testing.T) {
+	for _, format := range []string{"text", "json", "logfmt"} {
+		var buf bytes.Buffer
+		logger := NewLogger(Config{Writer: &buf, Format: format, Level: LevelTrace})
+		logger.Trace("entering loop", "i", 1)
+		logger.Tracef("item %d of %d", 2, 3)
+		out := buf.String()
+		if strings.Count(out, "TRACE") != 2 || strings.Contains(out, "DEBUG-4") {
+			t.Errorf("%s: level not rendered as TRACE:\n%s", format, out)
+		}
+		if !strings.Contains(out, "item 2 of 3") {
+			t.Errorf("%s: Tracef message not formatted:\n%s", format, out)
+		}
+	}
+
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Writer: &buf, Level: slog.LevelDebug})
+	logger.Trace("hidden")
+	logger.Tracef("hidden %d", 1)
+	if buf.Len() != 0 {
+		t.Errorf("Trace output at Debug level: %s", buf.String())
+	}
+}
+
+func TestFatalAndPanicLevels(t *testing.T) {
+	var code int
+	exit = func(c int) { code = c }
+	defer func() { exit = os.Exit }()
+
+	sink := &memorySink{}
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Writer: &buf, Format: "json", Level: slog.LevelInfo})
+	logger.Logger = slog.New(newMultiHandler(logger.Handler(), newSinkHandler(sink, slog.LevelError)))
+
+	logger.Fatal("cannot start", "port", 80)
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != "bad state" {
+				t.Errorf("recovered %v, want the message", r)
+			}
+		}()
+		logger.Panic("bad state")
+	}()
+
+	if out := buf.String(); !strings.Contains(out, `"level":"FATAL"`) || !strings.Contains(out, `"level":"PANIC"`) {
+		t.Errorf("levels not rendered as FATAL/PANIC:\n%s", out)
+	}
+	if len(sink.records) != 2 || sink.records[0].Level != LevelFatal || sink.records[1].Level != LevelPanic {
+		t.Errorf("sink records = %v, want a Fatal and a Panic record", sink.records)
+	}
+	for in, want := range map[string]slog.Level{"fatal": LevelFatal, "PANIC": LevelPanic, "fatal-1": LevelFatal - 1} {
+		if got, err := ParseLevel(in); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Writer: &buf, Level: slog.LevelInfo})
+	child := logger.With("k", "v")
+	cache := logger.Named("testcache")
+
+	logger.SetLevel(slog.LevelWarn)
+	if logger.Level() != slog.LevelWarn || child.Level() != slog.LevelWarn {
+		t.Errorf("Level() = %v / %v, want WARN shared with With loggers", logger.Level(), child.Level())
+	}
+	child.Info("hidden")
+	cache.SetLevel(slog.LevelDebug)
+	cache.Debug("cache debug")
+	if cache.Level() != slog.LevelDebug || logger.Named("testcache").Level() != slog.LevelDebug || logger.Level() != slog.LevelWarn {
+		t.Errorf("named level = %v / %v, root = %v", cache.Level(), logger.Named("testcache").Level(), logger.Level())
+	}
+	if out := buf.String(); strings.Contains(out, "hidden") || !strings.Contains(out, "cache debug") {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestSetLevelFor(t *testing.T) {
+	logger := NewLogger(Config{Writer: &bytes.Buffer{}, Level: slog.LevelInfo})
+	waitLevel := func(l *Logger, want slog.Level) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for l.Level() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("Level = %v, want %v", l.Level(), want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	logger.SetLevelFor(slog.LevelDebug, 20*time.Millisecond)
+	logger.SetLevelFor(LevelTrace, 20*time.Millisecond)
+	if logger.Level() != LevelTrace {
+		t.Fatalf("Level = %v, want TRACE", logger.Level())
+	}
+	waitLevel(logger, slog.LevelInfo) // 恢复为第一次调用之前的级别
+
+	db := logger.Named("tmpdb")
+	db.SetLevelFor(slog.LevelDebug, 20*time.Millisecond)
+	waitLevel(db, slog.LevelInfo)
+	if _, ok := logger.named.level("tmpdb"); ok {
+		t.Error("Expected named level to be reset after expiry")
+	}
+
+	// 期间手动修改的级别不会被恢复
+	logger.SetLevelFor(slog.LevelDebug, 20*time.Millisecond)
+	logger.SetLevel(slog.LevelWarn)
+	time.Sleep(50 * time.Millisecond)
+	if logger.Level() != slog.LevelWarn {
+		t.Errorf("Level = %v, want manual WARN kept", logger.Level())
+	}
+}
+
+func TestLevelSignals(t *testing.T) {
+	logger := NewLogger(Config{Writer: &bytes.Buffer{}, LevelSignals: map[os.Signal]slog.Level{syscall.SIGWINCH: slog.LevelError}})
+	defer logger.Close()
+	deadline := time.Now().Add(time.Second)
+	for logger.Level() != slog.LevelError {
+		if time.Now().After(deadline) {
+			t.Fatal("SIGWINCH did not change the level to ERROR")
+		}
+		syscall.Kill(os.Getpid(), syscall.SIGWINCH)
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, ok := DefaultLevelSignals()[syscall.SIGHUP]; !ok {
+		t.Error("Expected SIGHUP in the default mapping")
+	}
+}
@@ -0,0 +1,113 @@
+package log
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLevelFileInterval 是读取级别文件的默认间隔
+const DefaultLevelFileInterval = 10 * time.Second
+
+// levelWatcher 定期读取级别文件，实现 io.Closer 以便随 Logger 一起关闭
+type levelWatcher struct {
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// Close 停止读取，并等待正在进行的读取完成
+func (w *levelWatcher) Close() error {
+	w.once.Do(func() { close(w.stop) })
+	<-w.done
+	return nil
+}
+
+// WatchLevelFile 每隔 interval 读取 path 中的级别，内容变化时调整 Logger 的级别，返回的函数用于停止读取。
+// Kubernetes 中容器的环境变量不能修改，但挂载的 ConfigMap 文件可以，把级别放在文件里即可不重启调整：
+//
+//	debug
+//
+// 或者使用环境变量文件的格式，其他变量被忽略：
+//
+//	LOG_LEVEL=debug
+//
+// 只有文件内容变化时才设置级别，期间通过信号或 SetLevel 做的调整不会在下一次读取时被覆盖。
+// 文件不存在时保持当前级别，内容无法解析时输出一条 Warn 记录。interval <= 0 时使用 DefaultLevelFileInterval。
+func (l *Logger) WatchLevelFile(path string, interval time.Duration) (stop func()) {
+	w := l.watchLevelFile(path, interval)
+	return func() { w.Close() }
+}
+
+func (l *Logger) watchLevelFile(path string, interval time.Duration) *levelWatcher {
+	if interval <= 0 {
+		interval = DefaultLevelFileInterval
+	}
+	w := &levelWatcher{stop: make(chan struct{}), done: make(chan struct{})}
+	var last string // 上一次读取到的级别文本
+	check := func() {
+		value, err := readLevelFile(path)
+		if err != nil || value == last {
+			return
+		}
+		last = value
+		if level, err := ParseLevel(value); err != nil {
+			l.Warn("Invalid level in level file", "file", path, "error", err)
+		} else if level != l.Level() {
+			l.SetLevel(level)
+			l.Warn("Log level changed to "+levelString(level), "file", path)
+		}
+	}
+	check() // 第一次同步读取，创建后的第一条记录就使用文件中的级别
+	go func() {
+		defer close(w.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				check()
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+	return w
+}
+
+// readLevelFile 返回级别文件中的级别文本：LOG_LEVEL=<level> 一行的值，或者第一个非空、非注释行
+func readLevelFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	var plain string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			if plain == "" {
+				plain = line
+			}
+			continue
+		}
+		if strings.TrimSpace(key) == "LOG_LEVEL" {
+			return strings.Trim(strings.TrimSpace(value), `"'`), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if plain == "" {
+		return "", errors.New("no level in " + path)
+	}
+	return plain, nil
+}
@@ -0,0 +1,38 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLevelFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "level")
+	if err := os.WriteFile(path, []byte("warn\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	logger := NewLogger(Config{Writer: &bytes.Buffer{}, LevelFile: path, LevelFileInterval: 10 * time.Millisecond})
+	defer logger.Close()
+	if logger.Level() != slog.LevelWarn {
+		t.Fatalf("Level = %v, want WARN from the file on creation", logger.Level())
+	}
+
+	os.WriteFile(path, []byte("# ConfigMap\nAPP_MODE=prod\nexport LOG_LEVEL=\"debug\"\n"), 0644)
+	deadline := time.Now().Add(time.Second)
+	for logger.Level() != slog.LevelDebug {
+		if time.Now().After(deadline) {
+			t.Fatalf("Level = %v, want DEBUG after the file changed", logger.Level())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// 文件没有变化时不覆盖手动设置的级别
+	logger.SetLevel(slog.LevelError)
+	time.Sleep(50 * time.Millisecond)
+	if logger.Level() != slog.LevelError {
+		t.Errorf("Level = %v, want manual ERROR kept", logger.Level())
+	}
+}
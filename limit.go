@@ -0,0 +1,78 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// DefaultSinkQueueTimeout 是 LimitedSink 达到并发上限时等待空位的默认时长
+const DefaultSinkQueueTimeout = time.Second
+
+// ErrSinkBusy 表示 LimitedSink 在等待时间内没有空位，记录被丢弃
+var ErrSinkBusy = errors.New("slogx: sink busy, too many writes in flight")
+
+// LimitedSink 限制同步远程 Sink 同时进行中的 Write 数量。达到上限时后续写入排队等待，
+// 超过 queueTimeout 或 ctx 结束时放弃并返回 ErrSinkBusy；Config.NeverSample 中的消息和需要确认写入的记录
+// 不受 queueTimeout 限制，一直等到有空位或 ctx 结束。
+// 端点变慢时，突发的错误日志最多占用 maxInFlight 个连接，其余调用方最多阻塞 queueTimeout，
+// 不会耗尽 goroutine 或文件描述符。
+type LimitedSink struct {
+	Sink
+	sem     chan struct{}
+	timeout time.Duration
+	exempt  exemptions // 不受等待时间限制的消息，由 newLogger 按 Config.NeverSample 设置
+}
+
+// NewLimitedSink 创建并发受限的 Sink，maxInFlight <= 0 时按 1 处理，
+// queueTimeout <= 0 时使用 DefaultSinkQueueTimeout
+func NewLimitedSink(sink Sink, maxInFlight int, queueTimeout time.Duration) *LimitedSink {
+	if maxInFlight <= 0 {
+		maxInFlight = 1
+	}
+	if queueTimeout <= 0 {
+		queueTimeout = DefaultSinkQueueTimeout
+	}
+	return &LimitedSink{Sink: sink, sem: make(chan struct{}, maxInFlight), timeout: queueTimeout}
+}
+
+func (s *LimitedSink) Write(ctx context.Context, records []Record) error {
+	select {
+	case s.sem <- struct{}{}:
+	default:
+		// 豁免的记录不设置超时，nil channel 永远不会就绪
+		var timeout <-chan time.Time
+		if !s.keep(ctx, records) {
+			timer := time.NewTimer(s.timeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		select {
+		case s.sem <- struct{}{}:
+		case <-timeout:
+			return ErrSinkBusy
+		case <-ctx.Done():
+			return errors.Join(ErrSinkBusy, ctx.Err())
+		}
+	}
+	defer func() { <-s.sem }()
+	return s.Sink.Write(ctx, records)
+}
+
+// keep 判断这批记录中是否有不能丢弃的记录
+func (s *LimitedSink) keep(ctx context.Context, records []Record) bool {
+	if receiptFrom(ctx) != nil {
+		return true
+	}
+	for _, r := range records {
+		if s.exempt.match(r.Message) {
+			return true
+		}
+	}
+	return false
+}
+
+// InFlight 返回当前进行中的 Write 数量
+func (s *LimitedSink) InFlight() int {
+	return len(s.sem)
+}
@@ -0,0 +1,111 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// blockingSink 的 Write 阻塞到 release 被关闭
+type blockingSink struct {
+	memorySink
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingSink) Write(ctx context.Context, records []Record) error {
+	s.started <- struct{}{}
+	<-s.release
+	return s.memorySink.Write(ctx, records)
+}
+
+func TestLimitedSink(t *testing.T) {
+	inner := &blockingSink{started: make(chan struct{}, 2), release: make(chan struct{})}
+	sink := NewLimitedSink(inner, 1, 20*time.Millisecond)
+	records := []Record{slog.NewRecord(time.Now(), slog.LevelError, "boom", 0)}
+
+	done := make(chan error)
+	go func() { done <- sink.Write(context.Background(), records) }()
+	<-inner.started
+	if n := sink.InFlight(); n != 1 {
+		t.Errorf("InFlight = %d, want 1", n)
+	}
+
+	start := time.Now()
+	if err := sink.Write(context.Background(), records); !errors.Is(err, ErrSinkBusy) {
+		t.Errorf("second Write = %v, want ErrSinkBusy", err)
+	}
+	if waited := time.Since(start); waited < 20*time.Millisecond {
+		t.Errorf("second Write returned after %v, want it to queue for the timeout", waited)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sink.Write(ctx, records); !errors.Is(err, context.Canceled) {
+		t.Errorf("Write with canceled ctx = %v, want context.Canceled", err)
+	}
+
+	close(inner.release)
+	if err := <-done; err != nil {
+		t.Errorf("first Write = %v", err)
+	}
+	if sink.InFlight() != 0 {
+		t.Errorf("InFlight after completion = %d, want 0", sink.InFlight())
+	}
+}
+
+func TestLimitedSinkQueues(t *testing.T) {
+	inner := &blockingSink{started: make(chan struct{}, 2), release: make(chan struct{})}
+	sink := NewLimitedSink(inner, 1, time.Minute)
+	records := []Record{slog.NewRecord(time.Now(), slog.LevelError, "boom", 0)}
+
+	done := make(chan error, 2)
+	for range 2 {
+		go func() { done <- sink.Write(context.Background(), records) }()
+	}
+	<-inner.started
+	select {
+	case <-inner.started:
+		t.Fatal("second Write started while the first was in flight")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(inner.release)
+	for range 2 {
+		if err := <-done; err != nil {
+			t.Errorf("Write = %v", err)
+		}
+	}
+	if len(inner.records) != 2 {
+		t.Errorf("delivered %d records, want the queued write to go through too", len(inner.records))
+	}
+}
+
+func TestLimitedSinkExempt(t *testing.T) {
+	inner := &blockingSink{started: make(chan struct{}, 3), release: make(chan struct{})}
+	sink := NewLimitedSink(inner, 1, 10*time.Millisecond)
+	sink.exempt = newExemptions([]string{"audit"})
+	record := func(msg string) []Record { return []Record{slog.NewRecord(time.Now(), slog.LevelInfo, msg, 0)} }
+
+	done := make(chan error, 3)
+	go func() { done <- sink.Write(context.Background(), record("first")) }()
+	<-inner.started
+	// 豁免的消息和需要确认的记录在超时之后仍然等待空位
+	go func() { done <- sink.Write(context.Background(), record("audit")) }()
+	go func() {
+		done <- sink.Write(context.WithValue(context.Background(), receiptKey{}, &receipt{}), record("must deliver"))
+	}()
+	if err := sink.Write(context.Background(), record("dropped")); !errors.Is(err, ErrSinkBusy) {
+		t.Errorf("Write = %v, want ErrSinkBusy", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(inner.release)
+	for range 3 {
+		if err := <-done; err != nil {
+			t.Errorf("Write = %v", err)
+		}
+	}
+	if len(inner.records) != 3 {
+		t.Errorf("delivered %d records, want the exempt records to survive the queue timeout", len(inner.records))
+	}
+}
@@ -3,22 +3,26 @@ package log
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"os/signal"
-	"path"
 	"path/filepath"
-	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
-
-	"gopkg.in/natefinch/lumberjack.v2"
+	"time"
 )
 
 var defaultLogger *Logger
 
+// exit 是 Fatal 记录后调用的退出函数，测试中可以替换
+var exit = os.Exit
+
 const (
 	DefaultMaxSize    = 50  // 默认50MB
 	DefaultMaxBackups = 100 // 默认保留100个备份
@@ -51,6 +55,16 @@ func isProduction() bool {
 }
 
 func init() {
+	// 设置了 LOG_EARLY_BOOT 时，在配置 logger 之前输出的记录先缓存，等待 Init，见 earlyboot.go
+	defer startEarlyBoot()
+
+	// LOG_QUIET=1 时默认 logger 不产生任何输出，供完全占用标准输出的程序（例如基于 stdio 的协议）使用，
+	// 直到程序用 SetDefaultLogger 设置自己的 logger
+	if os.Getenv("LOG_QUIET") == "1" {
+		defaultLogger = newLogger(Config{Writer: io.Discard, Quiet: true}, nil)
+		return
+	}
+
 	// 确保logs目录存在
 	if err := os.MkdirAll("logs", 0755); err != nil {
 		panic("failed to create logs directory: " + err.Error())
@@ -60,24 +74,40 @@ func init() {
 	maxSize := getEnvOrDefault("LOG_MAX_SIZE", DefaultMaxSize)
 	maxBackups := getEnvOrDefault("LOG_MAX_BACKUPS", DefaultMaxBackups)
 	maxAge := getEnvOrDefault("LOG_MAX_AGE", DefaultMaxAge)
-	logLevel := getEnvOrDefault("LOG_LEVEL", int(slog.LevelDebug))
+	logLevel := slog.LevelDebug
+	if v := os.Getenv("LOG_LEVEL"); v != "" {
+		if l, err := ParseLevel(v); err == nil {
+			logLevel = l // 支持 -4 这样的数字和 trace、info 这样的名称
+		}
+	}
 
 	// 根据环境设置压缩和标准输出
 	isProd := isProduction()
 	compress := isProd
-	stdout := !isProd
+	stdout := !isProd && os.Getenv("LOG_NO_STDOUT") != "1"
 
 	// 使用默认配置初始化全局logger
-	defaultLogger = NewLogger(Config{
-		Level:      slog.Level(logLevel),
-		Format:     "text",
-		Filename:   filepath.Join("logs", getLogFileName()),
-		MaxSize:    maxSize,
-		MaxBackups: maxBackups,
-		MaxAge:     maxAge,
-		Compress:   compress,
-		Stdout:     stdout,
-	})
+	cfg := Config{
+		Level:       logLevel,
+		Format:      "text",
+		Filename:    filepath.Join("logs", getLogFileName()),
+		MaxSize:     maxSize,
+		MaxBackups:  maxBackups,
+		MaxAge:      maxAge,
+		Compress:    compress,
+		Stdout:      stdout,
+		Development: !isProd,
+		NoStdout:    os.Getenv("LOG_NO_STDOUT") == "1",
+		ReportInit:  os.Getenv("LOG_INIT_REPORT") == "1",
+
+		LevelFile:         os.Getenv("LOG_LEVEL_FILE"),
+		LevelFileInterval: time.Duration(getEnvOrDefault("LOG_LEVEL_INTERVAL", 0)) * time.Second,
+	}
+	// LOG_REOPEN_ON_HUP=1 时 SIGHUP 重新打开日志文件而不是切换级别，配合外部 logrotate 使用
+	if os.Getenv("LOG_REOPEN_ON_HUP") == "1" {
+		cfg.ReopenSignal = syscall.SIGHUP
+	}
+	defaultLogger = newLogger(cfg, envSources("GO_ENV", "LOG_LEVEL", "LOG_MAX_SIZE", "LOG_MAX_BACKUPS", "LOG_MAX_AGE", "LOG_NO_STDOUT", "LOG_REOPEN_ON_HUP", "LOG_LEVEL_FILE", "LOG_LEVEL_INTERVAL"))
 }
 
 // 提供包级别的日志函数
@@ -97,10 +127,44 @@ func Error(msg string, args ...any) {
 	defaultLogger.Error(msg, args...)
 }
 
+// DebugContext 使用默认 Logger 输出带 ctx 的 Debug 级别日志
+func DebugContext(ctx context.Context, msg string, args ...any) {
+	defaultLogger.DebugContext(ctx, msg, args...)
+}
+
+// InfoContext 使用默认 Logger 输出带 ctx 的 Info 级别日志
+func InfoContext(ctx context.Context, msg string, args ...any) {
+	defaultLogger.InfoContext(ctx, msg, args...)
+}
+
+// WarnContext 使用默认 Logger 输出带 ctx 的 Warn 级别日志
+func WarnContext(ctx context.Context, msg string, args ...any) {
+	defaultLogger.WarnContext(ctx, msg, args...)
+}
+
+// ErrorContext 使用默认 Logger 输出带 ctx 的 Error 级别日志
+func ErrorContext(ctx context.Context, msg string, args ...any) {
+	defaultLogger.ErrorContext(ctx, msg, args...)
+}
+
 func Fatal(msg string, args ...any) {
 	defaultLogger.Fatal(msg, args...)
 }
 
+func Panic(msg string, args ...any) {
+	defaultLogger.Panic(msg, args...)
+}
+
+// ErrorSync 使用默认 logger 以 Error 级别输出日志，并等待记录被持久写入至少一个输出
+func ErrorSync(ctx context.Context, msg string, args ...any) error {
+	return defaultLogger.ErrorSync(ctx, msg, args...)
+}
+
+// Sync 刷新默认 logger 的缓冲
+func Sync() error {
+	return defaultLogger.Sync()
+}
+
 // With returns a new Logger with the given attributes added to the global logger
 func With(args ...any) *Logger {
 	return defaultLogger.With(args...)
@@ -119,13 +183,113 @@ func GetDefaultLogger() *Logger {
 // Config 定义日志库的配置
 type Config struct {
 	Level      slog.Level // 日志级别: debug, info, warn, error
-	Format     string     // 输出格式: json, text
+	Format     string     // 输出格式: json, text, console, logfmt, ecs, clef, csv, msgpack, protobuf, rfc5424, template 或 RegisterFormat 注册的格式
 	Filename   string     // 日志文件路径
 	MaxSize    int        // 每个日志文件的最大兆字节数 (MB)
 	MaxBackups int        // 保留的旧日志文件的最大数量
 	MaxAge     int        // 保留旧日志文件的最大天数
 	Compress   bool       // 是否压缩旧日志文件
 	Stdout     bool       // 是否同时输出到标准输出
+	Writer     io.Writer  // 额外的输出目标，例如 FailoverWriter
+
+	LevelNames map[slog.Level]string // 自定义级别名称，例如 DBG/INF/WRN/ERR 或 console 中的 emoji，未列出的级别使用默认名称
+
+	LineTemplate  string   // Format 为 template 时的行模板，默认 DefaultLineTemplate，见 template.go
+	FieldMap      FieldMap // text/json 格式内置字段的 key 和顺序，见 fieldmap.go
+	PrettyJSON    bool     // json 格式输出为缩进的多行 JSON，属性按 key 排序，用于本地调试，优先于 FieldMap
+	FlattenGroups bool     // json 格式将分组展开为以点分隔的 key（http.method），而不是嵌套对象
+	CSVColumns    []string // csv 格式的列，默认 DefaultCSVColumns，见 csv.go
+	CSVHeader     bool     // csv 格式是否在创建时先写入表头
+
+	TimePrecision TimePrecision // 文件和标准输出的时间戳精度，默认微秒
+
+	FileLevel   slog.Leveler // 文件输出的最低级别，nil 表示只受 Level 限制；记录需要同时达到 Level 和输出自己的级别，见 handler.go
+	StdoutLevel slog.Leveler // 标准输出（NoStdout 时为标准错误）的最低级别，例如文件记录 Debug 而终端只显示 Info 以上
+	WriterLevel slog.Leveler // Writer 的最低级别
+
+	NamedLevels map[string]slog.Level // 按 logger 名称设置的级别，名称可以使用通配，例如 {"db.*": slog.LevelWarn, "http": slog.LevelInfo}；只对这个 Logger 及其派生的 logger 生效，见 named.go
+
+	StreamHeader bool              // 在每个日志文件、输出流和 Sink 的开头写入一条头记录，描述记录结构版本、字段 key、配置摘要和构建信息，见 header.go
+	HeaderFields map[string]string // 写入头记录的静态字段，例如 service、region
+
+	BufferSize    int           // 文件输出的缓冲区大小（字节），大于 0 时启用缓冲写入
+	FlushInterval time.Duration // 缓冲区的定期刷新间隔，默认 DefaultFlushInterval
+
+	Sinks []SinkConfig // 通过名称引用的额外输出，见 RegisterSink
+
+	AddCaller          *bool                 // 是否输出调用位置，nil 表示输出；关闭后不再获取调用栈，适合对性能敏感且不需要调用位置的场景
+	CallerKey          string                // 调用位置的 key，默认 "source"
+	CallerKeyCollision CallerCollisionPolicy // 用户属性与调用位置 key 同名时的处理方式，默认 KeepBoth
+	CallerTemplate     string                // 调用位置的输出模板，默认 DefaultCallerTemplate，见 caller.go
+	CallerFormat       CallerFormat          // 调用位置的格式预设，CallerTemplate 非空时以 CallerTemplate 为准
+	CallerSource       bool                  // 以 slog.Source 的结构输出调用位置（<CallerKey>.function、.file、.line），与 slog 的 AddSource 相同，为 true 时忽略 CallerTemplate 和 CallerFormat
+	StacktraceLevel    slog.Leveler          // 达到该级别的记录自动附加 stacktrace 属性（去掉本库内部的栈帧），nil 表示不附加
+
+	RecordIDKey string // 非空时为每条记录生成唯一 ID 并以该 key 输出，ID 由 SetIDGenerator 设置的生成器产生
+
+	SpanContext SpanContextFunc // 非空时为 ctx 中有活动 span 的记录附加 trace_id 和 span_id，配合 InfoContext 等方法实现日志与链路关联，见 span.go
+
+	ContextKeys []ContextKeyMapping // 从 ctx 中取出请求 ID、用户 ID、租户等值附加到带 ctx 输出的每条记录，见 contextkeys.go
+
+	Baggage     BaggageFunc // 读取 ctx 中的 baggage 成员（例如 OpenTelemetry baggage），见 baggage.go
+	BaggageKeys []string    // 输出到每条带 ctx 记录的 baggage 成员允许列表，以成员名为 key，需要同时设置 Baggage
+
+	TailSampling *TailSampling    // 非空时 HTTPMiddleware 和 StartRPC 缓存每个请求的低级别日志，只在请求失败或过慢时输出，见 tail.go
+	AccessLog    *AccessLogWriter // 非空时 HTTPMiddleware 将请求结束的记录以 Combined Log Format 写入它，代替 "HTTP request" 记录，见 access.go
+
+	MaxRecordBytes int      // 单条记录的大小上限（字节，估算值），超出时按优先级丢弃 Optional 标记的属性，0 表示不限制
+	NeverSample    []string // 永不被采样或限流的消息或消息指纹（见 Fingerprint），例如安全事件、支付失败
+
+	KeyNormalizer *KeyNormalizer // 非空时在编码前规范化属性 key，见 normalize.go
+	Transforms    []Transform    // 在采样和输出之前执行的计算字段和条件丢弃规则，可以从配置文件加载，见 transform.go
+
+	TelemetryInterval time.Duration // 大于 0 时按该间隔输出运行时快照，见 StartTelemetry
+	LatencySampleRate int           // 大于 0 时每 N 条记录统计一次日志管道自身的延迟，见 PipelineLatency
+
+	ClockValidAfter time.Time // 非零时，系统时间早于它的记录视为时钟未同步，附加启动以来的单调时间，见 boottime.go
+
+	CompressFormat string // Compress 为 true 时备份的压缩格式：gzip（默认）或 zstd，zstd 更快、压缩率更高，见 zstd.go
+	CompressLevel  int    // zstd 压缩级别（1-22），0 表示默认级别 3
+	ZstdDictionary []byte // zstd 压缩备份时使用的字典，可由 TrainZstdDictionary 训练，解压时需要同一个字典
+
+	MaxTotalSize int // 当前文件和所有滚动出的文件的总大小上限 (MB)，超出时从最旧的文件开始删除，与 MaxBackups/MaxAge 同时生效，见 retention.go
+
+	ArchiveDir string // 滚动出的文件移动到的目录，例如 logs/archive，默认按月份建子目录（logs/archive/2024-05/），也可以用 {2006-01-02} 等模板指定子目录，见 archive.go
+
+	RotateInterval time.Duration // 按时间滚动文件的周期，例如 RotateDaily、RotateHourly 或 6 * time.Hour，Filename 可以使用 {app}、{2006010215} 等模板，见 rotate.go；0 表示只按大小滚动
+	RotateUTC      bool          // 按 UTC 而不是本地时间计算滚动时间和文件名中的日期
+	Symlink        string        // 按时间滚动时始终指向当前文件的符号链接，例如 logs/app.log，便于 tail -F；为空时不创建
+
+	OnRotate     func(oldPath string) // 文件滚动并完成压缩后在后台调用，oldPath 是滚动出的文件，可用于上传或通知，见 rotatefile.go
+	RotateSignal os.Signal            // 收到该信号时调用 Rotate 切换日志文件，例如配合 logrotate 使用 syscall.SIGHUP，优先于 LevelSignals
+	ReopenSignal os.Signal            // 收到该信号时调用 Reopen 重新打开日志文件，配合 logrotate 的 create 和 postrotate kill -HUP 使用 syscall.SIGHUP，优先于 LevelSignals
+
+	LevelSignals map[os.Signal]slog.Level // 收到信号时切换到的日志级别，nil 时使用 DefaultLevelSignals；非 nil 的空 map 关闭按信号切换级别，见 level.go
+
+	LevelFile         string        // 定期读取的级别文件，例如 Kubernetes 挂载的 ConfigMap，内容为 debug 或 LOG_LEVEL=debug，变化时调整级别，见 levelwatch.go
+	LevelFileInterval time.Duration // 读取 LevelFile 的间隔，默认 DefaultLevelFileInterval
+
+	RotateOnStart bool // 创建时滚动已有的非空日志文件，让每次运行从新文件开始，便于批处理任务和排查崩溃的运行
+
+	NoStdout bool // 保证不写入标准输出，供用标准输出承载协议的程序（LSP 服务、git credential helper、插件）使用；开发模式下发现写入标准输出的配置会 panic，否则改为标准错误，见 stdio.go
+
+	FsyncPolicy FsyncPolicy     // 文件输出何时 fsync 落盘，默认 FsyncNever
+	OnFileError func(err error) // 后台压缩、清理旧文件出错时调用，默认输出到 stderr
+
+	FileMode os.FileMode // 新建日志文件的权限，例如 0600，默认 0644；显式设置时不受 umask 影响，见 perm.go
+	DirMode  os.FileMode // 新建日志目录的权限，例如 0700，默认 0755
+	FileUID  int         // 新建的日志文件和目录的属主，0 表示不修改（进程自身的用户），通常需要 root 或 CAP_CHOWN
+	FileGID  int         // 新建的日志文件和目录的属组，0 表示不修改，例如设置为 adm 组的 gid 让运维账号可读
+
+	ReportInit bool // 创建后输出一条 Info 级别的初始化报告记录，包含打开的输出、回退情况和配置来源，见 InitReport
+	Quiet      bool // 初始化中的警告（例如未知格式、Sink 创建失败）不输出到 stderr，只记录在初始化报告中
+
+	ThreadInfo bool // 附加 OS 线程 ID 和 CPU 编号，仅用于排查线程相关问题，每条记录都有系统调用开销，默认关闭
+
+	Development bool // 开发模式，Assert 失败时 panic
+
+	Translator     Translator // 非空时为有译文的消息追加译文属性，按 msg_id 属性或原消息查找，见 translate.go
+	TranslationKey string     // 译文属性的 key，默认 DefaultTranslationKey（msg_zh）
 }
 
 // Logger 是我们封装的日志器
@@ -134,86 +298,348 @@ type Logger struct {
 	handler    slog.Handler
 	level      *slog.LevelVar
 	callerSkip int // 添加 callerSkip 字段来控制调用栈跳过的层数
-}
 
-// getCallerLocation returns the file name and line number of the caller
-func getCallerLocation(skip int) string {
-	_, file, line, ok := runtime.Caller(skip)
-	if ok {
-		// funcName := runtime.FuncForPC(pc).Name()
-		fileName := path.Base(file)
-		// funcNames := strings.Split(funcName, ".")
-		// funcName = funcNames[len(funcNames)-1]
-		var buffer bytes.Buffer
-		buffer.WriteString("[")
-		buffer.WriteString(fileName)
-		// buffer.WriteString(":")
-		// buffer.WriteString(funcName)
-		buffer.WriteString(":")
-		buffer.WriteString(strconv.Itoa(line))
-		buffer.WriteString("]")
-		return buffer.String()
-	}
-	return ""
+	callerKey     string                // 调用位置的 key
+	callerTmpl    callerTemplate        // 调用位置的输出模板
+	callerSource  bool                  // 以 slog.Source 的结构输出调用位置
+	noCaller      bool                  // 不输出调用位置，Config.AddCaller 为 false 时设置
+	stackLevel    slog.Leveler          // 达到该级别的记录附加调用栈，为 nil 时不附加
+	collision     CallerCollisionPolicy // key 冲突处理策略
+	userCallerKey bool                  // With 绑定的属性中是否已包含调用位置的 key
+	recordIDKey   string                // 记录 ID 的 key，为空时不生成
+	name          string                // Named 设置的名称，根 Logger 为空
+	named         *namedLevels          // 按名称设置的级别，派生出的 logger 共享
+
+	sinks   []Sink      // 需要在 Close 时关闭的 Sink
+	closers []io.Closer // 需要在 Close 时关闭的文件等输出
+	rotator rotator     // 文件输出，没有配置 Filename 时为 nil
+
+	storm       *storm            // 风暴模式状态
+	development bool              // 开发模式
+	latency     *latencyHistogram // 日志管道延迟统计，未开启时为 nil
+	report      InitReport        // 初始化报告
+	stopSignals func()            // 停止信号监听 goroutine
+	boot        *earlyBoot        // 早期启动缓存，只有包初始化时创建的默认 logger 有
+	meters      []*sinkMeter      // 各 Sink 的写入统计，见 stats.go
+	rotation    *rotationMeter    // 文件滚动统计，没有配置 Filename 时为 nil
+	tail        *TailSampling     // HTTPMiddleware 和 StartRPC 使用的尾部采样配置，为 nil 时不缓存
+	access      *AccessLogWriter  // HTTPMiddleware 输出访问日志的 writer，为 nil 时输出 "HTTP request" 记录
 }
 
 // 以下是封装的日志方法，可以直接调用 slog.Logger 的方法
 func (l *Logger) Debug(msg string, args ...any) {
-	caller := getCallerLocation(3 + l.callerSkip)
-	args = append(args, "source", caller)
-	l.Logger.Debug(msg, args...)
+	l.log(context.Background(), slog.LevelDebug, msg, args...)
 }
 
 func (l *Logger) Info(msg string, args ...any) {
-	caller := getCallerLocation(3 + l.callerSkip)
-	args = append(args, "source", caller)
-	l.Logger.Info(msg, args...)
+	l.log(context.Background(), slog.LevelInfo, msg, args...)
 }
 
 func (l *Logger) Warn(msg string, args ...any) {
-	caller := getCallerLocation(3 + l.callerSkip)
-	args = append(args, "source", caller)
-	l.Logger.Warn(msg, args...)
+	l.log(context.Background(), slog.LevelWarn, msg, args...)
 }
 
 func (l *Logger) Error(msg string, args ...any) {
-	caller := getCallerLocation(3 + l.callerSkip)
-	args = append(args, "source", caller)
-	l.Logger.Error(msg, args...)
+	l.log(context.Background(), slog.LevelError, msg, args...)
+}
+
+// DebugContext 输出 Debug 级别的日志，ctx 传给 handler，供提取 trace ID 等上下文信息的 handler 和 Sink 使用。
+// 覆盖内嵌 slog.Logger 的同名方法，使调用位置与其他方法一致，下同
+func (l *Logger) DebugContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, slog.LevelDebug, msg, args...)
 }
 
-// Fatal 级别，通常在记录后退出程序
+// InfoContext 输出带 ctx 的 Info 级别日志
+func (l *Logger) InfoContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, slog.LevelInfo, msg, args...)
+}
+
+// WarnContext 输出带 ctx 的 Warn 级别日志
+func (l *Logger) WarnContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, slog.LevelWarn, msg, args...)
+}
+
+// ErrorContext 输出带 ctx 的 Error 级别日志
+func (l *Logger) ErrorContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, slog.LevelError, msg, args...)
+}
+
+// ErrorSync 以 Error 级别输出日志，并等待记录被持久写入至少一个输出后返回，见 delivery.go
+func (l *Logger) ErrorSync(ctx context.Context, msg string, args ...any) error {
+	return l.deliver(ctx, slog.LevelError, msg, l.pc(), args)
+}
+
+// Fatal 以 LevelFatal 级别输出日志后退出程序
 func (l *Logger) Fatal(msg string, args ...any) {
-	caller := getCallerLocation(3 + l.callerSkip)
-	// 将 caller 信息添加到 args 中
-	args = append(args, "source", caller)
-	l.Logger.Error(msg, args...) // slog 没有内置 fatal 级别，通常用 Error 记录后 os.Exit
-	os.Exit(1)
+	l.log(context.Background(), LevelFatal, msg, args...)
+	l.Sync() // 退出前确保缓冲中的日志已写出
+	exit(1)
+}
+
+// Panic 以 LevelPanic 级别输出日志后以 msg 触发 panic，可以被 recover
+func (l *Logger) Panic(msg string, args ...any) {
+	l.log(context.Background(), LevelPanic, msg, args...)
+	l.Sync()
+	panic(msg)
+}
+
+// Log 以指定级别输出日志，覆盖内嵌 slog.Logger 的同名方法，使调用位置与其他方法一致
+func (l *Logger) Log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	l.log(ctx, level, msg, args...)
+}
+
+// LogAttrs 与 Log 相同，但只接受 slog.Attr，覆盖内嵌 slog.Logger 的同名方法
+func (l *Logger) LogAttrs(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	args := make([]any, len(attrs))
+	for i, a := range attrs {
+		args[i] = a
+	}
+	l.log(ctx, level, msg, args...)
+}
+
+// log 是各级别方法的公共实现，负责注入调用位置并处理 key 冲突
+func (l *Logger) log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	if args, ok := stripMustDeliver(args); ok {
+		if err := l.deliver(ctx, level, msg, l.pc(), args); err != nil {
+			fmt.Fprintf(os.Stderr, "slogx: %q not delivered: %v\n", msg, err)
+		}
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// 级别未开启的记录不获取调用栈
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	l.logAt(ctx, level, msg, l.pc(), args)
+}
+
+// pc 返回调用位置的 PC，关闭了调用位置时返回 0
+func (l *Logger) pc() uintptr {
+	if l.noCaller {
+		return 0
+	}
+	return callerPC(l.callerSkip)
+}
+
+// logAt 输出调用位置为 pc 的记录
+func (l *Logger) logAt(ctx context.Context, level slog.Level, msg string, pc uintptr, args []any) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var start time.Time
+	if l.latency.sample() {
+		start = time.Now()
+	}
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	args = l.withStacktrace(level, args)
+	// pc 为 0 的记录没有调用位置（如初始化报告），不输出空的 caller
+	if !l.noCaller && pc != 0 {
+		callerKey := l.callerKey
+		switch l.collision {
+		case RenameUserKey:
+			args = renameKey(args, callerKey, callerKey+"_user")
+		case RenameCallerKey:
+			if l.userCallerKey || hasKey(args, callerKey) {
+				callerKey += "_caller"
+			}
+		}
+		// 将 caller 信息添加到 args 中，记录的 PC 与 caller 指向同一位置，供需要结构化调用位置的格式使用
+		args = append(args, slog.Attr{Key: callerKey, Value: l.callerValue(pc)})
+	}
+	if l.name != "" {
+		args = append(args, LoggerKey, l.name)
+	}
+	if l.recordIDKey != "" {
+		args = append(args, l.recordIDKey, NewID())
+	}
+	r := slog.NewRecord(now(), level, msg, pc)
+	r.Add(args...)
+	_ = l.Handler().Handle(ctx, r)
+	if !start.IsZero() {
+		l.latency.observe(time.Since(start))
+	}
+}
+
+// callerValue 返回 pc 对应的调用位置：按模板格式化的字符串，或 CallerSource 时的分组；无法解析时为空字符串
+func (l *Logger) callerValue(pc uintptr) slog.Value {
+	frame, ok := frameForPC(pc)
+	if !ok {
+		return slog.StringValue("")
+	}
+	if l.callerSource {
+		return sourceValue(frame)
+	}
+	return slog.StringValue(l.callerTmpl.format(frame))
 }
 
 // With 为 Logger 添加额外的属性
 func (l *Logger) With(args ...any) *Logger {
-	return &Logger{
-		Logger:     l.Logger.With(args...),
-		handler:    l.handler,
-		level:      l.level,
-		callerSkip: l.callerSkip + 1, // 增加 callerSkip，因为多了一层调用
-	}
+	newLogger := *l // 输出时的调用深度不变，保留 callerSkip
+	newLogger.bindAttrs(args)
+	return &newLogger
 }
 
-// WithCallerSkip returns a new Logger with custom caller skip level
+// WithCallerSkip returns a new Logger with custom caller skip level.
+// 库内部的栈帧总是被跳过，skip 是调用方自己封装日志的函数的层数，调用位置为这些函数的调用方
 func (l *Logger) WithCallerSkip(skip int, args ...any) *Logger {
-	newLogger := &Logger{
-		Logger:  l.Logger.With(args...),
-		handler: l.handler,
-		level:   l.level,
+	newLogger := *l
+	newLogger.callerSkip = skip
+	newLogger.bindAttrs(args)
+	return &newLogger
+}
+
+// bindAttrs 将属性绑定到 Logger 上，绑定前按冲突策略处理与调用位置同名的 key
+func (l *Logger) bindAttrs(args []any) {
+	switch l.collision {
+	case RenameUserKey:
+		args = renameKey(args, l.callerKey, l.callerKey+"_user")
+	case RenameCallerKey:
+		l.userCallerKey = l.userCallerKey || hasKey(args, l.callerKey)
+	}
+	l.Logger = l.Logger.With(args...)
+}
+
+// renameLevel 按 names 替换顶层级别字段的输出名称，未列出的 Trace、Panic、Fatal 级别使用 levelString 的名称
+func renameLevel(names map[slog.Level]string, groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 || a.Key != slog.LevelKey {
+		return a
+	}
+	if l, ok := a.Value.Any().(slog.Level); ok {
+		if name, ok := names[l]; ok {
+			a.Value = slog.StringValue(name)
+		} else if customLevel(l) {
+			a.Value = slog.StringValue(levelString(l))
+		}
+	}
+	return a
+}
+
+// CallerCollisionPolicy 定义用户属性与注入的调用位置 key 同名时的处理方式
+type CallerCollisionPolicy int
+
+const (
+	// KeepBoth 同时输出两个同名属性，与旧版本行为一致（默认）
+	KeepBoth CallerCollisionPolicy = iota
+	// RenameUserKey 保留调用位置，用户的同名属性改名为 "<key>_user"
+	RenameUserKey
+	// RenameCallerKey 保留用户属性，调用位置改用 "<key>_caller" 输出
+	RenameCallerKey
+)
+
+// forEachKey 按 slog 的参数规则遍历 args 中的 key，f 返回新的 key
+func forEachKey(args []any, f func(key string) string) []any {
+	var out []any
+	for i := 0; i < len(args); i++ {
+		switch v := args[i].(type) {
+		case string:
+			if nk := f(v); nk != v {
+				if out == nil {
+					out = append([]any(nil), args...)
+				}
+				out[i] = nk
+			}
+			i++ // 跳过 value
+		case slog.Attr:
+			if nk := f(v.Key); nk != v.Key {
+				if out == nil {
+					out = append([]any(nil), args...)
+				}
+				out[i] = slog.Attr{Key: nk, Value: v.Value}
+			}
+		}
+	}
+	if out == nil {
+		return args
+	}
+	return out
+}
+
+// renameKey 将 args 中名为 from 的 key 改为 to
+func renameKey(args []any, from, to string) []any {
+	return forEachKey(args, func(key string) string {
+		if key == from {
+			return to
+		}
+		return key
+	})
+}
+
+// hasKey 判断 args 中是否包含指定的 key
+func hasKey(args []any, key string) bool {
+	found := false
+	forEachKey(args, func(k string) string {
+		found = found || k == key
+		return k
+	})
+	return found
+}
+
+// Sync 将缓冲中的日志写入输出，如果输出支持则同时落盘
+func (l *Logger) Sync() error {
+	if l.boot != nil {
+		l.boot.flush()
 	}
-	return newLogger
+	var errs []error
+	for _, c := range l.closers {
+		if s, ok := c.(interface{ Sync() error }); ok {
+			if err := s.Sync(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Rotate 立即切换到新的日志文件，当前文件按滚动规则改名为备份，用于配合外部的 logrotate 或由运维手动触发。
+// 没有配置 Filename 时什么也不做。
+func (l *Logger) Rotate() error {
+	if l.rotator == nil {
+		return nil
+	}
+	return l.rotator.Rotate()
+}
+
+// Reopen 关闭并重新打开日志文件路径，不改名文件，用于外部 logrotate 按 create 方式改名文件之后，
+// 在 postrotate 中发送 ReopenSignal 让程序写入新文件。没有配置 Filename 时什么也不做。
+func (l *Logger) Reopen() error {
+	r, ok := l.rotator.(reopener)
+	if !ok {
+		return nil
+	}
+	return r.Reopen()
+}
+
+// Close 停止信号监听，关闭 Logger 使用的所有输出和 Sink
+func (l *Logger) Close() error {
+	if l.boot != nil {
+		l.boot.flush()
+	}
+	if l.stopSignals != nil {
+		l.stopSignals()
+	}
+	var errs []error
+	for _, c := range l.closers {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, sink := range l.sinks {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // wrappedHandler 包装原有的 handler，添加文件行号
 type wrappedHandler struct {
-	handler slog.Handler
+	handler   slog.Handler
+	callerKey string
+	caller    func(pc uintptr) slog.Value
 }
 
 func (h *wrappedHandler) Enabled(ctx context.Context, level slog.Level) bool {
@@ -224,8 +650,10 @@ func (h *wrappedHandler) Handle(ctx context.Context, r slog.Record) error {
 	// 创建一个新的 Record，先不设置消息
 	newRecord := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
 
-	// 先添加调用位置
-	newRecord.AddAttrs(slog.String("source", getCallerLocation(4)))
+	// 先添加调用位置，slog.Logger 已经将调用方的 PC 记录在 r.PC 中
+	if h.caller != nil && r.PC != 0 {
+		newRecord.AddAttrs(slog.Attr{Key: h.callerKey, Value: h.caller(r.PC)})
+	}
 
 	// 添加原有的其他属性
 	r.Attrs(func(a slog.Attr) bool {
@@ -237,11 +665,11 @@ func (h *wrappedHandler) Handle(ctx context.Context, r slog.Record) error {
 }
 
 func (h *wrappedHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return &wrappedHandler{handler: h.handler.WithAttrs(attrs)}
+	return &wrappedHandler{handler: h.handler.WithAttrs(attrs), callerKey: h.callerKey, caller: h.caller}
 }
 
 func (h *wrappedHandler) WithGroup(name string) slog.Handler {
-	return &wrappedHandler{handler: h.handler.WithGroup(name)}
+	return &wrappedHandler{handler: h.handler.WithGroup(name), callerKey: h.callerKey, caller: h.caller}
 }
 
 // WithField creates a logger with a field
@@ -251,7 +679,11 @@ func WithField(key string, value any) *slog.Logger {
 
 	// 创建一个新的 handler，在每次记录日志时添加文件行号
 	newHandler := &wrappedHandler{
-		handler: origLogger.Handler(),
+		handler:   origLogger.Handler(),
+		callerKey: defaultLogger.callerKey,
+	}
+	if !defaultLogger.noCaller {
+		newHandler.caller = defaultLogger.callerValue
 	}
 
 	return slog.New(newHandler)
@@ -259,80 +691,466 @@ func WithField(key string, value any) *slog.Logger {
 
 // NewLogger 初始化并返回一个 Logger 实例
 func NewLogger(cfg Config) *Logger {
-	var writers []io.Writer
+	return newLogger(cfg, nil)
+}
 
-	// 配置 lumberjack
-	if cfg.Filename != "" {
-		lumberjackLogger := &lumberjack.Logger{
-			Filename:   cfg.Filename,
-			MaxSize:    cfg.MaxSize,
-			MaxBackups: cfg.MaxBackups,
-			MaxAge:     cfg.MaxAge,
-			Compress:   cfg.Compress,
+// newLogger 创建 Logger，sources 是各配置项的来源，写入初始化报告
+func newLogger(cfg Config, sources map[string]string) *Logger {
+	report := InitReport{Sources: sources}
+	fallback := func(format string, args ...any) {
+		msg := fmt.Sprintf(format, args...)
+		report.Fallbacks = append(report.Fallbacks, msg)
+		if !cfg.Quiet {
+			fmt.Fprintln(os.Stderr, "slogx: "+msg)
 		}
-		writers = append(writers, lumberjackLogger)
-	}
-
-	// 是否同时输出到标准输出
-	if cfg.Stdout {
-		writers = append(writers, os.Stdout)
 	}
 
-	// 如果没有配置任何输出，则默认输出到标准输出
-	if len(writers) == 0 {
-		writers = append(writers, os.Stdout)
-	}
-
-	// 创建一个 MultiWriter 来同时写入多个目标
-	multiWriter := io.MultiWriter(writers...)
-
 	// 设置日志级别
 	level := &slog.LevelVar{}
 	level.Set(cfg.Level)
+	named := newNamedLevels()
+	floor := levelFloor{base: level, named: named} // 输出按最低的级别放行，命名 logger 的级别由 namedHandler 判断
+	for _, err := range named.setAll(cfg.NamedLevels) {
+		fallback("%v, skipped", err)
+	}
+
+	callerKey := cfg.CallerKey
+	if callerKey == "" {
+		callerKey = "source"
+	}
 
-	var handler slog.Handler
 	// 配置 slog Handler
 	handlerOptions := &slog.HandlerOptions{
 		AddSource: false,
-		Level:     level,
+		Level:     floor,
 		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
 			if a.Key == slog.TimeKey && a.Value.Kind() == slog.KindTime {
 				return slog.Attr{
 					Key:   "time",
-					Value: slog.StringValue(a.Value.Time().Format("2006-01-02 15:04:05.000000")),
+					Value: slog.StringValue(a.Value.Time().Format(cfg.TimePrecision.layout())),
 				}
 			}
-			return a
+			return renameLevel(cfg.LevelNames, groups, a)
 		},
 	}
 
-	if cfg.Format == "json" {
-		handler = slog.NewJSONHandler(multiWriter, handlerOptions)
-	} else {
-		handler = slog.NewTextHandler(multiWriter, handlerOptions)
+	// 自定义格式自行格式化时间，不使用 text/json 的时间替换
+	formatOptions := &slog.HandlerOptions{Level: floor}
+	if len(cfg.LevelNames) > 0 {
+		formatOptions.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+			return renameLevel(cfg.LevelNames, groups, a)
+		}
+	}
+	// newFormat 创建写入 w 的格式 handler，console 格式按 writers 中的输出是否为终端决定是否着色
+	newFormat := func(w io.Writer, writers []io.Writer) slog.Handler {
+		var h slog.Handler
+		switch cfg.Format {
+		case "json":
+			if cfg.PrettyJSON {
+				ph := newPrettyJSONHandler(w, formatOptions, cfg.TimePrecision.layout())
+				ph.callerKey = callerKey
+				h = ph
+				break
+			}
+			if !cfg.FieldMap.isZero() {
+				h = fieldMapHandler(w, formatOptions, cfg, callerKey)
+				break
+			}
+			h = slog.NewJSONHandler(w, handlerOptions)
+		case "logfmt":
+			lh := newLogfmtHandler(w, formatOptions)
+			lh.callerKey = callerKey
+			h = lh
+		case "ecs":
+			eh := newECSHandler(w, formatOptions)
+			eh.callerKey = callerKey
+			h = eh
+		case "clef":
+			ch := newCLEFHandler(w, formatOptions)
+			ch.callerKey = callerKey
+			h = ch
+		case "msgpack":
+			mh := newMsgpackHandler(w, formatOptions)
+			mh.callerKey = callerKey
+			h = mh
+		case "csv":
+			ch := newCSVHandler(w, formatOptions, cfg.CSVColumns, cfg.CSVHeader, cfg.TimePrecision.layout())
+			ch.callerKey = callerKey
+			h = ch
+		case "protobuf":
+			ph := newProtobufHandler(w, formatOptions)
+			ph.callerKey = callerKey
+			h = ph
+		case "rfc5424":
+			rh := newRFC5424Handler(w, formatOptions, RFC5424Options{})
+			rh.callerKey = callerKey
+			h = rh
+		case "template":
+			th := newTemplateHandler(w, formatOptions, cfg.LineTemplate, cfg.TimePrecision.layout())
+			th.callerKey = callerKey
+			h = th
+		case "console":
+			// 终端输出带颜色，文件等其他输出不带颜色
+			var tty, plain []io.Writer
+			for _, ww := range writers {
+				if isTerminal(ww) {
+					tty = append(tty, ww)
+				} else {
+					plain = append(plain, ww)
+				}
+			}
+			var consoleHandlers []slog.Handler
+			if len(tty) > 0 {
+				ch := newConsoleHandler(io.MultiWriter(tty...), formatOptions, true)
+				ch.callerKey = callerKey
+				consoleHandlers = append(consoleHandlers, ch)
+			}
+			if len(plain) > 0 {
+				ch := newConsoleHandler(io.MultiWriter(plain...), formatOptions, false)
+				ch.callerKey = callerKey
+				consoleHandlers = append(consoleHandlers, ch)
+			}
+			h = newMultiHandler(consoleHandlers...)
+		default:
+			if factory, ok := lookupFormat(cfg.Format); ok {
+				opts := *handlerOptions // 传入副本，factory 修改参数不影响其他输出
+				h = factory(w, &opts)
+				break
+			}
+			if !cfg.FieldMap.isZero() {
+				h = fieldMapHandler(w, formatOptions, cfg, callerKey)
+				break
+			}
+			h = slog.NewTextHandler(w, handlerOptions)
+		}
+		if cfg.FlattenGroups && cfg.Format == "json" {
+			h = newFlattenHandler(h)
+		}
+		// 其他精度由 ReplaceAttr 中的时间格式处理，只有省略时间戳需要在记录上处理
+		if cfg.TimePrecision == TimeOmit {
+			h = withPrecision(h, TimeOmit)
+		}
+		return h
+	}
+
+	// 头记录按输出的格式编码一次，写在每个文件和输出流的开头
+	var header []byte
+	streamHeader := newStreamHeader(cfg, callerKey)
+	if cfg.StreamHeader {
+		var buf bytes.Buffer
+		h := newFormat(&buf, []io.Writer{&buf})
+		buf.Reset() // 丢弃创建 handler 时写入的内容，例如 csv 表头
+		h.Handle(context.Background(), streamHeader.record())
+		header = buf.Bytes()
+	}
+
+	var writers []io.Writer
+	var writerLevels []slog.Leveler // 各输出自己的最低级别，nil 表示只受 Level 限制
+	var closers []io.Closer
+	var fileRotator rotator
+	var rotation *rotationMeter
+
+	// 配置按大小和时间滚动的文件输出
+	if cfg.Filename != "" {
+		rotation = &rotationMeter{}
+		var fileWriter io.WriteCloser
+		if cfg.RotateInterval > 0 {
+			r := newTimeRotator(cfg)
+			r.meter = rotation
+			r.header = header
+			fileWriter = r
+		} else {
+			f := newFileOutput(cfg, cfg.Filename)
+			f.meter = rotation
+			f.header = header
+			fileWriter = f
+		}
+		// 上次运行留下的内容滚动为备份，空文件不需要滚动
+		if cfg.RotateOnStart {
+			if info, err := os.Stat(activeFile(cfg)); err == nil && info.Size() > 0 {
+				if err := fileWriter.(rotator).Rotate(); err != nil {
+					fallback("rotate %s on start: %v", cfg.Filename, err)
+				}
+			}
+		}
+		if cfg.MaxTotalSize > 0 {
+			pruneTotalSize(cfg, "")
+		}
+		if cfg.BufferSize > 0 {
+			fileWriter = NewBufferedWriter(fileWriter, cfg.BufferSize, cfg.FlushInterval)
+		}
+		writers = append(writers, fileWriter)
+		writerLevels = append(writerLevels, cfg.FileLevel)
+		closers = append(closers, fileWriter)
+		report.Outputs = append(report.Outputs, "file:"+cfg.Filename)
+		fileRotator, _ = fileWriter.(rotator)
 	}
+	fileOutputs := len(writers) // 文件输出在打开新文件时自行写入头记录
+
+	// NoStdout 模式下发现写入标准输出的配置：开发模式直接 panic，否则改为标准错误
+	stdoutGuard := func(what string) {
+		if cfg.Development {
+			panic("slogx: NoStdout is set but " + what + " writes to stdout")
+		}
+		fallback("%s writes to stdout, redirected to stderr (NoStdout)", what)
+	}
+
+	if cfg.Writer != nil {
+		w := cfg.Writer
+		if cfg.NoStdout && isStdout(w) {
+			stdoutGuard("Writer")
+			w = os.Stderr
+		}
+		writers = append(writers, w)
+		writerLevels = append(writerLevels, cfg.WriterLevel)
+		report.Outputs = append(report.Outputs, "writer")
+	}
+
+	// 是否同时输出到标准输出
+	if cfg.Stdout {
+		if cfg.NoStdout {
+			stdoutGuard("Stdout")
+			writers = append(writers, os.Stderr)
+			writerLevels = append(writerLevels, cfg.StdoutLevel)
+			report.Outputs = append(report.Outputs, "stderr")
+		} else {
+			writers = append(writers, os.Stdout)
+			writerLevels = append(writerLevels, cfg.StdoutLevel)
+			report.Outputs = append(report.Outputs, "stdout")
+		}
+	}
+
+	// 永不采样、限流的消息，所有丢弃记录的机制共用
+	exempt := newExemptions(cfg.NeverSample)
+
+	// 创建配置中引用的 Sink，创建失败时跳过该 Sink，不影响其他输出
+	var sinks []Sink
+	var sinkPrecisions []TimePrecision
+	var sinkLevels []slog.Leveler
+	var meters []*sinkMeter
+	for _, sc := range cfg.Sinks {
+		if cfg.NoStdout && sinkWritesStdout(sc) {
+			stdoutGuard(fmt.Sprintf("sink %q", sc.Type))
+			sc.Type = "stderr"
+		}
+		sink, err := NewSink(sc)
+		if err != nil {
+			fallback("failed to create sink %q, skipped: %v", sc.Type, err)
+			continue
+		}
+		report.Outputs = append(report.Outputs, "sink:"+sc.Type)
+		sinks = append(sinks, sink)
+		sinkPrecisions = append(sinkPrecisions, sc.TimePrecision)
+		sinkLevels = append(sinkLevels, sc.MinLevel)
+		if sc.MaxInFlight > 0 {
+			limited := NewLimitedSink(sink, sc.MaxInFlight, sc.QueueTimeout)
+			limited.exempt = exempt
+			sink = limited
+		}
+		meters = append(meters, newSinkMeter(sc.Type, sink))
+	}
+
+	// 如果没有配置任何输出，则默认输出到标准输出
+	if len(writers) == 0 && len(sinks) == 0 {
+		if cfg.NoStdout {
+			writers = append(writers, os.Stderr)
+			writerLevels = append(writerLevels, cfg.StdoutLevel)
+			report.Outputs = append(report.Outputs, "stderr")
+			report.Fallbacks = append(report.Fallbacks, "no output configured, using stderr")
+		} else {
+			writers = append(writers, os.Stdout)
+			writerLevels = append(writerLevels, cfg.StdoutLevel)
+			report.Outputs = append(report.Outputs, "stdout")
+			report.Fallbacks = append(report.Fallbacks, "no output configured, using stdout")
+		}
+	}
+	if header != nil {
+		for _, w := range writers[fileOutputs:] {
+			w.Write(header)
+		}
+	}
+
+	var handlers []slog.Handler
+	if len(writers) > 0 {
+		if _, ok := lookupFormat(cfg.Format); !ok && cfg.Format != "" && !slices.Contains(builtinFormats, cfg.Format) {
+			fallback("unknown format %q, using text", cfg.Format)
+		}
+		if slices.ContainsFunc(writerLevels, func(l slog.Leveler) bool { return l != nil }) {
+			// 各输出有自己的最低级别时分别编码
+			for i, w := range writers {
+				h := newFormat(w, writers[i:i+1])
+				if writerLevels[i] != nil {
+					h = &minLevelHandler{Handler: h, min: writerLevels[i]}
+				}
+				handlers = append(handlers, &deliveryHandler{Handler: h, sync: syncWriters(writers[i : i+1])})
+			}
+		} else {
+			h := newFormat(io.MultiWriter(writers...), writers)
+			handlers = append(handlers, &deliveryHandler{Handler: h, sync: syncWriters(writers)})
+		}
+	}
+	for i, sink := range sinks {
+		h := withPrecision(newSinkHandler(meters[i], floor), sinkPrecisions[i])
+		if sinkLevels[i] != nil {
+			h = &minLevelHandler{Handler: h, min: sinkLevels[i]}
+		}
+		if cfg.StreamHeader {
+			h.Handle(context.Background(), streamHeader.record())
+		}
+		handlers = append(handlers, &deliveryHandler{Handler: h, sync: syncSink(sink)})
+	}
+	handler := newMultiHandler(handlers...)
+	if cfg.MaxRecordBytes > 0 {
+		handler = &budgetHandler{Handler: handler, max: cfg.MaxRecordBytes}
+	}
+	if cfg.ThreadInfo {
+		handler = &threadHandler{Handler: handler}
+	}
+	if cfg.KeyNormalizer != nil {
+		handler = &normalizeHandler{Handler: handler, n: cfg.KeyNormalizer, keep: callerKey}
+	}
+	if cfg.Translator != nil {
+		key := cfg.TranslationKey
+		if key == "" {
+			key = DefaultTranslationKey
+		}
+		handler = &translateHandler{Handler: handler, translator: cfg.Translator, key: key}
+	}
+	if !cfg.ClockValidAfter.IsZero() {
+		handler = newBootTimeHandler(handler, cfg.ClockValidAfter)
+	}
+
+	// 风暴模式在所有输出之前统一采样，开始/结束提示直接交给输出
+	st := &storm{banner: handler, keep: callerKey, exempt: exempt}
+	handler = &stormHandler{Handler: handler, storm: st}
+
+	// 变换规则最先执行，被丢弃的记录不计入风暴模式的速率
+	if len(cfg.Transforms) > 0 {
+		rules, errs := compileTransforms(cfg.Transforms)
+		for _, err := range errs {
+			fallback("%v, skipped", err)
+		}
+		if len(rules) > 0 {
+			handler = &transformHandler{Handler: handler, rules: rules, exempt: exempt}
+		}
+	}
+
+	// ctx 中的信息在变换之前附加，变换规则可以读取 trace_id 等属性
+	handler = &contextHandler{Handler: handler}
+	if len(cfg.ContextKeys) > 0 {
+		handler = &contextKeysHandler{Handler: handler, keys: cfg.ContextKeys}
+	}
+	if len(cfg.BaggageKeys) > 0 {
+		if cfg.Baggage != nil {
+			handler = &baggageHandler{Handler: handler, baggage: cfg.Baggage, keys: cfg.BaggageKeys}
+		} else {
+			fallback("BaggageKeys set without Baggage, skipped")
+		}
+	}
+	if cfg.SpanContext != nil {
+		handler = &spanHandler{Handler: handler, span: cfg.SpanContext}
+	}
+	// 缓存的记录在输出时才经过内层的处理，丢弃的记录没有额外开销
+	handler = &tailHandler{Handler: handler, exempt: exempt}
 
 	logger := &Logger{
-		Logger:     slog.New(handler),
-		handler:    handler,
-		level:      level,
-		callerSkip: 0, // 初始化时设置为0
+		Logger:      slog.New(&namedHandler{Handler: handler, base: level, levels: named}),
+		handler:     handler,
+		level:       level,
+		callerSkip:  0, // 初始化时设置为0
+		callerKey:   callerKey,
+		collision:   cfg.CallerKeyCollision,
+		callerTmpl:  defaultCallerTemplate,
+		recordIDKey: cfg.RecordIDKey,
+		sinks:       sinks,
+		meters:      meters,
+		rotation:    rotation,
+		closers:     closers,
+		rotator:     fileRotator,
+		storm:       st,
+		development: cfg.Development,
+		report:      report,
+		tail:        cfg.TailSampling,
+		access:      cfg.AccessLog,
+	}
+	if cfg.LatencySampleRate > 0 {
+		logger.latency = newLatencyHistogram(cfg.LatencySampleRate)
+	}
+	logger.named = named
+	logger.callerSource = cfg.CallerSource
+	logger.noCaller = cfg.AddCaller != nil && !*cfg.AddCaller
+	logger.stackLevel = cfg.StacktraceLevel
+	if cfg.CallerTemplate != "" {
+		logger.callerTmpl = parseCallerTemplate(cfg.CallerTemplate)
+	} else if cfg.CallerFormat != CallerFileLine {
+		logger.callerTmpl = parseCallerTemplate(cfg.CallerFormat.Template())
+	}
+	if cfg.TelemetryInterval > 0 {
+		// 先于其他输出关闭，避免关闭过程中继续写入
+		logger.closers = append([]io.Closer{logger.startTelemetry(cfg.TelemetryInterval)}, logger.closers...)
+	}
+	if cfg.LevelFile != "" {
+		logger.closers = append([]io.Closer{logger.watchLevelFile(cfg.LevelFile, cfg.LevelFileInterval)}, logger.closers...)
+	}
+
+	if cfg.ReportInit {
+		logger.logAt(context.Background(), slog.LevelInfo, "slogx initialized", 0, []any{slog.Any("init", report)})
 	}
 
+	// 信号监听在 Close 时停止，避免关闭后遗留 goroutine
+	c := make(chan os.Signal, 1)
+	levelSignals := cfg.LevelSignals
+	if levelSignals == nil {
+		levelSignals = DefaultLevelSignals()
+	}
+	var signals []os.Signal
+	for sig := range levelSignals {
+		signals = append(signals, sig)
+	}
+	if cfg.RotateSignal != nil {
+		signals = append(signals, cfg.RotateSignal)
+	}
+	if cfg.ReopenSignal != nil {
+		signals = append(signals, cfg.ReopenSignal)
+	}
+	// 没有任何信号时不能调用 Notify，否则会接收所有信号
+	if len(signals) > 0 {
+		signal.Notify(c, signals...)
+	}
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	logger.stopSignals = sync.OnceFunc(func() {
+		signal.Stop(c)
+		close(stop)
+		<-stopped
+	})
 	go func() {
-		c := make(chan os.Signal, 1)
-		signal.Notify(c, syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGUSR2)
-		for sig := range c {
-			switch sig {
-			case syscall.SIGHUP:
-				logger.level.Set(slog.LevelDebug)
-				logger.Warn("Log level changed to DEBUG")
-			case syscall.SIGUSR1:
-				logger.level.Set(slog.LevelInfo)
-				logger.Warn("Log level changed to INFO")
-			case syscall.SIGUSR2:
-				logger.level.Set(slog.LevelWarn)
-				logger.Warn("Log level changed to WARN")
+		defer close(stopped)
+		for {
+			var sig os.Signal
+			select {
+			case sig = <-c:
+			case <-stop:
+				return
+			}
+			if sig == cfg.RotateSignal {
+				if err := logger.Rotate(); err != nil {
+					logger.Error("Log file rotation failed", "error", err)
+				} else {
+					logger.Info("Log file rotated")
+				}
+				continue
+			}
+			if sig == cfg.ReopenSignal {
+				if err := logger.Reopen(); err != nil {
+					logger.Error("Log file reopen failed", "error", err)
+				} else {
+					logger.Info("Log file reopened")
+				}
+				continue
+			}
+			if level, ok := levelSignals[sig]; ok {
+				logger.SetLevel(level)
+				logger.Warn("Log level changed to " + levelString(level))
 			}
 		}
 	}()
@@ -1,14 +1,22 @@
 package log
 
 import (
+	"context"
+	"encoding/json"
 	"io"
 	"log/slog"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 )
 
 func TestCallerLocation(t *testing.T) {
+	// 替换标准输出为我们的pipe，logger 在创建时取得 os.Stdout，需要先替换
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
 	// 创建一个测试logger
 	testLogger := NewLogger(Config{
 		Level:    slog.LevelDebug,
@@ -17,11 +25,6 @@ func TestCallerLocation(t *testing.T) {
 		Stdout:   true,
 	})
 
-	// 替换标准输出为我们的pipe
-	oldStdout := os.Stdout
-	r, w, _ := os.Pipe()
-	os.Stdout = w
-
 	testLogger.Debug("test contains time filed", "time", 321)
 
 	// 测试完成后恢复标准输出
@@ -85,7 +88,220 @@ func TestCallerLocationInDifferentPackage(t *testing.T) {
 	}
 
 	// 验证行号是否正确（应该是调用 Debug 的行号）
-	if !strings.Contains(output, "log_test.go:61") { // 这里的行号应该是 Debug() 调用的实际行号
+	if !strings.Contains(output, "log_test.go:74") { // 这里的行号应该是 Debug() 调用的实际行号
 		t.Errorf("Expected log output to contain the correct line number, got: %s", output)
 	}
 }
+
+func TestCallerKeyCollision(t *testing.T) {
+	cases := []struct {
+		policy CallerCollisionPolicy
+		want   []string
+	}{
+		{RenameUserKey, []string{"source_user=billing", "source=["}},
+		{RenameCallerKey, []string{"source=billing", "source_caller=["}},
+		{KeepBoth, []string{"source=billing", "source=["}},
+	}
+
+	for _, c := range cases {
+		filename := t.TempDir() + "/test.log"
+		logger := NewLogger(Config{
+			Level:              slog.LevelDebug,
+			Format:             "text",
+			Filename:           filename,
+			CallerKeyCollision: c.policy,
+		})
+
+		logger.Info("event received", "source", "billing")
+		logger.With("source", "billing").Info("event received")
+
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("Failed to read log file: %v", err)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+			for _, want := range c.want {
+				if !strings.Contains(line, want) {
+					t.Errorf("policy %d: expected %q in line: %s", c.policy, want, line)
+				}
+			}
+		}
+	}
+}
+
+func TestCustomCallerKey(t *testing.T) {
+	filename := t.TempDir() + "/test.log"
+	logger := NewLogger(Config{
+		Level:     slog.LevelDebug,
+		Filename:  filename,
+		CallerKey: "caller",
+	})
+	logger.Info("hello", "source", "billing")
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	output := string(content)
+	if !strings.Contains(output, "caller=[") || !strings.Contains(output, "source=billing") {
+		t.Errorf("Expected custom caller key without collision, got: %s", output)
+	}
+}
+
+func TestLogAndLogAttrsCaller(t *testing.T) {
+	var buf strings.Builder
+	logger := NewLogger(Config{Level: slog.LevelDebug, Format: "json", Writer: &buf})
+
+	logger.Info("a")
+	logger.Log(context.Background(), slog.LevelInfo, "b", "k", 1)
+	logger.LogAttrs(context.Background(), slog.LevelInfo, "c", slog.Int("k", 1))
+	logger.Log(context.Background(), slog.LevelDebug-1, "hidden")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %q", buf.String())
+	}
+	source := func(line string) string {
+		i := strings.Index(line, `"source":`)
+		if i < 0 {
+			t.Fatalf("Expected caller in %s", line)
+		}
+		// 只比较文件名，各行调用的行号不同
+		return line[i : i+strings.IndexByte(line[i+len(`"source":`):], ':')]
+	}
+	if source(lines[1]) != source(lines[0]) || source(lines[2]) != source(lines[0]) {
+		t.Errorf("Expected Log/LogAttrs to report the same caller as Info, got:\n%s", buf.String())
+	}
+}
+
+func TestLevelNames(t *testing.T) {
+	names := map[slog.Level]string{slog.LevelDebug: "DBG", slog.LevelInfo: "INF", slog.LevelWarn: "WRN", slog.LevelError: "ERR"}
+	for _, format := range []string{"text", "json", "console", "logfmt"} {
+		var buf strings.Builder
+		logger := NewLogger(Config{Level: slog.LevelDebug, Format: format, Writer: &buf, LevelNames: names})
+		logger.Warn("disk almost full")
+		logger.Log(context.Background(), slog.LevelWarn+1, "custom level")
+
+		output := buf.String()
+		if !strings.Contains(output, "WRN") || strings.Contains(output, "WARN ") || strings.Contains(output, "level=WARN ") {
+			t.Errorf("%s: expected custom level name, got: %s", format, output)
+		}
+		if !strings.Contains(output, "WARN+1") {
+			t.Errorf("%s: expected default name for unmapped level, got: %s", format, output)
+		}
+	}
+}
+
+func TestOutputLevels(t *testing.T) {
+	sink := &memorySink{}
+	registerTestSink(t, "test-min-level", func(map[string]any) (Sink, error) { return sink, nil })
+	filename := t.TempDir() + "/app.log"
+	var buf strings.Builder
+	logger := NewLogger(Config{
+		Level:       slog.LevelDebug,
+		Filename:    filename,
+		Writer:      &buf,
+		WriterLevel: slog.LevelInfo,
+		Sinks:       []SinkConfig{{Type: "test-min-level", MinLevel: slog.LevelError}},
+	})
+	defer logger.Close()
+
+	logger.Debug("debug record")
+	logger.Info("info record")
+	logger.Error("error record")
+	logger.Sync()
+
+	file, _ := os.ReadFile(filename)
+	for _, msg := range []string{"debug record", "info record", "error record"} {
+		if !strings.Contains(string(file), msg) {
+			t.Errorf("Expected %q in the file, got %q", msg, file)
+		}
+	}
+	if strings.Contains(buf.String(), "debug record") || !strings.Contains(buf.String(), "info record") {
+		t.Errorf("Expected Writer to start at Info, got %q", buf.String())
+	}
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if len(sink.records) != 1 || sink.records[0].Message != "error record" {
+		t.Errorf("Expected only the error record in the sink, got %v", sink.records)
+	}
+}
+
+// contextSink 记录每次 Write 收到的 ctx 中 contextKey 的值
+type contextSink struct {
+	mu     sync.Mutex
+	values []any
+}
+
+type contextKey struct{}
+
+func (s *contextSink) Write(ctx context.Context, _ []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = append(s.values, ctx.Value(contextKey{}))
+	return nil
+}
+
+func (s *contextSink) Close() error { return nil }
+
+func TestContextMethods(t *testing.T) {
+	sink := &contextSink{}
+	registerTestSink(t, "test-context", func(map[string]any) (Sink, error) { return sink, nil })
+	var buf strings.Builder
+	logger := NewLogger(Config{Level: slog.LevelDebug, Format: "json", Writer: &buf, Sinks: []SinkConfig{{Type: "test-context"}}})
+
+	ctx := context.WithValue(context.Background(), contextKey{}, "trace-1")
+	logger.Info("plain")
+	logger.DebugContext(ctx, "debug")
+	logger.InfoContext(ctx, "info")
+	logger.WarnContext(ctx, "warn")
+	logger.ErrorContext(ctx, "error")
+
+	sink.mu.Lock()
+	values := sink.values
+	sink.mu.Unlock()
+	if len(values) != 5 || values[0] != nil {
+		t.Fatalf("Unexpected context values %v", values)
+	}
+	for _, v := range values[1:] {
+		if v != "trace-1" {
+			t.Errorf("Expected ctx to reach the sink, got %v", values)
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	file := func(line string) string {
+		var rec struct{ Source string }
+		json.Unmarshal([]byte(line), &rec)
+		return rec.Source[:strings.LastIndexByte(rec.Source, ':')]
+	}
+	for _, line := range lines[1:] {
+		if file(line) != file(lines[0]) {
+			t.Errorf("Expected context methods to report the same caller as Info, got:\n%s", buf.String())
+		}
+	}
+}
+
+func TestWithKeepsCaller(t *testing.T) {
+	var buf strings.Builder
+	logger := NewLogger(Config{Writer: &buf, Format: "json"})
+
+	logger.Info("plain")
+	logger.With("a", 1).Info("with")
+	logger.With("a", 1).With("b", 2).With("c", 3).Info("chained with")
+	logger.With("a", 1).WithCallerSkip(0, "b", 2).With("c", 3).InfoContext(context.Background(), "mixed")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 lines, got %q", buf.String())
+	}
+	for _, line := range lines {
+		var rec struct{ Source string }
+		json.Unmarshal([]byte(line), &rec)
+		if !strings.HasPrefix(rec.Source, "[log_test.go:") {
+			t.Errorf("Expected the caller in log_test.go, got %s", line)
+		}
+	}
+	if !strings.Contains(lines[2], `"a":1,"b":2,"c":3`) {
+		t.Errorf("Expected chained attrs, got %s", lines[2])
+	}
+}
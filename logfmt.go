@@ -0,0 +1,100 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strconv"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// newLogfmtHandler 创建严格的 logfmt 格式 handler。与 slog 的 text 格式相比：
+// key 中的非法字符会被替换为下划线，值在需要时使用 Go 字符串语法加引号转义，
+// 时间使用 RFC3339，分组展开为以点分隔的 key。
+func newLogfmtHandler(w io.Writer, opts *slog.HandlerOptions) *formatHandler {
+	return newFormatHandler(w, opts, func(buf *bytes.Buffer, e *entry) {
+		if e.time.Key != "" {
+			appendLogfmtPair(buf, e.time.Key, e.timeText(time.RFC3339Nano))
+		}
+		if e.level.Key != "" {
+			appendLogfmtPair(buf, e.level.Key, e.levelText())
+		}
+		if e.message.Key != "" {
+			appendLogfmtPair(buf, e.message.Key, e.message.Value.String())
+		}
+		for _, a := range e.attrs {
+			appendLogfmtAttr(buf, "", a)
+		}
+		if e.caller != "" {
+			appendLogfmtAttr(buf, "", e.callerAttr())
+		}
+		buf.WriteByte('\n')
+	})
+}
+
+func appendLogfmtAttr(buf *bytes.Buffer, prefix string, a slog.Attr) {
+	if a.Value.Kind() == slog.KindGroup {
+		for _, ga := range a.Value.Group() {
+			appendLogfmtAttr(buf, prefix+a.Key+".", ga)
+		}
+		return
+	}
+	appendLogfmtPair(buf, prefix+a.Key, valueText(a.Value))
+}
+
+func appendLogfmtPair(buf *bytes.Buffer, key, value string) {
+	if buf.Len() > 0 {
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(logfmtKey(key))
+	buf.WriteByte('=')
+	if logfmtNeedsQuote(value) {
+		buf.WriteString(strconv.Quote(value))
+	} else {
+		buf.WriteString(value)
+	}
+}
+
+// logfmtKey 将 key 中的空白、'='、'"' 和控制字符替换为下划线，空 key 输出为 "_"
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	valid := true
+	for _, r := range key {
+		if !logfmtKeyRune(r) {
+			valid = false
+			break
+		}
+	}
+	if valid {
+		return key
+	}
+	b := make([]rune, 0, len(key))
+	for _, r := range key {
+		if !logfmtKeyRune(r) {
+			r = '_'
+		}
+		b = append(b, r)
+	}
+	return string(b)
+}
+
+func logfmtKeyRune(r rune) bool {
+	return r > ' ' && r != '=' && r != '"' && r != utf8.RuneError && unicode.IsPrint(r)
+}
+
+// logfmtNeedsQuote 判断值是否需要加引号
+func logfmtNeedsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"regexp"
+	"testing"
+)
+
+func TestLogfmtFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: slog.LevelDebug, Format: "logfmt", Writer: &buf})
+	logger.Info("user login", "user name", "bob", "note", `said "hi"`, "path", `C:\tmp`,
+		"empty", "", "err", errors.New("boom"), slog.Group("http", "status", 200))
+
+	pattern := `^time=\S+ level=INFO msg="user login" user_name=bob note="said \\"hi\\"" path="C:\\\\tmp" empty="" err=boom http\.status=200 source=\[.+:\d+\]\n$`
+	if !regexp.MustCompile(pattern).MatchString(buf.String()) {
+		t.Errorf("Unexpected logfmt output: %q", buf.String())
+	}
+}
+
+func TestLogfmtKey(t *testing.T) {
+	cases := map[string]string{
+		"ok":      "ok",
+		"":        "_",
+		"a b":     "a_b",
+		"a=b":     "a_b",
+		`a"b`:     "a_b",
+		"tab\tok": "tab_ok",
+		"中文":      "中文",
+	}
+	for in, want := range cases {
+		if got := logfmtKey(in); got != want {
+			t.Errorf("logfmtKey(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
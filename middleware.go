@@ -0,0 +1,101 @@
+package log
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+)
+
+// HTTPMiddleware 使用默认 Logger 记录请求，见 Logger.HTTPMiddleware
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defaultLogger.HTTPMiddleware(next).ServeHTTP(w, r)
+	})
+}
+
+// HTTPMiddleware 返回为每个请求注入 logger 的 http.Handler：
+//
+//	http.ListenAndServe(":8080", logger.HTTPMiddleware(mux))
+//
+// 请求 ID 从 X-Request-Id 头读取，没有时使用 NewID 生成，并写入响应的 X-Request-Id 头；
+// 绑定了 request_id（上游传递了 X-Trace-Id 时还有 trace_id）的子 logger 通过 IntoContext 放入请求的 context，
+// handler 中使用 log.FromContext(r.Context()) 获取。请求结束后输出一条记录，包含 method、path、status、size 和 duration，
+// 状态码为 5xx 时级别为 Error，其他为 Info。这条记录在 net/http 的栈帧中输出，不带调用位置；
+// 设置了 Config.AccessLog 时改为以 Combined Log Format 写入访问日志。
+// 设置了 Config.TailSampling 时请求内的记录按尾部采样缓存，状态码为 5xx 时视为失败，请求结束的记录总是输出。
+func (l *Logger) HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ctx := ExtractHTTPHeaders(r.Context(), r.Header)
+		w.Header().Set(RequestIDHeader, RequestIDFromContext(ctx))
+		logger := l.With(CorrelationArgs(ctx)...)
+		ctx = IntoContext(ctx, logger)
+		done := func(bool) {}
+		if l.tail != nil {
+			ctx, done = l.TailSample(ctx, *l.tail)
+		}
+
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		done(status >= http.StatusInternalServerError)
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		if l.access != nil {
+			if err := l.access.Write(NewAccessLog(r, status, rec.size, start)); err != nil {
+				fmt.Fprintf(os.Stderr, "slogx: access log: %v\n", err)
+			}
+			return
+		}
+		logger.logAt(ctx, level, "HTTP request", 0, []any{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+			slog.Int64("size", rec.size),
+			slog.Duration("duration", time.Since(start)),
+		})
+	})
+}
+
+// statusRecorder 记录响应的状态码和字节数
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (w *statusRecorder) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+// Flush 支持流式响应
+func (w *statusRecorder) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap 让 http.ResponseController 可以访问原始的 ResponseWriter
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
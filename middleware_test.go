@@ -0,0 +1,107 @@
+package log
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	var buf strings.Builder
+	logger := NewLogger(Config{Writer: &buf, Format: "json"})
+	handler := logger.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info("handling")
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("short and stout"))
+	}))
+
+	r := httptest.NewRequest("GET", "/tea?cup=1", nil)
+	r.Header.Set(RequestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if got := w.Header().Get(RequestIDHeader); got != "req-1" {
+		t.Errorf("Expected the request ID in the response, got %q", got)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", buf.String())
+	}
+	if !strings.Contains(lines[0], `"msg":"handling"`) || !strings.Contains(lines[0], `"request_id":"req-1"`) {
+		t.Errorf("Expected the request logger in the context, got %s", lines[0])
+	}
+	for _, want := range []string{`"request_id":"req-1"`, `"method":"GET"`, `"path":"/tea"`, `"status":418`, `"size":15`, `"duration":`} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("Expected %s in %s", want, lines[1])
+		}
+	}
+
+	buf.Reset()
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if id := w.Header().Get(RequestIDHeader); id == "" || !strings.Contains(buf.String(), `"request_id":"`+id+`"`) {
+		t.Errorf("Expected a generated request ID %q, got %s", id, buf.String())
+	}
+}
+
+func TestHTTPMiddlewareRejectsRequestID(t *testing.T) {
+	var buf strings.Builder
+	logger := NewLogger(Config{Writer: &buf, Format: "json"})
+	handler := logger.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, id := range []string{strings.Repeat("a", MaxRequestIDLen+1), "evil\" injected=1", "line\nbreak", "<script>"} {
+		buf.Reset()
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header[RequestIDHeader] = []string{id}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		got := w.Header().Get(RequestIDHeader)
+		if got == id || got == "" {
+			t.Errorf("Expected %q to be replaced, got %q", id, got)
+		}
+		if !strings.Contains(buf.String(), `"request_id":"`+got+`"`) {
+			t.Errorf("Expected the generated request ID %q in %s", got, buf.String())
+		}
+	}
+
+	valid := "Req_1.a:b-" + strings.Repeat("z", MaxRequestIDLen-10)
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set(RequestIDHeader, valid)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if got := w.Header().Get(RequestIDHeader); got != valid {
+		t.Errorf("Expected %q to be kept, got %q", valid, got)
+	}
+}
+
+func TestHTTPMiddlewareNoCaller(t *testing.T) {
+	var buf strings.Builder
+	logger := NewLogger(Config{Writer: &buf, Format: "json"})
+	logger.HTTPMiddleware(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if out := buf.String(); !strings.Contains(out, `"msg":"HTTP request"`) || strings.Contains(out, `"source"`) {
+		t.Errorf("Expected a completion record without a caller, got %s", out)
+	}
+}
+
+func TestHTTPMiddlewareAccessLog(t *testing.T) {
+	var buf, access strings.Builder
+	logger := NewLogger(Config{Writer: &buf, Format: "json", AccessLog: NewAccessLogWriter(&access)})
+	handler := logger.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info("handling")
+		w.Write([]byte("hello"))
+	}))
+
+	r := httptest.NewRequest("GET", "/hello?x=1", nil)
+	r.RemoteAddr = "192.0.2.1:1234"
+	r.Header.Set("User-Agent", "curl/8.0")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	if strings.Contains(buf.String(), "HTTP request") || !strings.Contains(buf.String(), `"msg":"handling"`) {
+		t.Errorf("Expected only the handler record in the logger, got %s", buf.String())
+	}
+	line := access.String()
+	if !strings.HasPrefix(line, "192.0.2.1 - - [") || !strings.HasSuffix(line, `] "GET /hello?x=1 HTTP/1.1" 200 5 "-" "curl/8.0"`+"\n") {
+		t.Errorf("Unexpected access log line %q", line)
+	}
+}
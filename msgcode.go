@@ -0,0 +1,133 @@
+package log
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MsgCodeKey 是消息代码属性的 key
+const MsgCodeKey = "msg_code"
+
+// MsgCode 是一条已知消息的稳定代码。告警规则和面向客户的错误文档按代码而不是消息文本匹配，
+// 修改消息措辞或翻译时不会失效。
+type MsgCode struct {
+	Code        string     `json:"code"`                  // 稳定代码，例如 AUTH001，发布后不应修改
+	Message     string     `json:"message"`               // 记录的消息
+	Level       slog.Level `json:"level"`                 // 记录的级别
+	Description string     `json:"description,omitempty"` // 原因和处理方法，导出到代码表中
+}
+
+var (
+	msgCodeMu sync.RWMutex
+	msgCodes  = make(map[string]MsgCode)
+)
+
+// NewMsgCode 注册一个消息代码，通常在包级变量中定义：
+//
+//	var ErrLoginFailed = log.NewMsgCode("AUTH001", "login failed", slog.LevelWarn, "用户名或密码错误，连续失败会锁定账号")
+//
+//	log.LogCode(ctx, ErrLoginFailed, "user", name)
+//
+// 代码为空或重复注册时 panic。
+func NewMsgCode(code, message string, level slog.Level, description string) MsgCode {
+	if code == "" {
+		panic("slogx: empty message code")
+	}
+	msgCodeMu.Lock()
+	defer msgCodeMu.Unlock()
+	if _, dup := msgCodes[code]; dup {
+		panic("slogx: message code " + code + " registered twice")
+	}
+	c := MsgCode{Code: code, Message: message, Level: level, Description: description}
+	msgCodes[code] = c
+	return c
+}
+
+// LookupMsgCode 返回已注册的消息代码
+func LookupMsgCode(code string) (MsgCode, bool) {
+	msgCodeMu.RLock()
+	defer msgCodeMu.RUnlock()
+	c, ok := msgCodes[code]
+	return c, ok
+}
+
+// MsgCodes 返回按代码排序的所有已注册消息代码
+func MsgCodes() []MsgCode {
+	msgCodeMu.RLock()
+	defer msgCodeMu.RUnlock()
+	codes := make([]MsgCode, 0, len(msgCodes))
+	for _, c := range msgCodes {
+		codes = append(codes, c)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i].Code < codes[j].Code })
+	return codes
+}
+
+// Attr 返回 msg_code 属性，用于在自定义消息的记录上附加代码
+func (c MsgCode) Attr() slog.Attr {
+	return slog.String(MsgCodeKey, c.Code)
+}
+
+// LogCode 按代码的级别和消息输出一条带 msg_code 属性的记录
+func (l *Logger) LogCode(ctx context.Context, c MsgCode, args ...any) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !l.Enabled(ctx, c.Level) {
+		return
+	}
+	l.logAt(ctx, c.Level, c.Message, l.pc(), append([]any{c.Attr()}, args...))
+}
+
+// LogCode 使用默认 Logger 输出带 msg_code 属性的记录
+func LogCode(ctx context.Context, c MsgCode, args ...any) {
+	l := defaultLogger
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !l.Enabled(ctx, c.Level) {
+		return
+	}
+	l.logAt(ctx, c.Level, c.Message, l.pc(), append([]any{c.Attr()}, args...))
+}
+
+// WriteMsgCodes 导出代码表，format 为 json（默认）、csv 或 markdown，
+// 可以在构建时生成供告警规则和客户文档使用的代码表
+func WriteMsgCodes(w io.Writer, format string) error {
+	codes := MsgCodes()
+	switch format {
+	case "", "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(codes)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"code", "level", "message", "description"})
+		for _, c := range codes {
+			cw.Write([]string{c.Code, levelString(c.Level), c.Message, c.Description})
+		}
+		cw.Flush()
+		return cw.Error()
+	case "markdown", "md":
+		var b strings.Builder
+		b.WriteString("| Code | Level | Message | Description |\n|------|-------|---------|-------------|\n")
+		for _, c := range codes {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", markdownCell(c.Code), levelString(c.Level), markdownCell(c.Message), markdownCell(c.Description))
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+	return fmt.Errorf("slogx: unknown message code table format %q", format)
+}
+
+// markdownCell 转义表格单元格中的竖线和换行
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
@@ -0,0 +1,67 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+var (
+	testLoginFailed = NewMsgCode("TEST002", "login failed", slog.LevelWarn, "wrong password | locked after 5 attempts")
+	testDBDown      = NewMsgCode("TEST001", "database unavailable", slog.LevelError, "")
+)
+
+func TestLogCode(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Writer: &buf, Format: "json", Level: slog.LevelDebug})
+	logger.LogCode(context.Background(), testLoginFailed, "user", "alice")
+
+	var rec map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.Bytes(), err)
+	}
+	if rec[MsgCodeKey] != "TEST002" || rec["msg"] != "login failed" || rec["level"] != "WARN" || rec["user"] != "alice" {
+		t.Errorf("record = %v", rec)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering TEST001 twice did not panic")
+		}
+	}()
+	NewMsgCode("TEST001", "again", slog.LevelInfo, "")
+}
+
+func TestWriteMsgCodes(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteMsgCodes(&buf, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var codes []MsgCode
+	if err := json.Unmarshal(buf.Bytes(), &codes); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.Bytes(), err)
+	}
+	var got []string
+	for _, c := range codes {
+		if strings.HasPrefix(c.Code, "TEST") {
+			got = append(got, c.Code+"/"+c.Level.String())
+		}
+	}
+	if strings.Join(got, ",") != "TEST001/ERROR,TEST002/WARN" {
+		t.Errorf("exported codes = %v, want sorted TEST001, TEST002 with levels", got)
+	}
+
+	buf.Reset()
+	if err := WriteMsgCodes(&buf, "markdown"); err != nil {
+		t.Fatal(err)
+	}
+	if want := `| TEST002 | WARN | login failed | wrong password \| locked after 5 attempts |`; !strings.Contains(buf.String(), want) {
+		t.Errorf("markdown table missing %q:\n%s", want, buf.String())
+	}
+	if err := WriteMsgCodes(&buf, "xml"); err == nil {
+		t.Error("unknown format did not fail")
+	}
+}
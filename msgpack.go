@@ -0,0 +1,369 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"time"
+)
+
+// newMsgpackHandler 创建 MessagePack 二进制格式的 handler，适合日志量很大、需要节省磁盘和带宽的服务。
+// 每条记录编码为一个 map：time 使用 MessagePack 标准的 timestamp 扩展类型，level、msg、调用位置为字符串，
+// 其他属性保持原有类型，分组编码为嵌套 map。可以用 MsgpackDecoder 读回。
+func newMsgpackHandler(w io.Writer, opts *slog.HandlerOptions) *formatHandler {
+	return newFormatHandler(w, opts, func(buf *bytes.Buffer, e *entry) {
+		builtins := make([]slog.Attr, 0, 4)
+		for _, a := range []slog.Attr{e.time, e.level, e.message} {
+			if a.Key != "" {
+				builtins = append(builtins, a)
+			}
+		}
+		if e.caller != "" {
+			builtins = append(builtins, slog.String(e.callerKey, e.caller))
+		}
+		appendMsgpackMapHeader(buf, len(builtins)+len(e.attrs))
+		for _, a := range builtins {
+			appendMsgpackString(buf, a.Key)
+			if l, ok := a.Value.Any().(slog.Level); ok && a.Value.Kind() == slog.KindAny {
+				appendMsgpackString(buf, l.String())
+				continue
+			}
+			appendMsgpackValue(buf, a.Value)
+		}
+		appendMsgpackAttrs(buf, e.attrs)
+	})
+}
+
+func appendMsgpackAttrs(buf *bytes.Buffer, attrs []slog.Attr) {
+	for _, a := range attrs {
+		appendMsgpackString(buf, a.Key)
+		appendMsgpackValue(buf, a.Value)
+	}
+}
+
+func appendMsgpackValue(buf *bytes.Buffer, v slog.Value) {
+	switch v.Kind() {
+	case slog.KindString:
+		appendMsgpackString(buf, v.String())
+	case slog.KindInt64:
+		appendMsgpackInt(buf, v.Int64())
+	case slog.KindUint64:
+		appendMsgpackUint(buf, v.Uint64())
+	case slog.KindFloat64:
+		buf.WriteByte(0xcb)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(v.Float64()))
+	case slog.KindBool:
+		if v.Bool() {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case slog.KindDuration:
+		appendMsgpackInt(buf, int64(v.Duration()))
+	case slog.KindTime:
+		appendMsgpackTime(buf, v.Time())
+	case slog.KindGroup:
+		appendMsgpackMapHeader(buf, len(v.Group()))
+		appendMsgpackAttrs(buf, v.Group())
+	default:
+		switch x := v.Any().(type) {
+		case nil:
+			buf.WriteByte(0xc0)
+		case []byte:
+			appendMsgpackBinary(buf, x)
+		default:
+			appendMsgpackString(buf, valueText(v))
+		}
+	}
+}
+
+func appendMsgpackMapHeader(buf *bytes.Buffer, n int) {
+	switch {
+	case n < 16:
+		buf.WriteByte(0x80 | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xde)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdf)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+func appendMsgpackString(buf *bytes.Buffer, s string) {
+	n := len(s)
+	switch {
+	case n < 32:
+		buf.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(0xd9)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xda)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdb)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+	buf.WriteString(s)
+}
+
+func appendMsgpackBinary(buf *bytes.Buffer, b []byte) {
+	n := len(b)
+	switch {
+	case n <= math.MaxUint8:
+		buf.WriteByte(0xc4)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xc5)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xc6)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+	buf.Write(b)
+}
+
+func appendMsgpackInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0:
+		appendMsgpackUint(buf, uint64(i))
+	case i >= -32:
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt8:
+		buf.WriteByte(0xd0)
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt16:
+		buf.WriteByte(0xd1)
+		binary.Write(buf, binary.BigEndian, int16(i))
+	case i >= math.MinInt32:
+		buf.WriteByte(0xd2)
+		binary.Write(buf, binary.BigEndian, int32(i))
+	default:
+		buf.WriteByte(0xd3)
+		binary.Write(buf, binary.BigEndian, i)
+	}
+}
+
+func appendMsgpackUint(buf *bytes.Buffer, u uint64) {
+	switch {
+	case u < 128:
+		buf.WriteByte(byte(u))
+	case u <= math.MaxUint8:
+		buf.WriteByte(0xcc)
+		buf.WriteByte(byte(u))
+	case u <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		binary.Write(buf, binary.BigEndian, uint16(u))
+	case u <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		binary.Write(buf, binary.BigEndian, uint32(u))
+	default:
+		buf.WriteByte(0xcf)
+		binary.Write(buf, binary.BigEndian, u)
+	}
+}
+
+// appendMsgpackTime 使用 timestamp 96 扩展类型（ext8, type -1）编码时间
+func appendMsgpackTime(buf *bytes.Buffer, t time.Time) {
+	buf.Write([]byte{0xc7, 12, 0xff})
+	binary.Write(buf, binary.BigEndian, uint32(t.Nanosecond()))
+	binary.Write(buf, binary.BigEndian, t.Unix())
+}
+
+// MsgpackDecoder 从流中逐条读取 msgpack 格式输出的日志记录
+type MsgpackDecoder struct {
+	r *bufio.Reader
+}
+
+// NewMsgpackDecoder 创建读取 r 的解码器
+func NewMsgpackDecoder(r io.Reader) *MsgpackDecoder {
+	return &MsgpackDecoder{r: bufio.NewReader(r)}
+}
+
+// Decode 读取下一条记录。整数解码为 int64 或 uint64，时间解码为 time.Time，分组解码为 map[string]any，
+// 流结束时返回 io.EOF
+func (d *MsgpackDecoder) Decode() (map[string]any, error) {
+	if _, err := d.r.Peek(1); err != nil {
+		return nil, err
+	}
+	v, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("slogx: msgpack record is %T, not a map", v)
+	}
+	return m, nil
+}
+
+func (d *MsgpackDecoder) value() (any, error) {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return nil, noEOF(err)
+	}
+	switch {
+	case c < 0x80:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return d.mapOf(int(c & 0x0f))
+	case c&0xf0 == 0x90:
+		return d.arrayOf(int(c & 0x0f))
+	case c&0xe0 == 0xa0:
+		return d.str(int(c & 0x1f))
+	}
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.length(c - 0xc4)
+		if err != nil {
+			return nil, err
+		}
+		return d.bytes(n)
+	case 0xc7:
+		n, err := d.length(0)
+		if err != nil {
+			return nil, err
+		}
+		return d.ext(n)
+	case 0xd6:
+		return d.ext(4)
+	case 0xd7:
+		return d.ext(8)
+	case 0xca:
+		var f float32
+		err := binary.Read(d.r, binary.BigEndian, &f)
+		return float64(f), noEOF(err)
+	case 0xcb:
+		var f float64
+		err := binary.Read(d.r, binary.BigEndian, &f)
+		return f, noEOF(err)
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		return d.uint(1 << (c - 0xcc))
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		u, err := d.uint(1 << (c - 0xd0))
+		if err != nil {
+			return nil, err
+		}
+		shift := 64 - 8*(1<<(c-0xd0))
+		return int64(u<<shift) >> shift, nil
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.length(c - 0xd9)
+		if err != nil {
+			return nil, err
+		}
+		return d.str(n)
+	case 0xdc, 0xdd:
+		n, err := d.length(c - 0xdc + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.arrayOf(n)
+	case 0xde, 0xdf:
+		n, err := d.length(c - 0xde + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.mapOf(n)
+	}
+	return nil, fmt.Errorf("slogx: unsupported msgpack type 0x%02x", c)
+}
+
+// length 读取 1、2、4 字节（size 为 0、1、2）的长度
+func (d *MsgpackDecoder) length(size byte) (int, error) {
+	u, err := d.uint(1 << size)
+	return int(u), err
+}
+
+func (d *MsgpackDecoder) uint(n int) (uint64, error) {
+	b, err := d.bytes(n)
+	if err != nil {
+		return 0, err
+	}
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u, nil
+}
+
+func (d *MsgpackDecoder) bytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	_, err := io.ReadFull(d.r, b)
+	return b, noEOF(err)
+}
+
+func (d *MsgpackDecoder) str(n int) (any, error) {
+	b, err := d.bytes(n)
+	return string(b), err
+}
+
+func (d *MsgpackDecoder) arrayOf(n int) (any, error) {
+	arr := make([]any, n)
+	for i := range arr {
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		arr[i] = v
+	}
+	return arr, nil
+}
+
+func (d *MsgpackDecoder) mapOf(n int) (any, error) {
+	m := make(map[string]any, n)
+	for i := 0; i < n; i++ {
+		k, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		m[fmt.Sprint(k)] = v
+	}
+	return m, nil
+}
+
+// ext 读取扩展类型，只识别 timestamp（type -1），其他类型按原始字节返回
+func (d *MsgpackDecoder) ext(n int) (any, error) {
+	b, err := d.bytes(n + 1)
+	if err != nil {
+		return nil, err
+	}
+	typ, data := int8(b[0]), b[1:]
+	if typ != -1 {
+		return data, nil
+	}
+	switch n {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(data)), 0), nil
+	case 8:
+		v := binary.BigEndian.Uint64(data)
+		return time.Unix(int64(v&(1<<34-1)), int64(v>>34)), nil
+	case 12:
+		return time.Unix(int64(binary.BigEndian.Uint64(data[4:])), int64(binary.BigEndian.Uint32(data[:4]))), nil
+	}
+	return nil, fmt.Errorf("slogx: invalid msgpack timestamp length %d", n)
+}
+
+// noEOF 将读取中途的 io.EOF 转换为 io.ErrUnexpectedEOF
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,76 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: slog.LevelDebug, Format: "msgpack", Writer: &buf})
+	at := time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)
+	long := strings.Repeat("x", 300)
+	logger.Info("order created",
+		"id", 42, "delta", -70000, "big", uint64(1<<40), "ratio", 0.5, "ok", true,
+		"at", at, "note", long, slog.Group("user", "name", "alice"))
+	logger.Warn("second")
+
+	dec := NewMsgpackDecoder(&buf)
+	m, err := dec.Decode()
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	want := map[string]any{
+		"level": "INFO",
+		"msg":   "order created",
+		"id":    int64(42),
+		"delta": int64(-70000),
+		"big":   uint64(1 << 40),
+		"ratio": 0.5,
+		"ok":    true,
+		"note":  long,
+	}
+	for k, v := range want {
+		if m[k] != v {
+			t.Errorf("%s = %#v, want %#v", k, m[k], v)
+		}
+	}
+	if got, _ := m["at"].(time.Time); !got.Equal(at) {
+		t.Errorf("at = %v, want %v", m["at"], at)
+	}
+	if _, ok := m["time"].(time.Time); !ok {
+		t.Errorf("Expected record time, got %#v", m["time"])
+	}
+	if user, _ := m["user"].(map[string]any); user["name"] != "alice" {
+		t.Errorf("Expected nested group, got %#v", m["user"])
+	}
+	if _, ok := m["source"].(string); !ok {
+		t.Errorf("Expected caller, got %#v", m["source"])
+	}
+
+	if m, err = dec.Decode(); err != nil || m["msg"] != "second" {
+		t.Fatalf("Expected second record, got %v, %v", m, err)
+	}
+	if _, err = dec.Decode(); err != io.EOF {
+		t.Errorf("Expected io.EOF at end of stream, got %v", err)
+	}
+}
+
+func TestMsgpackTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterSink(&buf, "msgpack")
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, "hello", 0)
+	r.AddAttrs(slog.String("k", "v"))
+	if err := sink.Write(context.Background(), []Record{r}); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if _, err := NewMsgpackDecoder(bytes.NewReader(data[:len(data)-1])).Decode(); err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
@@ -0,0 +1,220 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// LoggerKey 是命名 logger 输出名称使用的属性 key
+const LoggerKey = "logger"
+
+// namedLevels 保存按名称设置的级别，没有设置的名称使用所属 Logger 的级别。
+// 每个 NewLogger 创建的 Logger 有自己的一份，由它 Named、With 派生出的 logger 共享
+type namedLevels struct {
+	mu       sync.RWMutex
+	levels   map[string]slog.Level
+	patterns []string     // levels 中含有通配符的名称，按长度从长到短排列
+	floor    atomic.Int64 // 所有已设置级别中最低的一个，没有设置时为 math.MaxInt64
+}
+
+func newNamedLevels() *namedLevels {
+	n := &namedLevels{}
+	n.floor.Store(math.MaxInt64)
+	return n
+}
+
+// Named 返回默认 Logger 的命名子 logger，见 Logger.Named
+func Named(name string) *Logger {
+	return defaultLogger.Named(name)
+}
+
+// Named 返回名为 name 的子 logger，记录带有 logger=name 属性，级别可以用 SetLevel(name, level)
+// （默认 Logger）或 Logger.SetNamedLevel 单独调整：
+//
+//	db := log.Named("db")
+//	log.SetLevel("db", slog.LevelWarn) // 只让 db 模块安静下来
+//
+// 在命名 logger 上再调用 Named 时名称以点连接，例如 db.pool；
+// db.pool 没有单独设置级别时沿用 db 的级别。
+func (l *Logger) Named(name string) *Logger {
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	nl := *l
+	nl.name = name
+	h := l.Handler()
+	if nh, ok := h.(*namedHandler); ok {
+		h = nh.Handler
+	}
+	nl.Logger = slog.New(&namedHandler{Handler: h, name: name, base: l.level, levels: l.named})
+	return &nl
+}
+
+// Name 返回 Logger 的名称，根 Logger 为空
+func (l *Logger) Name() string {
+	return l.name
+}
+
+// SetLevel 在运行时设置默认 Logger 中名为 name 的 logger（包括它的子 logger）的级别，
+// 设置后立即对已经创建的命名 logger 生效；name 为空时设置默认 Logger 的级别。
+//
+// name 可以是 path.Match 的通配模式，例如 db.* 匹配 db.pool、db.pool.conn 等所有下级名称。
+// 查找级别时名称本身的设置优先，其次是匹配的最长模式，都没有时沿用上一级名称的级别。
+// 其他 Logger 使用 Logger.SetNamedLevel。
+func SetLevel(name string, level slog.Level) {
+	if name == "" {
+		defaultLogger.level.Set(level)
+		return
+	}
+	defaultLogger.SetNamedLevel(name, level)
+}
+
+// SetNamedLevel 设置 Logger 中名为 name 的 logger 的级别，只影响由这个 Logger 派生出的命名 logger，见 SetLevel
+func (l *Logger) SetNamedLevel(name string, level slog.Level) {
+	l.named.set(name, level)
+}
+
+// ResetLevel 取消 SetLevel 为 name 设置的级别，之后沿用上一级名称或根 Logger 的级别
+func ResetLevel(name string) {
+	defaultLogger.ResetNamedLevel(name)
+}
+
+// ResetNamedLevel 取消 SetNamedLevel 为 name 设置的级别
+func (l *Logger) ResetNamedLevel(name string) {
+	l.named.reset(name)
+}
+
+// setAll 按 Config.NamedLevels 设置级别，返回名称为空或通配模式有误的错误
+func (n *namedLevels) setAll(levels map[string]slog.Level) []error {
+	var errs []error
+	for name, level := range levels {
+		if name == "" {
+			errs = append(errs, errors.New("empty logger name in NamedLevels"))
+			continue
+		}
+		if _, err := path.Match(name, ""); err != nil {
+			errs = append(errs, fmt.Errorf("logger name pattern %q: %w", name, err))
+			continue
+		}
+		n.set(name, level)
+	}
+	return errs
+}
+
+func (n *namedLevels) set(name string, level slog.Level) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.levels == nil {
+		n.levels = make(map[string]slog.Level)
+	}
+	n.levels[name] = level
+	n.updateFloor()
+}
+
+func (n *namedLevels) reset(name string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.levels, name)
+	n.updateFloor()
+}
+
+// has 返回 name 本身是否单独设置了级别
+func (n *namedLevels) has(name string) bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	_, ok := n.levels[name]
+	return ok
+}
+
+// updateFloor 重新计算最低的命名级别和通配模式列表，调用方需持有 n.mu
+func (n *namedLevels) updateFloor() {
+	floor := int64(math.MaxInt64)
+	n.patterns = n.patterns[:0]
+	for name, level := range n.levels {
+		floor = min(floor, int64(level))
+		if isNamePattern(name) {
+			n.patterns = append(n.patterns, name)
+		}
+	}
+	sort.Slice(n.patterns, func(i, j int) bool {
+		a, b := n.patterns[i], n.patterns[j]
+		return len(a) > len(b) || (len(a) == len(b) && a < b)
+	})
+	n.floor.Store(floor)
+}
+
+// isNamePattern 判断名称是否含有 path.Match 的通配符
+func isNamePattern(name string) bool {
+	return strings.ContainsAny(name, `*?[\`)
+}
+
+// level 返回 name、匹配 name 的最长模式或最近的上一级名称设置的级别
+func (n *namedLevels) level(name string) (slog.Level, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	for name != "" {
+		if level, ok := n.levels[name]; ok {
+			return level, true
+		}
+		for _, pattern := range n.patterns {
+			if ok, _ := path.Match(pattern, name); ok {
+				return n.levels[pattern], true
+			}
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return 0, false
+}
+
+// levelFloor 是各输出使用的级别：Logger 级别和所有命名级别中较低的一个，
+// 命名 logger 可以输出比根 Logger 更详细的记录，实际的过滤由 namedHandler 完成
+type levelFloor struct {
+	base  *slog.LevelVar
+	named *namedLevels
+}
+
+func (f levelFloor) Level() slog.Level {
+	level := f.base.Level()
+	if floor := f.named.floor.Load(); floor < int64(level) {
+		return slog.Level(floor)
+	}
+	return level
+}
+
+// namedHandler 是 Logger 最外层的 handler，按 logger 名称对应的级别过滤记录
+type namedHandler struct {
+	slog.Handler
+	name   string
+	base   *slog.LevelVar
+	levels *namedLevels
+}
+
+func (h *namedHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	threshold := h.base.Level()
+	if h.name != "" && h.levels.floor.Load() != math.MaxInt64 {
+		if l, ok := h.levels.level(h.name); ok {
+			threshold = l
+		}
+	}
+	return level >= threshold && h.Handler.Enabled(ctx, level)
+}
+
+func (h *namedHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &namedHandler{Handler: h.Handler.WithAttrs(attrs), name: h.name, base: h.base, levels: h.levels}
+}
+
+func (h *namedHandler) WithGroup(name string) slog.Handler {
+	return &namedHandler{Handler: h.Handler.WithGroup(name), name: h.name, base: h.base, levels: h.levels}
+}
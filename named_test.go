@@ -0,0 +1,86 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNamedLevels(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Writer: &buf, Level: slog.LevelInfo})
+	db := logger.Named("testdb")
+	pool := db.Named("pool").With("conn", 1)
+
+	logger.SetNamedLevel("testdb", slog.LevelWarn)
+	db.Info("db info")
+	pool.Info("pool info")
+	db.Warn("db warn")
+	logger.Info("root info")
+
+	logger.SetNamedLevel("testdb.pool", slog.LevelDebug)
+	pool.Debug("pool debug")
+	logger.Debug("root debug")
+
+	logger.ResetNamedLevel("testdb")
+	db.Info("db info again")
+
+	out := buf.String()
+	for _, want := range []string{"db warn", "root info", "pool debug", "logger=testdb.pool", "db info again"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "\n"); n != 4 {
+		t.Errorf("got %d records, want 4 (db info, pool info and root debug filtered):\n%s", n, out)
+	}
+	if name := pool.Name(); name != "testdb.pool" {
+		t.Errorf("Name() = %q", name)
+	}
+}
+
+func TestNamedLevelPatterns(t *testing.T) {
+	levels := map[string]slog.Level{
+		"tpat.*":       slog.LevelWarn,
+		"tpat.cache.*": slog.LevelDebug,
+		"thttp":        slog.LevelError,
+		"[bad":         slog.LevelInfo,
+	}
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Writer: &buf, Level: slog.LevelInfo, NamedLevels: levels, Quiet: true})
+
+	logger.Named("tpat").Named("db").Info("db info")
+	logger.Named("tpat.cache.redis").Debug("cache debug")
+	logger.Named("thttp").Named("api").Warn("api warn")
+	logger.Named("other").Info("other info")
+
+	out := buf.String()
+	if strings.Contains(out, "db info") || strings.Contains(out, "api warn") {
+		t.Errorf("Expected tpat.* at Warn and thttp children at Error, got %q", out)
+	}
+	if !strings.Contains(out, "cache debug") || !strings.Contains(out, "other info") {
+		t.Errorf("Expected the longest pattern and the root level to apply, got %q", out)
+	}
+	if fallbacks := logger.InitReport().Fallbacks; len(fallbacks) != 1 || !strings.Contains(fallbacks[0], "[bad") {
+		t.Errorf("Expected the bad pattern to be reported, got %v", fallbacks)
+	}
+}
+
+func TestNamedLevelsPerLogger(t *testing.T) {
+	var quiet, loud bytes.Buffer
+	a := NewLogger(Config{Writer: &quiet, Level: slog.LevelInfo, NamedLevels: map[string]slog.Level{"tdb": slog.LevelError}})
+	b := NewLogger(Config{Writer: &loud, Level: slog.LevelInfo})
+
+	a.Named("tdb").Info("a info")
+	b.Named("tdb").Info("b info")
+	if strings.Contains(quiet.String(), "a info") {
+		t.Errorf("Expected NamedLevels to silence tdb on its own logger, got %q", quiet.String())
+	}
+	if !strings.Contains(loud.String(), "b info") {
+		t.Errorf("Expected NamedLevels of another logger not to apply, got %q", loud.String())
+	}
+	if _, ok := defaultLogger.named.level("tdb"); ok {
+		t.Error("Expected NamedLevels not to change the default logger")
+	}
+}
@@ -0,0 +1,113 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"unicode"
+)
+
+// KeyNormalizer 在编码前统一属性 key 的写法，避免调用方风格不一致（userId、user_id、"User ID"）导致下游字段分裂。
+// 处理顺序：去掉首尾空白，替换非法字符，最后转为 snake_case 或小写。
+type KeyNormalizer struct {
+	SnakeCase   bool            // 驼峰转为 snake_case，例如 userID -> user_id、HTTPStatus -> http_status，隐含 Lowercase
+	Lowercase   bool            // 转为小写
+	Replacement string          // 非法字符的替换文本，默认 "_"，连续的非法字符只替换一次
+	Allowed     func(rune) bool // 合法字符，默认字母、数字、下划线和点
+	Skip        map[string]bool // 不处理的 key
+}
+
+func defaultKeyRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.'
+}
+
+// Normalize 返回规范化后的 key
+func (n *KeyNormalizer) Normalize(key string) string {
+	if n.Skip[key] {
+		return key
+	}
+	allowed := n.Allowed
+	if allowed == nil {
+		allowed = defaultKeyRune
+	}
+	repl := n.Replacement
+	if repl == "" {
+		repl = "_"
+	}
+
+	rs := []rune(strings.TrimSpace(key))
+	var b strings.Builder
+	replaced := false
+	for i, r := range rs {
+		if !allowed(r) {
+			if !replaced {
+				b.WriteString(repl)
+			}
+			replaced = true
+			continue
+		}
+		replaced = false
+		if n.SnakeCase && unicode.IsUpper(r) && i > 0 {
+			prev := rs[i-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if (unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && nextLower) && !strings.HasSuffix(b.String(), "_") {
+				b.WriteByte('_')
+			}
+		}
+		if n.SnakeCase || n.Lowercase {
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (n *KeyNormalizer) attrs(attrs []slog.Attr) []slog.Attr {
+	out := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		out[i] = n.attr(a)
+	}
+	return out
+}
+
+func (n *KeyNormalizer) attr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Key != "" {
+		a.Key = n.Normalize(a.Key)
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		a.Value = slog.GroupValue(n.attrs(a.Value.Group())...)
+	}
+	return a
+}
+
+// normalizeHandler 对记录、WithAttrs 的属性以及 WithGroup 的分组名做 key 规范化，
+// 名为 keep 的顶层属性（调用位置）保持不变，以便格式能识别它
+type normalizeHandler struct {
+	slog.Handler
+	n    *KeyNormalizer
+	keep string
+}
+
+func (h *normalizeHandler) Handle(ctx context.Context, r slog.Record) error {
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key != h.keep {
+			a = h.n.attr(a)
+		}
+		nr.AddAttrs(a)
+		return true
+	})
+	return h.Handler.Handle(ctx, nr)
+}
+
+func (h *normalizeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &normalizeHandler{Handler: h.Handler.WithAttrs(h.n.attrs(attrs)), n: h.n, keep: h.keep}
+}
+
+func (h *normalizeHandler) WithGroup(name string) slog.Handler {
+	if name != "" {
+		name = h.n.Normalize(name)
+	}
+	return &normalizeHandler{Handler: h.Handler.WithGroup(name), n: h.n, keep: h.keep}
+}
@@ -0,0 +1,52 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestKeyNormalizer(t *testing.T) {
+	n := &KeyNormalizer{SnakeCase: true}
+	cases := map[string]string{
+		"userID":        "user_id",
+		"HTTPStatus":    "http_status",
+		"  User ID ":    "user_id",
+		"User-ID":       "user_id",
+		"request.path":  "request.path",
+		"already_snake": "already_snake",
+		"a  b--c":       "a_b_c",
+	}
+	for in, want := range cases {
+		if got := n.Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	lower := &KeyNormalizer{Lowercase: true, Replacement: "-", Skip: map[string]bool{"X-Keep": true}}
+	if got := lower.Normalize("Trace ID"); got != "trace-id" {
+		t.Errorf("Normalize(%q) = %q, want %q", "Trace ID", got, "trace-id")
+	}
+	if got := lower.Normalize("X-Keep"); got != "X-Keep" {
+		t.Errorf("Expected skipped key to be unchanged, got %q", got)
+	}
+}
+
+func TestKeyNormalizerHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{
+		Level:         slog.LevelDebug,
+		Format:        "logfmt",
+		Writer:        &buf,
+		KeyNormalizer: &KeyNormalizer{SnakeCase: true},
+	})
+	logger.With("requestId", "r-1").WithGroup("HTTP").Info("done", "statusCode", 200, slog.Group("Resp", "bodySize", 12))
+
+	output := buf.String()
+	for _, want := range []string{"request_id=r-1", "http.status_code=200", "http.resp.body_size=12"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output, got: %s", want, output)
+		}
+	}
+}
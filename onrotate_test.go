@@ -0,0 +1,69 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOnRotate(t *testing.T) {
+	dir := t.TempDir()
+	rotated := make(chan string, 4)
+	logger := NewLogger(Config{
+		Filename: filepath.Join(dir, "app.log"),
+		MaxSize:  1,
+		OnRotate: func(oldPath string) { rotated <- oldPath },
+	})
+	defer logger.Close()
+
+	big := strings.Repeat("x", 600*1024)
+	logger.Info("first", "payload", big)
+	logger.Info("second", "payload", big) // 超过 1MB，触发按大小滚动
+	if err := logger.Rotate(); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case p := <-rotated:
+			if filepath.Dir(p) != dir || !strings.HasPrefix(filepath.Base(p), "app-") {
+				t.Errorf("OnRotate path = %s", p)
+			}
+			if _, err := os.Stat(p); err != nil {
+				t.Errorf("rotated file missing: %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("OnRotate called %d times, want 2", i)
+		}
+	}
+}
+
+func TestOnRotateTimePeriod(t *testing.T) {
+	current := time.Date(2024, 5, 1, 23, 59, 59, 0, time.UTC)
+	SetClock(ClockFunc(func() time.Time { return current }))
+	defer SetClock(nil)
+
+	dir := t.TempDir()
+	rotated := make(chan string, 1)
+	logger := NewLogger(Config{
+		Filename:       filepath.Join(dir, "app.log"),
+		RotateInterval: RotateDaily,
+		RotateUTC:      true,
+		OnRotate:       func(oldPath string) { rotated <- oldPath },
+	})
+	defer logger.Close()
+	logger.Info("day one")
+	current = current.Add(time.Hour)
+	logger.Info("day two")
+
+	select {
+	case p := <-rotated:
+		if want := filepath.Join(dir, "app-2024-05-01.log"); p != want {
+			t.Errorf("OnRotate path = %s, want %s", p, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnRotate not called on period change")
+	}
+}
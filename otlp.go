@@ -0,0 +1,401 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultOTLPEndpoint  = "http://localhost:4318/v1/logs"
+	DefaultOTLPBatchSize = 512
+	otlpScopeName        = "github.com/luojiego/slogx"
+)
+
+// ErrSinkOverflow 表示异步 Sink 的待发送队列已满，最旧的记录被丢弃
+var ErrSinkOverflow = errors.New("slogx: sink queue full, oldest records dropped")
+
+// OTLPOptions 是 OTLP 导出器的参数
+type OTLPOptions struct {
+	Endpoint      string            // OTLP/HTTP 日志接收地址，默认 DefaultOTLPEndpoint
+	Headers       map[string]string // 额外的请求头，例如鉴权信息
+	Resource      map[string]string // 资源属性，例如 service.name、deployment.environment
+	BatchSize     int               // 攒够多少条记录立即发送，默认 DefaultOTLPBatchSize
+	MaxPending    int               // 待发送记录的上限，采集端变慢时超出的部分从最旧的记录开始丢弃，默认 BatchSize 的 8 倍
+	FlushInterval time.Duration     // 定期发送的间隔，默认 DefaultFlushInterval
+	Timeout       time.Duration     // 单次请求超时，默认 10 秒
+	Client        *http.Client      // 自定义 HTTP 客户端
+	Receipts      BatchReceipts     // 每批记录发送成功或失败后的回调，见 receipts.go
+}
+
+// OTLPSink 将记录转换为 OpenTelemetry LogRecord，批量通过 OTLP/HTTP（JSON 编码）发送给采集端。
+// 记录中的 trace_id/span_id 属性（十六进制字符串）会映射到 LogRecord 的 traceId/spanId，实现日志与链路关联。
+type OTLPSink struct {
+	opts    OTLPOptions
+	mu      sync.Mutex
+	pending []Record
+	kick    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	lastErr error
+	batches uint64 // 已发送的批次数，用作 BatchReceipts 的批次 ID
+	dropped uint64 // 待发送队列满时丢弃的记录数
+
+	watermark time.Time // 最新一条已被采集端接收的记录的时间
+}
+
+// NewOTLPSink 创建 OTLP 导出器，后台按批量大小和时间间隔发送
+func NewOTLPSink(opts OTLPOptions) *OTLPSink {
+	if opts.Endpoint == "" {
+		opts.Endpoint = DefaultOTLPEndpoint
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultOTLPBatchSize
+	}
+	if opts.MaxPending <= 0 {
+		opts.MaxPending = 8 * opts.BatchSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = DefaultFlushInterval
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: opts.Timeout}
+	}
+	s := &OTLPSink{
+		opts: opts,
+		kick: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go s.loop()
+	return s
+}
+
+func (s *OTLPSink) Write(_ context.Context, records []Record) error {
+	s.mu.Lock()
+	for _, r := range records {
+		s.pending = append(s.pending, r.Clone())
+	}
+	// 发送跟不上时丢弃最旧的记录，待发送队列占用的内存有上限
+	over := len(s.pending) - s.opts.MaxPending
+	if over > 0 {
+		n := copy(s.pending, s.pending[over:])
+		clear(s.pending[n:])
+		s.pending = s.pending[:n]
+		s.dropped += uint64(over)
+	}
+	full := len(s.pending) >= s.opts.BatchSize
+	err := s.lastErr
+	s.mu.Unlock()
+	if full {
+		select {
+		case s.kick <- struct{}{}:
+		default:
+		}
+	}
+	if over > 0 {
+		err = errors.Join(fmt.Errorf("%w: %d records", ErrSinkOverflow, over), err)
+	}
+	// 发送是异步的，这里返回上一次发送的错误，便于调用方感知采集端异常
+	return err
+}
+
+// Dropped 返回待发送队列满时丢弃的记录总数，实现 Dropper
+func (s *OTLPSink) Dropped() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+func (s *OTLPSink) loop() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.Flush()
+		case <-s.kick:
+			s.Flush()
+		case <-s.stop:
+			s.Flush()
+			return
+		}
+	}
+}
+
+// Flush 立即发送所有待发送的记录
+func (s *OTLPSink) Flush() error {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = nil
+	if len(batch) == 0 {
+		s.mu.Unlock()
+		return nil
+	}
+	s.batches++
+	id := s.batches
+	s.mu.Unlock()
+
+	err := s.export(batch)
+	s.mu.Lock()
+	s.lastErr = err
+	if err == nil {
+		for _, r := range batch {
+			if r.Time.After(s.watermark) {
+				s.watermark = r.Time
+			}
+		}
+	}
+	s.mu.Unlock()
+	s.opts.Receipts.report(id, batch, err)
+	return err
+}
+
+// Watermark 返回最新一条已被采集端接收的记录的时间，实现 Watermarker
+func (s *OTLPSink) Watermark() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.watermark
+}
+
+func (s *OTLPSink) export(records []Record) error {
+	body, err := json.Marshal(s.payload(records))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.opts.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.opts.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("slogx: otlp export failed: %s", resp.Status)
+	}
+	return nil
+}
+
+// Close 停止后台发送并发送剩余的记录
+func (s *OTLPSink) Close() error {
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastErr
+}
+
+// 以下是 OTLP/HTTP JSON 编码使用的结构，字段名遵循 OTLP 的 proto3 JSON 映射
+
+type otlpLogsData struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano,omitempty"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+	TraceID              string         `json:"traceId,omitempty"`
+	SpanID               string         `json:"spanId,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string         `json:"stringValue,omitempty"`
+	BoolValue   *bool           `json:"boolValue,omitempty"`
+	IntValue    *string         `json:"intValue,omitempty"` // int64 在 proto3 JSON 中编码为字符串
+	DoubleValue *float64        `json:"doubleValue,omitempty"`
+	KvlistValue *otlpKeyValues  `json:"kvlistValue,omitempty"`
+	ArrayValue  *otlpArrayValue `json:"arrayValue,omitempty"`
+}
+
+type otlpKeyValues struct {
+	Values []otlpKeyValue `json:"values"`
+}
+
+type otlpArrayValue struct {
+	Values []otlpAnyValue `json:"values"`
+}
+
+func (s *OTLPSink) payload(records []Record) otlpLogsData {
+	var resource otlpResource
+	for k, v := range s.opts.Resource {
+		resource.Attributes = append(resource.Attributes, otlpKeyValue{Key: k, Value: otlpString(v)})
+	}
+	observed := strconv.FormatInt(now().UnixNano(), 10)
+	logRecords := make([]otlpLogRecord, 0, len(records))
+	for _, r := range records {
+		lr := otlpLogRecord{
+			ObservedTimeUnixNano: observed,
+			SeverityNumber:       otlpSeverity(r.Level),
+			SeverityText:         levelString(r.Level),
+			Body:                 otlpString(r.Message),
+		}
+		if !r.Time.IsZero() {
+			lr.TimeUnixNano = strconv.FormatInt(r.Time.UnixNano(), 10)
+		}
+		// 调用位置按 OTel 语义约定输出为 code.* 属性
+		if frame, ok := frameForPC(r.PC); ok {
+			line := strconv.Itoa(frame.Line)
+			lr.Attributes = append(lr.Attributes,
+				otlpKeyValue{Key: "code.filepath", Value: otlpString(frame.File)},
+				otlpKeyValue{Key: "code.lineno", Value: otlpAnyValue{IntValue: &line}},
+				otlpKeyValue{Key: "code.function", Value: otlpString(frame.Function)},
+			)
+		}
+		r.Attrs(func(a slog.Attr) bool {
+			v := a.Value.Resolve()
+			switch {
+			case a.Key == "trace_id" && isHexID(v, 16):
+				lr.TraceID = v.String()
+			case a.Key == "span_id" && isHexID(v, 8):
+				lr.SpanID = v.String()
+			default:
+				lr.Attributes = append(lr.Attributes, otlpKeyValue{Key: a.Key, Value: otlpValue(v)})
+			}
+			return true
+		})
+		logRecords = append(logRecords, lr)
+	}
+	return otlpLogsData{ResourceLogs: []otlpResourceLogs{{
+		Resource:  resource,
+		ScopeLogs: []otlpScopeLogs{{Scope: otlpScope{Name: otlpScopeName}, LogRecords: logRecords}},
+	}}}
+}
+
+// otlpSeverity 将 slog 级别映射为 OTel SeverityNumber：Debug=5, Info=9, Warn=13, Error=17
+func otlpSeverity(level slog.Level) int {
+	n := int(level) + 9
+	if n < 1 {
+		return 1
+	}
+	if n > 24 {
+		return 24
+	}
+	return n
+}
+
+// isHexID 判断值是否为 n 字节的十六进制 ID
+func isHexID(v slog.Value, n int) bool {
+	if v.Kind() != slog.KindString {
+		return false
+	}
+	b, err := hex.DecodeString(v.String())
+	return err == nil && len(b) == n
+}
+
+func otlpString(s string) otlpAnyValue {
+	return otlpAnyValue{StringValue: &s}
+}
+
+func otlpValue(v slog.Value) otlpAnyValue {
+	switch v.Kind() {
+	case slog.KindBool:
+		b := v.Bool()
+		return otlpAnyValue{BoolValue: &b}
+	case slog.KindInt64:
+		i := strconv.FormatInt(v.Int64(), 10)
+		return otlpAnyValue{IntValue: &i}
+	case slog.KindUint64:
+		i := strconv.FormatUint(v.Uint64(), 10)
+		return otlpAnyValue{IntValue: &i}
+	case slog.KindDuration:
+		i := strconv.FormatInt(int64(v.Duration()), 10)
+		return otlpAnyValue{IntValue: &i}
+	case slog.KindFloat64:
+		f := v.Float64()
+		return otlpAnyValue{DoubleValue: &f}
+	case slog.KindGroup:
+		kvs := &otlpKeyValues{}
+		for _, a := range v.Group() {
+			kvs.Values = append(kvs.Values, otlpKeyValue{Key: a.Key, Value: otlpValue(a.Value.Resolve())})
+		}
+		return otlpAnyValue{KvlistValue: kvs}
+	case slog.KindAny:
+		if items, ok := v.Any().([]any); ok {
+			arr := &otlpArrayValue{}
+			for _, item := range items {
+				arr.Values = append(arr.Values, otlpValue(slog.AnyValue(item)))
+			}
+			return otlpAnyValue{ArrayValue: arr}
+		}
+	}
+	return otlpString(valueText(v))
+}
+
+// optStringMap 从 options 中读取 map[string]string 参数，兼容 JSON 解码得到的 map[string]any
+func optStringMap(options map[string]any, key string) map[string]string {
+	switch v := options[key].(type) {
+	case map[string]string:
+		return v
+	case map[string]any:
+		m := make(map[string]string, len(v))
+		for k, val := range v {
+			m[k] = fmt.Sprint(val)
+		}
+		return m
+	}
+	return nil
+}
+
+func init() {
+	RegisterSink("otlp", func(options map[string]any) (Sink, error) {
+		return NewOTLPSink(OTLPOptions{
+			Endpoint:      optString(options, "endpoint", ""),
+			Headers:       optStringMap(options, "headers"),
+			Resource:      optStringMap(options, "resource"),
+			BatchSize:     optInt(options, "batch_size", 0),
+			MaxPending:    optInt(options, "max_pending", 0),
+			FlushInterval: optDuration(options, "flush_interval", 0),
+			Timeout:       optDuration(options, "timeout", 0),
+		}), nil
+	})
+}
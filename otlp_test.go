@@ -0,0 +1,131 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOTLPSink(t *testing.T) {
+	var mu sync.Mutex
+	var payloads []otlpLogsData
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" || r.Header.Get("X-Token") != "secret" {
+			t.Errorf("Unexpected headers: %v", r.Header)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var data otlpLogsData
+		if err := json.Unmarshal(body, &data); err != nil {
+			t.Errorf("Invalid payload %s: %v", body, err)
+		}
+		mu.Lock()
+		payloads = append(payloads, data)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	sink := NewOTLPSink(OTLPOptions{
+		Endpoint: srv.URL,
+		Headers:  map[string]string{"X-Token": "secret"},
+		Resource: map[string]string{"service.name": "checkout"},
+	})
+	registerTestSink(t, "test-otlp", func(map[string]any) (Sink, error) { return sink, nil })
+
+	logger := NewLogger(Config{Level: slog.LevelDebug, Sinks: []SinkConfig{{Type: "test-otlp"}}})
+	logger.Warn("slow request",
+		"trace_id", "4bf92f3577b34da6a3ce929d0e0e4736",
+		"span_id", "00f067aa0ba902b7",
+		"latency_ms", 1200,
+		slog.Group("http", "method", "GET"))
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(payloads) != 1 {
+		t.Fatalf("Expected 1 export request, got %d", len(payloads))
+	}
+	rl := payloads[0].ResourceLogs[0]
+	if kv := rl.Resource.Attributes[0]; kv.Key != "service.name" || *kv.Value.StringValue != "checkout" {
+		t.Errorf("Unexpected resource attributes: %+v", rl.Resource.Attributes)
+	}
+	lr := rl.ScopeLogs[0].LogRecords[0]
+	if lr.SeverityNumber != 13 || lr.SeverityText != "WARN" || *lr.Body.StringValue != "slow request" {
+		t.Errorf("Unexpected log record: %+v", lr)
+	}
+	if lr.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || lr.SpanID != "00f067aa0ba902b7" {
+		t.Errorf("Expected trace correlation, got trace=%q span=%q", lr.TraceID, lr.SpanID)
+	}
+	attrs := map[string]otlpAnyValue{}
+	for _, kv := range lr.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if v := attrs["latency_ms"]; v.IntValue == nil || *v.IntValue != "1200" {
+		t.Errorf("Expected int attribute, got %+v", v)
+	}
+	if v := attrs["http"]; v.KvlistValue == nil || v.KvlistValue.Values[0].Key != "method" {
+		t.Errorf("Expected kvlist attribute, got %+v", v)
+	}
+	if _, ok := attrs["code.lineno"]; !ok {
+		t.Errorf("Expected code.* attributes, got %v", attrs)
+	}
+}
+
+func TestOTLPSeverity(t *testing.T) {
+	cases := map[slog.Level]int{
+		slog.LevelDebug: 5,
+		slog.LevelInfo:  9,
+		slog.LevelWarn:  13,
+		slog.LevelError: 17,
+		slog.Level(-20): 1,
+		slog.Level(30):  24,
+	}
+	for level, want := range cases {
+		if got := otlpSeverity(level); got != want {
+			t.Errorf("otlpSeverity(%v) = %d, want %d", level, got, want)
+		}
+	}
+}
+
+func TestOTLPMaxPending(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data otlpLogsData
+		json.NewDecoder(r.Body).Decode(&data)
+		mu.Lock()
+		defer mu.Unlock()
+		for _, lr := range data.ResourceLogs[0].ScopeLogs[0].LogRecords {
+			bodies = append(bodies, *lr.Body.StringValue)
+		}
+	}))
+	defer srv.Close()
+
+	sink := NewOTLPSink(OTLPOptions{Endpoint: srv.URL, BatchSize: 100, MaxPending: 3, FlushInterval: time.Hour})
+	registerTestSink(t, "test-otlp-pending", func(map[string]any) (Sink, error) { return sink, nil })
+	logger := NewLogger(Config{Sinks: []SinkConfig{{Type: "test-otlp-pending"}}})
+	for i := 1; i <= 5; i++ {
+		logger.Info(fmt.Sprintf("record %d", i))
+	}
+
+	stats := logger.Stats().Sinks[0]
+	if stats.Dropped != 2 || stats.Errors != 2 || !errors.Is(stats.LastError, ErrSinkOverflow) {
+		t.Errorf("stats = %+v, want 2 dropped records reported as errors", stats)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(bodies) != "[record 3 record 4 record 5]" {
+		t.Errorf("exported %v, want the oldest records dropped", bodies)
+	}
+}
@@ -0,0 +1,84 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const (
+	defaultFileMode os.FileMode = 0644
+	defaultDirMode  os.FileMode = 0755
+)
+
+// fileMode 返回创建日志文件使用的权限
+func fileMode(cfg Config) os.FileMode {
+	if cfg.FileMode != 0 {
+		return cfg.FileMode
+	}
+	return defaultFileMode
+}
+
+// dirMode 返回创建日志目录使用的权限
+func dirMode(cfg Config) os.FileMode {
+	if cfg.DirMode != 0 {
+		return cfg.DirMode
+	}
+	return defaultDirMode
+}
+
+// setOwner 按配置修改新建文件或目录的权限和属主。
+// 显式配置的权限不受 umask 影响；FileUID/FileGID 为 0 时不修改对应的属主。
+func setOwner(cfg Config, path string, mode, configured os.FileMode) error {
+	if configured != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			return err
+		}
+	}
+	if cfg.FileUID == 0 && cfg.FileGID == 0 {
+		return nil
+	}
+	uid, gid := cfg.FileUID, cfg.FileGID
+	if uid == 0 {
+		uid = -1
+	}
+	if gid == 0 {
+		gid = -1
+	}
+	return os.Chown(path, uid, gid)
+}
+
+// createFile 以追加方式打开日志文件，只有本次新建的文件才会设置权限和属主，
+// 已存在的文件（例如其他进程创建的）保持原样
+func createFile(cfg Config, path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, fileMode(cfg))
+	if os.IsExist(err) {
+		return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, fileMode(cfg))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := setOwner(cfg, path, fileMode(cfg), cfg.FileMode); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// makeDirs 与 os.MkdirAll 相同，但对新建的每一级目录设置 DirMode 和属主
+func makeDirs(cfg Config, dir string) error {
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return nil
+	}
+	if parent := filepath.Dir(dir); parent != dir {
+		if err := makeDirs(cfg, parent); err != nil {
+			return err
+		}
+	}
+	if err := os.Mkdir(dir, dirMode(cfg)); err != nil {
+		if os.IsExist(err) {
+			return nil
+		}
+		return err
+	}
+	return setOwner(cfg, dir, dirMode(cfg), cfg.DirMode)
+}
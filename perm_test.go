@@ -0,0 +1,61 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestFileMode(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	logger := NewLogger(Config{
+		Filename: filepath.Join(dir, "app", "app.log"),
+		FileMode: 0600,
+		DirMode:  0700,
+		FileUID:  os.Getuid(),
+		FileGID:  os.Getgid(),
+		Compress: true,
+	})
+	logger.Info("first")
+	if err := logger.Rotate(); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	logger.Info("second")
+	logger.Close()
+
+	for _, d := range []string{dir, filepath.Join(dir, "app")} {
+		if info, err := os.Stat(d); err != nil || info.Mode().Perm() != 0700 {
+			t.Errorf("dir %s mode = %v, %v, want 0700", d, info.Mode().Perm(), err)
+		}
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "app", "*"))
+	if len(files) != 2 {
+		t.Fatalf("files = %v, want the active file and a compressed backup", files)
+	}
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("%s mode = %v, want 0600", f, info.Mode().Perm())
+		}
+		if st := info.Sys().(*syscall.Stat_t); int(st.Uid) != os.Getuid() || int(st.Gid) != os.Getgid() {
+			t.Errorf("%s owner = %d:%d, want %d:%d", f, st.Uid, st.Gid, os.Getuid(), os.Getgid())
+		}
+	}
+}
+
+func TestFileModeKeepsExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, nil, 0640); err != nil {
+		t.Fatal(err)
+	}
+	logger := NewLogger(Config{Filename: path, FileMode: 0600})
+	logger.Info("hello")
+	logger.Close()
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0640 {
+		t.Errorf("existing file mode = %v, want it unchanged (0640)", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,150 @@
+// Package pipelinetest 提供在内存中运行完整日志管道的测试夹具。
+//
+// New 使用与生产环境相同的 Config 构建 Logger，格式、字段映射、key 规范化、翻译、采样、
+// 属性预算和 Sink 路由都按配置生效，只是把文件、标准输出和 Config.Sinks 中的输出替换为内存，
+// 测试可以直接断言最终输出的内容：
+//
+//	func TestOrderLogs(t *testing.T) {
+//		p := pipelinetest.New(t, config.Logging()) // 业务中真实使用的配置
+//		p.Logger.Info("order created", "id", 42)
+//		if !strings.Contains(p.Output(), `"id":42`) {
+//			t.Errorf("unexpected output: %s", p.Output())
+//		}
+//	}
+package pipelinetest
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	log "github.com/luojiego/slogx"
+)
+
+// sinkType 是替代 Config.Sinks 中各 Sink 的内存 Sink 名称
+const sinkType = "pipelinetest"
+
+func init() {
+	log.RegisterSink(sinkType, func(options map[string]any) (log.Sink, error) {
+		return options[sinkType].(*memorySink), nil
+	})
+}
+
+// Pipeline 是在内存中运行的日志管道
+type Pipeline struct {
+	Logger *log.Logger // 按配置构建的 Logger
+
+	out   *syncBuffer
+	sinks []*memorySink
+}
+
+// New 按 cfg 构建 Logger，cfg.Filename、cfg.Stdout 和 cfg.Writer 的输出改为写入内存，
+// cfg.Sinks 中的每个 Sink 替换为记录收到的日志的内存 Sink，其 TimePrecision 保持不变。
+// 测试结束时自动关闭 Logger。
+func New(t testing.TB, cfg log.Config) *Pipeline {
+	t.Helper()
+	p := &Pipeline{out: &syncBuffer{}}
+	cfg.Filename = ""
+	cfg.Stdout = false
+	cfg.Writer = p.out
+
+	sinks := make([]log.SinkConfig, len(cfg.Sinks))
+	for i, sc := range cfg.Sinks {
+		ms := &memorySink{}
+		p.sinks = append(p.sinks, ms)
+		sinks[i] = log.SinkConfig{
+			Type:          sinkType,
+			Options:       map[string]any{sinkType: ms},
+			TimePrecision: sc.TimePrecision,
+		}
+	}
+	cfg.Sinks = sinks
+
+	p.Logger = log.NewLogger(cfg)
+	t.Cleanup(func() { p.Logger.Close() })
+	return p
+}
+
+// Output 返回主输出（文件或标准输出）中已写入的全部内容
+func (p *Pipeline) Output() string {
+	p.Logger.Sync()
+	return p.out.String()
+}
+
+// Lines 按行返回主输出的内容，不含末尾的空行
+func (p *Pipeline) Lines() []string {
+	out := strings.TrimSuffix(p.Output(), "\n")
+	if out == "" {
+		return nil
+	}
+	return strings.Split(out, "\n")
+}
+
+// SinkRecords 返回 cfg.Sinks[i] 收到的记录
+func (p *Pipeline) SinkRecords(i int) []log.Record {
+	return p.sinks[i].snapshot()
+}
+
+// Reset 清空已经收集的输出和记录
+func (p *Pipeline) Reset() {
+	p.Logger.Sync()
+	p.out.reset()
+	for _, s := range p.sinks {
+		s.reset()
+	}
+}
+
+// syncBuffer 是并发安全的 bytes.Buffer
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (b *syncBuffer) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Reset()
+}
+
+// memorySink 在内存中保存收到的记录
+type memorySink struct {
+	mu      sync.Mutex
+	records []log.Record
+}
+
+func (s *memorySink) Write(_ context.Context, records []log.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range records {
+		s.records = append(s.records, r.Clone())
+	}
+	return nil
+}
+
+func (s *memorySink) Close() error { return nil }
+
+func (s *memorySink) snapshot() []log.Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]log.Record(nil), s.records...)
+}
+
+func (s *memorySink) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = nil
+}
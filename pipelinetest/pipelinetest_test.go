@@ -0,0 +1,39 @@
+package pipelinetest
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	log "github.com/luojiego/slogx"
+)
+
+func TestPipeline(t *testing.T) {
+	p := New(t, log.Config{
+		Level:         slog.LevelInfo,
+		Format:        "json",
+		Filename:      "should-not-be-created.log",
+		Stdout:        true,
+		KeyNormalizer: &log.KeyNormalizer{SnakeCase: true},
+		Sinks:         []log.SinkConfig{{Type: "stdout"}},
+	})
+	p.Logger.Debug("hidden")
+	p.Logger.Info("order created", "orderID", 42)
+
+	lines := p.Lines()
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want 1: %q", len(lines), lines)
+	}
+	if !strings.Contains(lines[0], `"order_id":42`) {
+		t.Errorf("normalizer not applied: %s", lines[0])
+	}
+	records := p.SinkRecords(0)
+	if len(records) != 1 || records[0].Message != "order created" {
+		t.Errorf("sink records = %v", records)
+	}
+
+	p.Reset()
+	if p.Output() != "" || len(p.SinkRecords(0)) != 0 {
+		t.Error("Reset did not clear collected output")
+	}
+}
@@ -0,0 +1,63 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sort"
+)
+
+// newPrettyJSONHandler 创建缩进的多行 JSON handler，用于本地调试嵌套较深的结构化日志：
+//
+//	{
+//	  "time": "2024-05-01 12:00:00.000000",
+//	  "level": "INFO",
+//	  "msg": "order created",
+//	  "source": "[main.go:42]",
+//	  "order": {
+//	    "id": 42,
+//	    "items": 3
+//	  }
+//	}
+//
+// 属性（包括分组内的属性）按 key 排序。输出较大且不便于机器逐行处理，不建议在生产环境使用。
+func newPrettyJSONHandler(w io.Writer, opts *slog.HandlerOptions, timeLayout string) *formatHandler {
+	return newFormatHandler(w, opts, func(buf *bytes.Buffer, e *entry) {
+		var compact bytes.Buffer
+		builtins := make([]slog.Attr, 0, 4)
+		if e.time.Key != "" {
+			builtins = append(builtins, slog.String(e.time.Key, e.timeText(timeLayout)))
+		}
+		if e.level.Key != "" {
+			builtins = append(builtins, slog.String(e.level.Key, e.levelText()))
+		}
+		if e.message.Key != "" {
+			builtins = append(builtins, e.message)
+		}
+		if e.caller != "" {
+			builtins = append(builtins, slog.String(e.callerKey, e.caller))
+		}
+		compact.WriteByte('{')
+		appendJSONAttrs(&compact, builtins, false)
+		appendJSONAttrs(&compact, sortAttrs(e.attrs), len(builtins) > 0)
+		compact.WriteByte('}')
+		if err := json.Indent(buf, compact.Bytes(), "", "  "); err != nil {
+			buf.Write(compact.Bytes())
+		}
+		buf.WriteByte('\n')
+	})
+}
+
+// sortAttrs 返回按 key 排序的属性副本，分组内的属性同样排序
+func sortAttrs(attrs []slog.Attr) []slog.Attr {
+	sorted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		if a.Value.Kind() == slog.KindGroup {
+			a.Value = slog.GroupValue(sortAttrs(a.Value.Group())...)
+		}
+		sorted[i] = a
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+	return sorted
+}
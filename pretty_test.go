@@ -0,0 +1,33 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestPrettyJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: slog.LevelDebug, Format: "json", PrettyJSON: true, Writer: &buf})
+	logger.Info("order created", "zeta", 1, slog.Group("order", "items", 3, "id", 42), "alpha", "a")
+
+	output := buf.String()
+	var m map[string]any
+	if err := json.Unmarshal([]byte(output), &m); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", output, err)
+	}
+	if !strings.Contains(output, "\n  \"level\": \"INFO\",\n") {
+		t.Errorf("Expected indented output, got:\n%s", output)
+	}
+	order := []string{`"msg"`, `"source"`, `"alpha"`, `"order"`, `"id"`, `"items"`, `"zeta"`}
+	last := -1
+	for _, key := range order {
+		i := strings.Index(output, key)
+		if i <= last {
+			t.Errorf("Expected %s after previous keys, got:\n%s", key, output)
+		}
+		last = i
+	}
+}
@@ -0,0 +1,104 @@
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"log/slog"
+	"math"
+)
+
+// protobuf 线格式的类型
+const (
+	pbVarint  = 0
+	pbFixed64 = 1
+	pbBytes   = 2
+)
+
+// newProtobufHandler 创建 protobuf 格式的 handler，记录按 proto/record.proto 中的 Record 编码，
+// 每条记录前有 varint 长度前缀，便于自定义采集端高效解析并保证 schema 演进的兼容性
+func newProtobufHandler(w io.Writer, opts *slog.HandlerOptions) *formatHandler {
+	return newFormatHandler(w, opts, func(buf *bytes.Buffer, e *entry) {
+		var rec []byte
+		if e.time.Key != "" && !e.record.Time.IsZero() {
+			rec = pbAppendVarintField(rec, 1, uint64(e.record.Time.UnixNano()))
+		}
+		if e.level.Key != "" {
+			rec = pbAppendVarintField(rec, 2, pbZigzag(int64(e.record.Level)))
+			rec = pbAppendStringField(rec, 3, e.levelText())
+		}
+		if e.message.Key != "" {
+			rec = pbAppendStringField(rec, 4, e.message.Value.String())
+		}
+		if e.caller != "" {
+			rec = pbAppendStringField(rec, 5, e.caller)
+		}
+		for _, a := range e.attrs {
+			rec = pbAppendBytesField(rec, 6, pbAttr(a))
+		}
+		buf.Write(binary.AppendUvarint(nil, uint64(len(rec))))
+		buf.Write(rec)
+	})
+}
+
+func pbAttr(a slog.Attr) []byte {
+	b := pbAppendStringField(nil, 1, a.Key)
+	return pbAppendBytesField(b, 2, pbValue(a.Value))
+}
+
+func pbValue(v slog.Value) []byte {
+	switch v.Kind() {
+	case slog.KindString:
+		return pbAppendStringField(nil, 1, v.String())
+	case slog.KindInt64:
+		return pbAppendVarintField(nil, 2, pbZigzag(v.Int64()))
+	case slog.KindUint64:
+		return pbAppendVarintField(nil, 3, v.Uint64())
+	case slog.KindFloat64:
+		b := pbAppendTag(nil, 4, pbFixed64)
+		return binary.LittleEndian.AppendUint64(b, math.Float64bits(v.Float64()))
+	case slog.KindBool:
+		var u uint64
+		if v.Bool() {
+			u = 1
+		}
+		return pbAppendVarintField(nil, 5, u)
+	case slog.KindDuration:
+		return pbAppendVarintField(nil, 6, uint64(v.Duration()))
+	case slog.KindTime:
+		return pbAppendVarintField(nil, 7, uint64(v.Time().UnixNano()))
+	case slog.KindGroup:
+		var g []byte
+		for _, a := range v.Group() {
+			g = pbAppendBytesField(g, 1, pbAttr(a))
+		}
+		return pbAppendBytesField(nil, 8, g)
+	}
+	if b, ok := v.Any().([]byte); ok {
+		return pbAppendBytesField(nil, 9, b)
+	}
+	return pbAppendStringField(nil, 1, valueText(v))
+}
+
+func pbAppendTag(b []byte, field, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(field)<<3|uint64(wire))
+}
+
+func pbAppendVarintField(b []byte, field int, v uint64) []byte {
+	return binary.AppendUvarint(pbAppendTag(b, field, pbVarint), v)
+}
+
+func pbAppendBytesField(b []byte, field int, v []byte) []byte {
+	b = binary.AppendUvarint(pbAppendTag(b, field, pbBytes), uint64(len(v)))
+	return append(b, v...)
+}
+
+func pbAppendStringField(b []byte, field int, s string) []byte {
+	b = binary.AppendUvarint(pbAppendTag(b, field, pbBytes), uint64(len(s)))
+	return append(b, s...)
+}
+
+// pbZigzag 是 sint32/sint64 使用的 zigzag 编码
+func pbZigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
@@ -0,0 +1,104 @@
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"log/slog"
+	"math"
+	"testing"
+)
+
+// pbField 是测试中解析出的一个 protobuf 字段
+type pbField struct {
+	num    int
+	varint uint64
+	bytes  []byte
+}
+
+// pbParse 解析一条消息的全部字段，只支持编码器用到的线格式
+func pbParse(t *testing.T, b []byte) []pbField {
+	t.Helper()
+	var fields []pbField
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		b = b[n:]
+		f := pbField{num: int(tag >> 3)}
+		switch tag & 7 {
+		case pbVarint:
+			f.varint, n = binary.Uvarint(b)
+			b = b[n:]
+		case pbFixed64:
+			f.varint = binary.LittleEndian.Uint64(b)
+			b = b[8:]
+		case pbBytes:
+			l, n := binary.Uvarint(b)
+			f.bytes = b[n : n+int(l)]
+			b = b[n+int(l):]
+		default:
+			t.Fatalf("unexpected wire type %d", tag&7)
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func TestProtobufRecord(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: slog.LevelDebug, Format: "protobuf", Writer: &buf})
+	logger.Error("disk full", "free", -3, "ratio", 0.25, slog.Group("disk", "path", "/data"))
+	logger.Info("second")
+
+	data := buf.Bytes()
+	var records [][]byte
+	for len(data) > 0 {
+		l, n := binary.Uvarint(data)
+		records = append(records, data[n:n+int(l)])
+		data = data[n+int(l):]
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+
+	got := map[int]pbField{}
+	var attrs [][]byte
+	for _, f := range pbParse(t, records[0]) {
+		if f.num == 6 {
+			attrs = append(attrs, f.bytes)
+			continue
+		}
+		got[f.num] = f
+	}
+	if got[1].varint == 0 {
+		t.Error("missing time_unix_nano")
+	}
+	if lv := int64(got[2].varint>>1) ^ -int64(got[2].varint&1); lv != int64(slog.LevelError) {
+		t.Errorf("level = %d, want %d", lv, slog.LevelError)
+	}
+	if string(got[3].bytes) != "ERROR" || string(got[4].bytes) != "disk full" {
+		t.Errorf("level_text = %q, message = %q", got[3].bytes, got[4].bytes)
+	}
+	if len(got[5].bytes) == 0 {
+		t.Error("missing caller")
+	}
+	if len(attrs) != 3 {
+		t.Fatalf("got %d attrs, want 3", len(attrs))
+	}
+
+	value := func(attr []byte) (string, pbField) {
+		f := pbParse(t, attr)
+		return string(f[0].bytes), pbParse(t, f[1].bytes)[0]
+	}
+	if k, v := value(attrs[0]); k != "free" || v.num != 2 || v.varint != pbZigzag(-3) {
+		t.Errorf("free = %s %+v", k, v)
+	}
+	if k, v := value(attrs[1]); k != "ratio" || v.num != 4 || math.Float64frombits(v.varint) != 0.25 {
+		t.Errorf("ratio = %s %+v", k, v)
+	}
+	k, v := value(attrs[2])
+	if k != "disk" || v.num != 8 {
+		t.Fatalf("disk = %s %+v", k, v)
+	}
+	if gk, gv := value(pbParse(t, v.bytes)[0].bytes); gk != "path" || string(gv.bytes) != "/data" {
+		t.Errorf("disk.path = %s %q", gk, gv.bytes)
+	}
+}
@@ -0,0 +1,342 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// DefaultRedaction 是 PurgeOptions.Replacement 为空时替换主体数据的值
+const DefaultRedaction = "[REDACTED]"
+
+// PurgeOptions 控制 PurgeLogs 如何删除数据主体的信息
+type PurgeOptions struct {
+	Keys        []string // 记录属于该主体时，这些 key 的值也一起替换，例如 email、ip、name；key 可以是分组内的名称
+	Replacement string   // 替换后的值，默认 DefaultRedaction
+	SigningKey  []byte   // 签名报告的 HMAC-SHA256 密钥，不能为空
+}
+
+// PurgedFile 是 PurgeLogs 修改过的一个文件
+type PurgedFile struct {
+	Path    string
+	Records int    // 被修改的记录数
+	SHA256  string // 修改后文件内容的 SHA-256（十六进制），可用于事后核对文件未被替换
+}
+
+// PurgeReport 是删除数据主体信息的记录，可以作为处理删除请求的凭证。报告中只保存主体标识的哈希。
+type PurgeReport struct {
+	SubjectHash string // 主体标识的 SHA-256（十六进制）
+	Time        time.Time
+	Scanned     int          // 扫描的文件数
+	Files       []PurgedFile // 被修改的文件
+	Signature   string       // 对其余字段的 HMAC-SHA256 签名（十六进制）
+}
+
+// Verify 用签名密钥校验报告未被篡改
+func (r PurgeReport) Verify(key []byte) bool {
+	sig, err := hex.DecodeString(r.Signature)
+	return err == nil && hmac.Equal(sig, r.sign(key))
+}
+
+func (r PurgeReport) sign(key []byte) []byte {
+	r.Signature = ""
+	payload, _ := json.Marshal(r)
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// PurgeLogs 扫描 cfg 对应的本地日志文件：当前文件之外的按时间滚动的文件、滚动出的备份（包括 .gz、.zst）和归档目录，
+// 找出含有值等于 subject 的属性的记录（例如 user_id 或 email 的哈希），将 subject 以及 opts.Keys 的值替换后改写文件，
+// 返回签名的报告。json 和 key=value 格式的行按值精确匹配，其他格式不做修改。
+//
+// 正在写入的文件不会被改写，需要先调用 Logger.Rotate 将它滚动为备份。改写通过临时文件和改名完成，保留文件的修改时间，
+// 不影响按时间清理。
+func PurgeLogs(cfg Config, subject string, opts PurgeOptions) (PurgeReport, error) {
+	if subject == "" {
+		return PurgeReport{}, errors.New("slogx: purge subject is empty")
+	}
+	if len(opts.SigningKey) == 0 {
+		return PurgeReport{}, errors.New("slogx: purge report signing key is empty")
+	}
+	if opts.Replacement == "" {
+		opts.Replacement = DefaultRedaction
+	}
+	sum := sha256.Sum256([]byte(subject))
+	report := PurgeReport{SubjectHash: hex.EncodeToString(sum[:]), Time: now()}
+
+	active := activeFile(cfg)
+	seen := make(map[string]bool)
+	var paths []string
+	for _, pattern := range append(retentionPatterns(cfg.Filename), archivePatterns(cfg)...) {
+		matches, _ := filepath.Glob(pattern)
+		for _, path := range matches {
+			if info, err := os.Lstat(path); err != nil || !info.Mode().IsRegular() {
+				continue // 跳过 Config.Symlink 等符号链接
+			}
+			if !seen[path] && path != active {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	sort.Strings(paths)
+
+	var errs []error
+	for _, path := range paths {
+		n, digest, err := purgeFile(cfg, path, subject, opts)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		report.Scanned++
+		if n > 0 {
+			report.Files = append(report.Files, PurgedFile{Path: path, Records: n, SHA256: digest})
+		}
+	}
+	report.Signature = hex.EncodeToString(report.sign(opts.SigningKey))
+	return report, errors.Join(errs...)
+}
+
+// activeFile 返回 cfg 对应的正在写入的文件
+func activeFile(cfg Config) string {
+	if cfg.RotateInterval <= 0 {
+		return cfg.Filename
+	}
+	start, _ := (&timeRotator{cfg: cfg}).period(now())
+	return rotateFilename(cfg.Filename, cfg.RotateInterval, start)
+}
+
+// purgeFile 改写一个文件，返回被修改的记录数和改写后内容的 SHA-256
+func purgeFile(cfg Config, path, subject string, opts PurgeOptions) (int, string, error) {
+	data, err := readLogFile(cfg, path)
+	if err != nil {
+		return 0, "", err
+	}
+	var out bytes.Buffer
+	modified := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 16<<20)
+	for scanner.Scan() {
+		line, changed := purgeLine(scanner.Bytes(), subject, opts)
+		if changed {
+			modified++
+		}
+		out.Write(line)
+		out.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, "", err
+	}
+	if modified == 0 {
+		return 0, "", nil
+	}
+	encoded, err := encodeLogFile(cfg, path, out.Bytes())
+	if err != nil {
+		return 0, "", err
+	}
+	if err := replaceFile(path, encoded); err != nil {
+		return 0, "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return modified, hex.EncodeToString(sum[:]), nil
+}
+
+// purgeLine 在属于 subject 的记录中替换 subject 和 opts.Keys 的值
+func purgeLine(line []byte, subject string, opts PurgeOptions) ([]byte, bool) {
+	isJSON := bytes.HasPrefix(line, []byte("{"))
+	var spans []valueSpan
+	if isJSON {
+		spans = jsonScalars(line)
+	} else {
+		spans = textPairs(line)
+	}
+	match := false
+	for _, s := range spans {
+		if s.value == subject {
+			match = true
+			break
+		}
+	}
+	if !match {
+		return line, false
+	}
+
+	replacement := strconv.Quote(opts.Replacement)
+	if isJSON {
+		b, _ := json.Marshal(opts.Replacement)
+		replacement = string(b)
+	} else if !strings.ContainsAny(opts.Replacement, " =\"") {
+		replacement = opts.Replacement
+	}
+	var out []byte
+	last := 0
+	for _, s := range spans {
+		if s.value != subject && !purgeKey(s.key, opts.Keys) {
+			continue
+		}
+		out = append(out, line[last:s.start]...)
+		out = append(out, replacement...)
+		last = s.end
+	}
+	return append(out, line[last:]...), true
+}
+
+// purgeKey 判断 key（text 格式中可能是 group.key）是否在 keys 中
+func purgeKey(key string, keys []string) bool {
+	leaf := key[strings.LastIndexByte(key, '.')+1:]
+	for _, k := range keys {
+		if k == key || k == leaf {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonScalars 返回一行 json 中每个字符串、数字、布尔值的位置，key 为所在字段的名称，数组元素使用数组的名称
+func jsonScalars(line []byte) []valueSpan {
+	type container struct {
+		object bool
+		key    string
+	}
+	var spans []valueSpan
+	var stack []container
+	key, expectKey := "", false
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; c {
+		case '{', '[':
+			stack = append(stack, container{object: c == '{', key: key})
+			expectKey = c == '{'
+		case '}', ']':
+			if len(stack) > 0 {
+				key = stack[len(stack)-1].key
+				stack = stack[:len(stack)-1]
+			}
+		case ',':
+			expectKey = len(stack) > 0 && stack[len(stack)-1].object
+		case ':', ' ', '\t', '\r':
+		case '"':
+			end := i + 1
+			for end < len(line) && line[end] != '"' {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(line) {
+				return spans
+			}
+			end++
+			var s string
+			json.Unmarshal(line[i:end], &s)
+			if expectKey {
+				key, expectKey = s, false
+			} else {
+				spans = append(spans, valueSpan{key: key, value: s, start: i, end: end})
+			}
+			i = end - 1
+		default:
+			end := i
+			for end < len(line) && !strings.ContainsRune(",}] \t", rune(line[end])) {
+				end++
+			}
+			spans = append(spans, valueSpan{key: key, value: string(line[i:end]), start: i, end: end})
+			i = end - 1
+		}
+	}
+	return spans
+}
+
+// readLogFile 读取日志文件，.gz 和 .zst 文件先解压，zstd 使用 cfg.ZstdDictionary
+func readLogFile(cfg Config, path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch filepath.Ext(path) {
+	case ".gz":
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(r)
+	case ".zst":
+		var opts []zstd.DOption
+		if cfg.ZstdDictionary != nil {
+			opts = append(opts, zstd.WithDecoderDicts(cfg.ZstdDictionary))
+		}
+		r, err := zstd.NewReader(bytes.NewReader(data), opts...)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	}
+	return data, nil
+}
+
+// encodeLogFile 按文件扩展名重新压缩改写后的内容
+func encodeLogFile(cfg Config, path string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch filepath.Ext(path) {
+	case ".gz":
+		w = gzip.NewWriter(&buf)
+	case ".zst":
+		enc, err := zstd.NewWriter(&buf, zstdOptions(cfg.CompressLevel, cfg.ZstdDictionary)...)
+		if err != nil {
+			return nil, err
+		}
+		w = enc
+	default:
+		return data, nil
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// replaceFile 通过临时文件和改名替换 path 的内容，保留权限和修改时间
+func replaceFile(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".purge-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if err = errors.Join(err, tmp.Close()); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode()); err != nil {
+		return err
+	}
+	if err := os.Chtimes(tmp.Name(), info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
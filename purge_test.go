@@ -0,0 +1,75 @@
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPurgeLogs(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{Filename: filepath.Join(dir, "app.log"), Format: "json", Compress: true}
+	logger := NewLogger(cfg)
+	logger.Info("login", slog.Group("user", "id", "u-42", "email", "a@example.com"), "ip", "10.0.0.1")
+	logger.Info("login", slog.Group("user", "id", "u-7", "email", "b@example.com"), "ip", "10.0.0.2")
+	if err := logger.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("active", "user_id", "u-42")
+	logger.Close()
+
+	key := []byte("secret")
+	report, err := PurgeLogs(cfg, "u-42", PurgeOptions{Keys: []string{"email", "ip"}, SigningKey: key})
+	if err != nil {
+		t.Fatalf("PurgeLogs failed: %v", err)
+	}
+	if report.Scanned != 1 || len(report.Files) != 1 || report.Files[0].Records != 1 || !strings.HasSuffix(report.Files[0].Path, ".log.gz") {
+		t.Fatalf("report = %+v", report)
+	}
+
+	f, _ := os.Open(report.Files[0].Path)
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(r)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	for _, leaked := range []string{"u-42", "a@example.com", "10.0.0.1"} {
+		if strings.Contains(lines[0], leaked) {
+			t.Errorf("%s still present: %s", leaked, lines[0])
+		}
+	}
+	if !strings.Contains(lines[0], `"user":{"id":"[REDACTED]","email":"[REDACTED]"},"ip":"[REDACTED]"`) {
+		t.Errorf("redacted line = %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"id":"u-7","email":"b@example.com"},"ip":"10.0.0.2"`) {
+		t.Errorf("other subject modified: %s", lines[1])
+	}
+	if active, _ := os.ReadFile(cfg.Filename); !strings.Contains(string(active), "u-42") {
+		t.Error("active file was rewritten")
+	}
+
+	if !report.Verify(key) {
+		t.Error("report signature does not verify")
+	}
+	report.Files[0].Records = 0
+	if report.Verify(key) {
+		t.Error("tampered report verified")
+	}
+}
+
+func TestPurgeTextLine(t *testing.T) {
+	line := []byte(`time="2024-05-01 12:00:00" level=INFO msg=login user.id=42 user.name="Ann Lee" ip=10.0.0.1`)
+	got, ok := purgeLine(line, "42", PurgeOptions{Keys: []string{"name"}, Replacement: "gone"})
+	if !ok || string(got) != `time="2024-05-01 12:00:00" level=INFO msg=login user.id=gone user.name=gone ip=10.0.0.1` {
+		t.Errorf("purgeLine = %s, %v", got, ok)
+	}
+	if _, ok := purgeLine([]byte(`msg=login user.id=420`), "42", PurgeOptions{}); ok {
+		t.Error("partial value matched")
+	}
+}
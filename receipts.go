@@ -0,0 +1,31 @@
+package log
+
+// BatchReceipts 是批量发送的 Sink（例如 OTLPSink）的投递回执。批量 Sink 的 Write 只是入队，
+// 真正的发送结果在后台产生，需要端到端保证的程序可以据此对账，或把失败的记录重新输出到其他地方：
+//
+//	Receipts: log.BatchReceipts{
+//		OnFailed: func(id uint64, err error, records []log.Record) {
+//			for _, r := range records {
+//				spool.Handle(context.Background(), r) // 例如写入本地文件，采集端恢复后补发
+//			}
+//		},
+//	}
+//
+// 回调在发送所在的后台 goroutine 中同步调用，耗时的处理应自行转交给其他 goroutine。
+type BatchReceipts struct {
+	OnDelivered func(batchID uint64, count int)                   // 一批记录被接收端确认后调用
+	OnFailed    func(batchID uint64, err error, records []Record) // 一批记录发送失败后调用，records 归回调所有，Sink 不会再重试
+}
+
+// report 按发送结果调用回调，batchID 在同一个 Sink 内从 1 开始递增
+func (r BatchReceipts) report(batchID uint64, records []Record, err error) {
+	if err != nil {
+		if r.OnFailed != nil {
+			r.OnFailed(batchID, err, records)
+		}
+		return
+	}
+	if r.OnDelivered != nil {
+		r.OnDelivered(batchID, len(records))
+	}
+}
@@ -0,0 +1,63 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOTLPBatchReceipts(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	type receipt struct {
+		id    uint64
+		count int
+		err   error
+	}
+	var got []receipt
+	var failed []Record
+	sink := NewOTLPSink(OTLPOptions{
+		Endpoint:      srv.URL,
+		FlushInterval: time.Hour,
+		Receipts: BatchReceipts{
+			OnDelivered: func(id uint64, count int) { got = append(got, receipt{id: id, count: count}) },
+			OnFailed: func(id uint64, err error, records []Record) {
+				got = append(got, receipt{id: id, count: len(records), err: err})
+				failed = append(failed, records...)
+			},
+		},
+	})
+	defer sink.Close()
+
+	records := []Record{
+		slog.NewRecord(time.Now(), slog.LevelInfo, "first", 0),
+		slog.NewRecord(time.Now(), slog.LevelInfo, "second", 0),
+	}
+	sink.Write(context.Background(), records)
+	if err := sink.Flush(); err == nil {
+		t.Fatal("Flush against a failing collector succeeded")
+	}
+	// 应用重新投递失败的记录
+	sink.Write(context.Background(), failed)
+	if err := sink.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	if len(got) != 2 || got[0].id != 1 || got[0].err == nil || got[0].count != 2 ||
+		got[1].id != 2 || got[1].err != nil || got[1].count != 2 {
+		t.Errorf("receipts = %+v, want batch 1 failed and batch 2 delivered with 2 records each", got)
+	}
+	if len(failed) != 2 || failed[0].Message != "first" {
+		t.Errorf("failed records = %v", failed)
+	}
+}
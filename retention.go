@@ -0,0 +1,114 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// placeholderRe 匹配文件名模板中的 {} 占位符
+var placeholderRe = regexp.MustCompile(`\{[^}]*\}`)
+
+// retentionPatterns 返回 Config.Filename 对应的当前文件、按时间滚动的文件和它们的备份（包括压缩后的 .gz 和 .zst）的 glob 模式
+func retentionPatterns(filename string) []string {
+	if strings.Contains(filename, "{") {
+		p := placeholderRe.ReplaceAllString(filename, "*")
+		return []string{p, p + ".gz", p + ".zst"}
+	}
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+	return []string{filename, base + "-*" + ext, base + "-*" + ext + ".gz", base + "-*" + ext + ".zst"}
+}
+
+// pruneTotalSize 在总大小超过 cfg.MaxTotalSize 时从最旧的文件开始删除，直到回到上限以内。
+// active 是正在写入的文件，和最近修改的文件一样不会被删除；active 为空时只保留最近修改的文件。
+func pruneTotalSize(cfg Config, active string) {
+	type file struct {
+		path string
+		info os.FileInfo
+	}
+	seen := make(map[string]bool)
+	var files []file
+	var total int64
+	for _, pattern := range append(retentionPatterns(cfg.Filename), archivePatterns(cfg)...) {
+		matches, _ := filepath.Glob(pattern)
+		for _, path := range matches {
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			// 用 Lstat 跳过指向当前文件的符号链接（Config.Symlink），避免重复计算
+			info, err := os.Lstat(path)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			files = append(files, file{path, info})
+			total += info.Size()
+		}
+	}
+	max := int64(cfg.MaxTotalSize) * 1024 * 1024
+	if total <= max || len(files) == 0 {
+		return
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].info.ModTime().Before(files[j].info.ModTime())
+	})
+	// 最后一个是最近修改的文件
+	for _, f := range files[:len(files)-1] {
+		if total <= max {
+			return
+		}
+		if f.path == active {
+			continue
+		}
+		if os.Remove(f.path) == nil {
+			total -= f.info.Size()
+		}
+	}
+}
+
+// periodMatcher 从按时间滚动的文件名中解析出周期的开始时间，与 rotateFilename 相反
+type periodMatcher struct {
+	re      *regexp.Regexp
+	layouts []string // 模板中各时间占位符的格式，与 re 的分组一一对应
+	loc     *time.Location
+}
+
+// newPeriodMatcher 按周期文件名模板（见 periodTemplate）创建 periodMatcher，也匹配压缩后的 .gz 和 .zst
+func newPeriodMatcher(template string, loc *time.Location) periodMatcher {
+	template = filepath.ToSlash(template)
+	var expr strings.Builder
+	var layouts []string
+	expr.WriteString("^")
+	for {
+		i := strings.IndexByte(template, '{')
+		j := strings.IndexByte(template[i+1:], '}')
+		if i < 0 || j < 0 {
+			expr.WriteString(regexp.QuoteMeta(template))
+			break
+		}
+		expr.WriteString(regexp.QuoteMeta(template[:i]))
+		if name := template[i+1 : i+1+j]; name == "app" {
+			expr.WriteString(regexp.QuoteMeta(strings.TrimSuffix(getLogFileName(), ".log")))
+		} else {
+			expr.WriteString(`([^/]+?)`)
+			layouts = append(layouts, name)
+		}
+		template = template[i+j+2:]
+	}
+	expr.WriteString(`(?:\.gz|\.zst)?$`)
+	return periodMatcher{re: regexp.MustCompile(expr.String()), layouts: layouts, loc: loc}
+}
+
+// parse 返回 path 对应的周期开始时间，path 不是周期文件（例如按大小滚动出的备份）时 ok 为 false
+func (m periodMatcher) parse(path string) (time.Time, bool) {
+	match := m.re.FindStringSubmatch(filepath.ToSlash(path))
+	if match == nil || len(m.layouts) == 0 {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(strings.Join(m.layouts, "\n"), strings.Join(match[1:], "\n"), m.loc)
+	return t, err == nil
+}
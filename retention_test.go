@@ -0,0 +1,70 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPruneTotalSize(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Now().Add(-time.Hour)
+	files := []struct {
+		name string
+		size int
+	}{
+		{"app-2024-05-01T00-00-00.000.log", 400 << 10},
+		{"app-2024-05-02T00-00-00.000.log.gz", 400 << 10},
+		{"other.log", 2 << 20},
+		{"app.log", 400 << 10},
+	}
+	for i, f := range files {
+		path := filepath.Join(dir, f.name)
+		if err := os.WriteFile(path, make([]byte, f.size), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := base.Add(time.Duration(i) * time.Minute)
+		os.Chtimes(path, mtime, mtime)
+	}
+
+	cfg := Config{Filename: filepath.Join(dir, "app.log"), MaxTotalSize: 1}
+	pruneTotalSize(cfg, cfg.Filename)
+
+	var left []string
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		left = append(left, e.Name())
+	}
+	if got, want := strings.Join(left, ","), "app-2024-05-02T00-00-00.000.log.gz,app.log,other.log"; got != want {
+		t.Errorf("remaining files = %s, want %s", got, want)
+	}
+}
+
+func TestRetentionPatternsTemplate(t *testing.T) {
+	got := retentionPatterns("logs/{app}-{2006010215}.log")
+	if len(got) != 3 || got[0] != "logs/*-*.log" || got[1] != "logs/*-*.log.gz" || got[2] != "logs/*-*.log.zst" {
+		t.Errorf("retentionPatterns = %q", got)
+	}
+}
+
+func TestMaxTotalSizeOnRotate(t *testing.T) {
+	dir := t.TempDir()
+	logger := NewLogger(Config{Filename: filepath.Join(dir, "app.log"), MaxSize: 1, MaxTotalSize: 2})
+	defer logger.Close()
+	chunk := strings.Repeat("x", 300<<10)
+	for i := 0; i < 12; i++ {
+		logger.Info("chunk", "payload", chunk)
+	}
+
+	var total int64
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		info, _ := e.Info()
+		total += info.Size()
+	}
+	if total > 2<<20 {
+		t.Errorf("total size = %d bytes over %d files, want <= 2MB", total, len(entries))
+	}
+}
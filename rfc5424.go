@@ -0,0 +1,190 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultSDID 是属性所在 SD-ELEMENT 的默认 ID。32473 是 IANA 保留给文档示例的企业编号，正式部署可替换为自己的编号
+const DefaultSDID = "slogx@32473"
+
+// RFC5424Options 是 RFC5424 格式的参数，零值字段使用默认值
+type RFC5424Options struct {
+	Facility int    // syslog facility，默认 1（user）
+	AppName  string // APP-NAME，默认可执行文件名
+	Hostname string // HOSTNAME，默认 os.Hostname
+	SDID     string // 顶层属性所在 SD-ELEMENT 的 ID，默认 DefaultSDID
+}
+
+// newRFC5424Handler 创建 RFC5424 格式的 handler，属性映射为 STRUCTURED-DATA，rsyslog 等可以按结构解析：
+//
+//	<134>1 2024-05-01T12:00:00.000000Z host app 1234 E1001 [slogx@32473 user_id="123" source="[main.go:42]"][http@32473 method="GET"] user logged in
+//
+// 顶层属性放在 SDID 元素中，每个顶层分组单独成为一个元素（ID 为分组名加 SDID 的 @ 部分），嵌套分组展开为以点分隔的参数名；
+// msg_id 属性作为 MSGID 输出。
+func newRFC5424Handler(w io.Writer, opts *slog.HandlerOptions, o RFC5424Options) *formatHandler {
+	if o.Facility <= 0 {
+		o.Facility = 1
+	}
+	if o.AppName == "" {
+		o.AppName = strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
+	}
+	if o.Hostname == "" {
+		o.Hostname, _ = os.Hostname()
+	}
+	if o.SDID == "" {
+		o.SDID = DefaultSDID
+	}
+	enterprise := ""
+	if i := strings.IndexByte(o.SDID, '@'); i >= 0 {
+		enterprise = o.SDID[i:]
+	}
+	header := " " + sdHeaderField(o.Hostname, 255) + " " + sdHeaderField(o.AppName, 48) + " " + strconv.Itoa(os.Getpid()) + " "
+
+	return newFormatHandler(w, opts, func(buf *bytes.Buffer, e *entry) {
+		buf.WriteByte('<')
+		buf.WriteString(strconv.Itoa(o.Facility*8 + syslogSeverity(e.record.Level)))
+		buf.WriteString(">1 ")
+		if e.record.Time.IsZero() || e.time.Key == "" {
+			buf.WriteByte('-')
+		} else {
+			buf.WriteString(e.record.Time.Format("2006-01-02T15:04:05.000000Z07:00"))
+		}
+		buf.WriteString(header)
+
+		msgID := "-"
+		var top []slog.Attr
+		var groups []slog.Attr
+		for _, a := range e.attrs {
+			switch {
+			case a.Key == MsgIDKey && a.Value.Kind() != slog.KindGroup:
+				msgID = sdHeaderField(a.Value.String(), 32)
+			case a.Value.Kind() == slog.KindGroup:
+				groups = append(groups, a)
+			default:
+				top = append(top, a)
+			}
+		}
+		if e.caller != "" {
+			top = append(top, e.callerAttr())
+		}
+		buf.WriteString(msgID)
+		buf.WriteByte(' ')
+
+		if len(top) == 0 && len(groups) == 0 {
+			buf.WriteByte('-')
+		}
+		if len(top) > 0 {
+			appendSDElement(buf, o.SDID, top)
+		}
+		for _, g := range groups {
+			appendSDElement(buf, sdName(g.Key)+enterprise, g.Value.Group())
+		}
+		if e.message.Key != "" {
+			buf.WriteByte(' ')
+			buf.WriteString(e.message.Value.String())
+		}
+		buf.WriteByte('\n')
+	})
+}
+
+func appendSDElement(buf *bytes.Buffer, id string, attrs []slog.Attr) {
+	buf.WriteByte('[')
+	buf.WriteString(id)
+	var params func(prefix string, attrs []slog.Attr)
+	params = func(prefix string, attrs []slog.Attr) {
+		for _, a := range attrs {
+			if a.Value.Kind() == slog.KindGroup {
+				params(prefix+a.Key+".", a.Value.Group())
+				continue
+			}
+			buf.WriteByte(' ')
+			buf.WriteString(sdName(prefix + a.Key))
+			buf.WriteString(`="`)
+			for _, r := range valueText(a.Value) {
+				if r == '"' || r == '\\' || r == ']' {
+					buf.WriteByte('\\')
+				}
+				buf.WriteRune(r)
+			}
+			buf.WriteByte('"')
+		}
+	}
+	params("", attrs)
+	buf.WriteByte(']')
+}
+
+// sdName 将 SD-ID/PARAM-NAME 限制为 1-32 个可打印 ASCII 字符，且不含 '='、空格、']'、'"'
+func sdName(s string) string {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s) && len(b) < 32; i++ {
+		c := s[i]
+		if c <= ' ' || c >= 0x7f || c == '=' || c == ']' || c == '"' || c == '@' {
+			c = '_'
+		}
+		b = append(b, c)
+	}
+	if len(b) == 0 {
+		return "_"
+	}
+	return string(b)
+}
+
+// sdHeaderField 将头部字段限制为 max 个可打印 ASCII 字符，空值输出为 "-"
+func sdHeaderField(s string, max int) string {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s) && len(b) < max; i++ {
+		if c := s[i]; c > ' ' && c < 0x7f {
+			b = append(b, c)
+		}
+	}
+	if len(b) == 0 {
+		return "-"
+	}
+	return string(b)
+}
+
+// syslogSeverity 将 slog 级别映射为 syslog severity
+func syslogSeverity(level slog.Level) int {
+	switch {
+	case level < slog.LevelInfo:
+		return 7 // debug
+	case level == slog.LevelInfo:
+		return 6 // informational
+	case level < slog.LevelWarn:
+		return 5 // notice
+	case level < slog.LevelError:
+		return 4 // warning
+	case level < LevelCritical:
+		return 3 // err
+	default:
+		return 2 // crit
+	}
+}
+
+func init() {
+	RegisterSink("syslog", func(options map[string]any) (Sink, error) {
+		addr := optString(options, "address", "")
+		if addr == "" {
+			return nil, fmt.Errorf("slogx: syslog sink requires option \"address\"")
+		}
+		conn, err := net.Dial(optString(options, "network", "udp"), addr)
+		if err != nil {
+			return nil, err
+		}
+		h := newRFC5424Handler(conn, &slog.HandlerOptions{Level: slog.Level(-128)}, RFC5424Options{
+			Facility: optInt(options, "facility", 0),
+			AppName:  optString(options, "app_name", ""),
+			Hostname: optString(options, "hostname", ""),
+			SDID:     optString(options, "sd_id", ""),
+		})
+		return &writerSink{handler: h, closer: conn}, nil
+	})
+}
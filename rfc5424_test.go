@@ -0,0 +1,58 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRFC5424Format(t *testing.T) {
+	var buf bytes.Buffer
+	h := newRFC5424Handler(&buf, nil, RFC5424Options{Facility: 16, AppName: "billing", Hostname: "web-1"})
+	slog.New(h).Warn("charge failed", MsgIDKey, "E1001", "note", `say "hi" ]`, slog.Group("http", "method", "GET", slog.Group("req", "id", 7)))
+
+	pid := strconv.Itoa(os.Getpid())
+	want := `^<132>1 \d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{6}\S+ web-1 billing ` + pid +
+		` E1001 \[slogx@32473 note="say \\"hi\\" \\]"\]\[http@32473 method="GET" req.id="7"\] charge failed\n$`
+	if !regexp.MustCompile(want).MatchString(buf.String()) {
+		t.Errorf("Output %q does not match %s", buf.String(), want)
+	}
+
+	buf.Reset()
+	slog.New(h).Info("plain")
+	if !strings.Contains(buf.String(), " - - plain\n") || !strings.HasPrefix(buf.String(), "<134>1 ") {
+		t.Errorf("Expected NILVALUE for MSGID and SD, got %q", buf.String())
+	}
+}
+
+func TestSyslogSink(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("UDP is not available:", err)
+	}
+	defer pc.Close()
+
+	logger := NewLogger(Config{
+		Level: slog.LevelDebug,
+		Sinks: []SinkConfig{{Type: "syslog", Options: map[string]any{"address": pc.LocalAddr().String(), "app_name": "svc"}}},
+	})
+	defer logger.Close()
+	logger.Error("disk failure", "disk", "sda")
+
+	pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	b := make([]byte, 2048)
+	n, _, err := pc.ReadFrom(b)
+	if err != nil {
+		t.Fatalf("Failed to receive syslog datagram: %v", err)
+	}
+	msg := string(b[:n])
+	if !strings.HasPrefix(msg, "<11>1 ") || !strings.Contains(msg, ` svc `) || !strings.Contains(msg, `disk="sda"`) || !strings.HasSuffix(msg, " disk failure\n") {
+		t.Errorf("Unexpected syslog message %q", msg)
+	}
+}
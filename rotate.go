@@ -0,0 +1,345 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotator 是支持手动滚动的文件输出，rotatingFile、timeRotator 和 BufferedWriter 都实现了它
+type rotator interface {
+	Rotate() error
+}
+
+// reopener 是可以重新打开文件路径的文件输出，用于外部工具（logrotate）改名文件之后继续写入原路径
+type reopener interface {
+	Reopen() error
+}
+
+// 常用的按时间滚动周期，也可以使用任意 time.Duration，例如 6 * time.Hour
+const (
+	RotateHourly = time.Hour
+	RotateDaily  = 24 * time.Hour
+)
+
+// 未使用文件名模板时，文件名中的时间格式
+const (
+	dailyLayout  = "2006-01-02"
+	hourlyLayout = "2006-01-02T15"
+	minuteLayout = "2006-01-02T15-04"
+)
+
+// timeRotator 按时间周期切换日志文件，每个周期写入一个带时间的文件，例如按天滚动时 logs/app.log 在
+// 2024-05-01 写入 logs/app-2024-05-01.log。周期内仍按 MaxSize 滚动。
+// 结束的周期文件按 Compress 压缩，MaxBackups 和 MaxAge 既作用于同一周期内按大小滚动出的备份，
+// 也作用于按文件名模板识别出的各周期文件，删除周期文件时一并删除它的备份。
+//
+// 周期从当天零点开始对齐：每 6 小时滚动时切换点为 0、6、12、18 点；
+// 周期为整数天时从 1970-01-01 起按天数对齐。
+type timeRotator struct {
+	mu  sync.Mutex
+	cfg Config // 文件名、滚动周期和按大小滚动的参数
+
+	current     fileOutput
+	currentName string    // 当前周期的文件名
+	start       time.Time // 当前周期的开始时间
+	next        time.Time // 当前周期的结束时间
+
+	meter  *rotationMeter // 滚动统计，周期切换和周期内按大小滚动都计入，可以为 nil
+	header []byte         // 写在每个新文件开头的头记录，见 Config.StreamHeader
+
+	frozen   int           // FreezeForBackup 的嵌套次数，不为 0 时不切换周期
+	finished chan struct{} // 最近一个周期文件处理完成时关闭，各周期文件按顺序处理
+}
+
+func newTimeRotator(cfg Config) *timeRotator {
+	return &timeRotator{cfg: cfg}
+}
+
+func (r *timeRotator) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t := now(); r.current == nil || (!t.Before(r.next) && r.frozen == 0) {
+		if err := r.switchPeriod(t); err != nil {
+			return 0, err
+		}
+	}
+	return r.current.Write(p)
+}
+
+// switchPeriod 切换到 t 所在周期的文件。上一个周期的文件在后台关闭和处理，
+// 关闭时要等待它的压缩等后台任务，不能让写入方在周期切换时阻塞
+func (r *timeRotator) switchPeriod(t time.Time) error {
+	old, oldStart, oldFile := r.currentName, r.start, r.current
+	r.current = nil
+	if old != "" {
+		r.meter.rotated(t)
+	}
+	start, next := r.period(t)
+	r.currentName = rotateFilename(r.cfg.Filename, r.cfg.RotateInterval, start)
+	if oldFile != nil || r.cfg.MaxBackups > 0 || r.cfg.MaxAge > 0 {
+		prev, done, active := r.finished, make(chan struct{}), r.currentName
+		r.finished = done
+		go func() {
+			defer close(done)
+			if prev != nil {
+				<-prev
+			}
+			if oldFile != nil {
+				if err := oldFile.Close(); err != nil {
+					r.meter.failed(err)
+					fileError(r.cfg, fmt.Errorf("close %s: %w", old, err))
+				}
+				r.finish(old, oldStart)
+			}
+			r.prunePeriods(active)
+		}()
+	}
+	current := newFileOutput(r.cfg, r.currentName)
+	current.meter = r.meter
+	current.header = r.header
+	r.current = current
+	r.start, r.next = start, next
+	if link := r.cfg.Symlink; link != "" {
+		if strings.Contains(link, "{") {
+			link = rotateFilename(link, r.cfg.RotateInterval, start) // 例如 logs/{app}.log
+		}
+		if err := updateSymlink(r.cfg, link, r.currentName); err != nil {
+			fileError(r.cfg, fmt.Errorf("symlink %s: %w", r.cfg.Symlink, err))
+		}
+	}
+	if old != "" && r.cfg.MaxTotalSize > 0 {
+		pruneTotalSize(r.cfg, r.currentName)
+	}
+	return nil
+}
+
+// finish 处理结束的周期文件：压缩，移动到周期开始时间对应的归档目录，然后调用 OnRotate
+func (r *timeRotator) finish(path string, start time.Time) {
+	if r.cfg.Compress {
+		compressed, err := compressBackup(r.cfg, path)
+		if err != nil && !os.IsNotExist(err) {
+			r.meter.failed(err)
+			fileError(r.cfg, fmt.Errorf("compress %s: %w", path, err))
+		} else if err == nil {
+			path = compressed
+		}
+	}
+	if r.cfg.ArchiveDir != "" {
+		archived, err := archiveFile(r.cfg, path, start)
+		if err != nil {
+			r.meter.failed(err)
+			fileError(r.cfg, fmt.Errorf("archive %s: %w", path, err))
+		} else {
+			path = archived
+		}
+	}
+	if r.cfg.OnRotate != nil {
+		r.cfg.OnRotate(path)
+	}
+}
+
+// period 返回 t 所在周期的开始和结束时间（本地时间或 UTC）
+func (r *timeRotator) period(t time.Time) (start, end time.Time) {
+	if r.cfg.RotateUTC {
+		t = t.UTC()
+	} else {
+		t = t.Local()
+	}
+	y, m, d := t.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	interval := r.cfg.RotateInterval
+	if interval%RotateDaily == 0 {
+		// 按日期而不是时长计算，夏令时切换的那天也在零点滚动
+		days := int(interval / RotateDaily)
+		day := int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+		start = midnight.AddDate(0, 0, -(day % days))
+		return start, start.AddDate(0, 0, days)
+	}
+	elapsed := t.Sub(midnight)
+	start = midnight.Add(elapsed - elapsed%interval)
+	end = start.Add(interval)
+	if next := midnight.AddDate(0, 0, 1); end.After(next) {
+		end = next
+	}
+	return start, end
+}
+
+// Rotate 将当前周期的文件滚动为备份，之后的写入进入同一周期的新文件
+func (r *timeRotator) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current == nil {
+		// 还没有写入过，先打开当前周期的文件，上次运行留下的内容同样会被滚动
+		if err := r.switchPeriod(now()); err != nil {
+			return err
+		}
+	}
+	return r.current.Rotate()
+}
+
+// Reopen 重新打开当前周期的文件
+func (r *timeRotator) Reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current == nil {
+		return nil
+	}
+	return r.current.Reopen()
+}
+
+// Sync 将当前周期文件已写入的数据落盘
+func (r *timeRotator) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current == nil {
+		return nil
+	}
+	return r.current.Sync()
+}
+
+// prunePeriods 按 MaxBackups 和 MaxAge 删除已结束的周期文件（包括压缩和归档后的）以及它们按大小滚动出的备份，
+// 只处理早于 active（正在写入的周期文件）的周期，后台执行较晚时已经切换出的新周期不受影响
+func (r *timeRotator) prunePeriods(active string) {
+	if r.cfg.MaxBackups <= 0 && r.cfg.MaxAge <= 0 {
+		return
+	}
+	loc := time.Local
+	if r.cfg.RotateUTC {
+		loc = time.UTC
+	}
+	template := periodTemplate(r.cfg.Filename, r.cfg.RotateInterval)
+	full, base := newPeriodMatcher(template, loc), newPeriodMatcher(filepath.Base(template), loc)
+	current, _ := full.parse(active)
+
+	periods := make(map[time.Time][]string)
+	add := func(path string, start time.Time, ok bool) {
+		if ok && start.Before(current) {
+			periods[start] = append(periods[start], path)
+		}
+	}
+	p := placeholderRe.ReplaceAllString(template, "*")
+	for _, pattern := range []string{p, p + ".gz", p + ".zst"} {
+		matches, _ := filepath.Glob(pattern)
+		for _, path := range matches {
+			start, ok := full.parse(path)
+			add(path, start, ok)
+		}
+	}
+	for _, dir := range archiveDirs(r.cfg) {
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			start, ok := base.parse(e.Name())
+			add(filepath.Join(dir, e.Name()), start, ok)
+		}
+	}
+
+	starts := make([]time.Time, 0, len(periods))
+	for start := range periods {
+		starts = append(starts, start)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].After(starts[j]) })
+	var cutoff time.Time
+	if r.cfg.MaxAge > 0 {
+		cutoff = now().Add(-time.Duration(r.cfg.MaxAge) * 24 * time.Hour)
+	}
+	for i, start := range starts {
+		if (r.cfg.MaxBackups <= 0 || i < r.cfg.MaxBackups) && !start.Add(r.cfg.RotateInterval).Before(cutoff) {
+			continue
+		}
+		paths := periods[start]
+		for _, b := range newFileOutput(r.cfg, rotateFilename(r.cfg.Filename, r.cfg.RotateInterval, start)).backups() {
+			paths = append(paths, b.path)
+		}
+		for _, path := range paths {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				fileError(r.cfg, err)
+			}
+		}
+	}
+}
+
+// Close 关闭当前周期的文件，并等待之前的周期文件处理完成
+func (r *timeRotator) Close() error {
+	r.mu.Lock()
+	current, finished := r.current, r.finished
+	r.current = nil
+	r.mu.Unlock()
+	var err error
+	if current != nil {
+		err = current.Close()
+	}
+	if finished != nil {
+		<-finished
+	}
+	return err
+}
+
+// updateSymlink 将 link 原子地指向 target：先创建临时链接再改名覆盖，tail -F 始终能打开 link。
+// link 和 target 在同一目录树下时使用相对路径，移动整个日志目录后链接仍然有效。
+func updateSymlink(cfg Config, link, target string) error {
+	if rel, err := filepath.Rel(filepath.Dir(link), target); err == nil {
+		target = rel
+	}
+	if err := makeDirs(cfg, filepath.Dir(link)); err != nil {
+		return err
+	}
+	tmp := link + ".tmp"
+	os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// periodTemplate 返回周期文件名的模板：path 含有 {} 占位符时原样返回，
+// 否则在扩展名前插入按周期长度选择的时间格式，例如 logs/app.log -> logs/app-{2006-01-02}.log
+func periodTemplate(path string, interval time.Duration) string {
+	if strings.Contains(path, "{") {
+		return path
+	}
+	layout := dailyLayout
+	switch {
+	case interval%RotateDaily == 0:
+	case interval%time.Hour == 0:
+		layout = hourlyLayout
+	default:
+		layout = minuteLayout
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-{" + layout + "}" + ext
+}
+
+// rotateFilename 返回周期 start 对应的文件名。
+//
+// path 中含有 {} 占位符时作为模板：{app} 替换为可执行文件名，其他占位符按 Go 时间格式展开，
+// 例如 logs/{app}-{2006010215}.log。否则在扩展名前插入周期的时间，
+// 例如 logs/app.log -> logs/app-2024-05-01.log（按天）或 logs/app-2024-05-01T15.log（按小时）。
+func rotateFilename(path string, interval time.Duration, start time.Time) string {
+	path = periodTemplate(path, interval)
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(path, '{')
+		j := strings.IndexByte(path[i+1:], '}')
+		if i < 0 || j < 0 {
+			b.WriteString(path)
+			return b.String()
+		}
+		b.WriteString(path[:i])
+		name := path[i+1 : i+1+j]
+		if name == "app" {
+			b.WriteString(strings.TrimSuffix(getLogFileName(), ".log"))
+		} else {
+			b.WriteString(start.Format(name))
+		}
+		path = path[i+j+2:]
+	}
+}
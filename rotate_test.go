@@ -0,0 +1,245 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestDailyRotation(t *testing.T) {
+	current := time.Date(2024, 5, 1, 23, 59, 59, 0, time.UTC)
+	SetClock(ClockFunc(func() time.Time { return current }))
+	defer SetClock(nil)
+
+	dir := t.TempDir()
+	logger := NewLogger(Config{
+		Format:         "text",
+		Filename:       filepath.Join(dir, "app.log"),
+		RotateInterval: RotateDaily,
+		RotateUTC:      true,
+	})
+	logger.Info("before midnight")
+	current = current.Add(2 * time.Second)
+	logger.Info("after midnight")
+	logger.Close()
+
+	for file, msg := range map[string]string{
+		"app-2024-05-01.log": "before midnight",
+		"app-2024-05-02.log": "after midnight",
+	} {
+		b, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		if !strings.Contains(string(b), msg) || strings.Count(string(b), "\n") != 1 {
+			t.Errorf("%s = %q, want only %q", file, b, msg)
+		}
+	}
+}
+
+func TestRotatePeriod(t *testing.T) {
+	r := newTimeRotator(Config{RotateInterval: 6 * time.Hour, RotateUTC: true})
+	start, end := r.period(time.Date(2024, 5, 1, 13, 30, 0, 0, time.UTC))
+	if want := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC); !start.Equal(want) || !end.Equal(want.Add(6*time.Hour)) {
+		t.Errorf("period = %v - %v, want 12:00 - 18:00", start, end)
+	}
+
+	r = newTimeRotator(Config{RotateInterval: RotateDaily})
+	start, _ = r.period(time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC))
+	if start.Hour() != 0 || start.Minute() != 0 || start.Location() != time.Local {
+		t.Errorf("daily period start = %v, want local midnight", start)
+	}
+}
+
+func TestRotateFilename(t *testing.T) {
+	start := time.Date(2024, 5, 1, 15, 0, 0, 0, time.UTC)
+	app := strings.TrimSuffix(getLogFileName(), ".log")
+	for _, tt := range []struct {
+		path     string
+		interval time.Duration
+		want     string
+	}{
+		{"logs/app.log", RotateDaily, "logs/app-2024-05-01.log"},
+		{"logs/app.log", RotateHourly, "logs/app-2024-05-01T15.log"},
+		{"logs/{app}-{2006010215}.log", 6 * time.Hour, "logs/" + app + "-2024050115.log"},
+		{"logs/{2006}/{01-02}.log", RotateDaily, "logs/2024/05-01.log"},
+	} {
+		if got := rotateFilename(tt.path, tt.interval, start); got != tt.want {
+			t.Errorf("rotateFilename(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRotate(t *testing.T) {
+	dir := t.TempDir()
+	logger := NewLogger(Config{Filename: filepath.Join(dir, "app.log"), BufferSize: 4096, RotateSignal: syscall.SIGWINCH})
+	defer logger.Close()
+	logger.Info("first")
+	if err := logger.Rotate(); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	logger.Info("second")
+
+	// 信号监听在后台 goroutine 中注册，重复发送直到滚动发生
+	deadline := time.Now().Add(time.Second)
+	for {
+		syscall.Kill(os.Getpid(), syscall.SIGWINCH)
+		time.Sleep(10 * time.Millisecond)
+		logger.Sync()
+		if files, _ := os.ReadDir(dir); len(files) >= 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("RotateSignal did not rotate the file")
+		}
+	}
+	b, _ := os.ReadFile(filepath.Join(dir, "app.log"))
+	if strings.Contains(string(b), "first") || strings.Contains(string(b), "second") {
+		t.Errorf("active file still has rotated records: %s", b)
+	}
+}
+
+func TestRotateSymlink(t *testing.T) {
+	current := time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC)
+	SetClock(ClockFunc(func() time.Time { return current }))
+	defer SetClock(nil)
+
+	dir := t.TempDir()
+	link := filepath.Join(dir, "app.log")
+	logger := NewLogger(Config{
+		Filename:       filepath.Join(dir, "{2006-01-02}", "app.log"),
+		RotateInterval: RotateDaily,
+		RotateUTC:      true,
+		Symlink:        link,
+		MaxTotalSize:   10,
+	})
+	defer logger.Close()
+
+	for _, msg := range []string{"day one", "day two"} {
+		logger.Info(msg)
+		target, err := os.Readlink(link)
+		if err != nil {
+			t.Fatalf("Readlink failed: %v", err)
+		}
+		if want := filepath.Join(current.Format("2006-01-02"), "app.log"); target != want {
+			t.Errorf("symlink -> %s, want %s", target, want)
+		}
+		if b, _ := os.ReadFile(link); !strings.Contains(string(b), msg) {
+			t.Errorf("reading through the symlink = %q, want %q", b, msg)
+		}
+		current = current.Add(time.Hour)
+	}
+}
+
+func TestRotateOnStart(t *testing.T) {
+	current := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	SetClock(ClockFunc(func() time.Time { return current }))
+	defer SetClock(nil)
+
+	for _, interval := range []time.Duration{0, RotateDaily} {
+		dir := t.TempDir()
+		cfg := Config{Filename: filepath.Join(dir, "app.log"), RotateInterval: interval, RotateUTC: true, RotateOnStart: true}
+		active := activeFile(cfg)
+		if err := os.WriteFile(active, []byte("previous run\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		logger := NewLogger(cfg)
+		logger.Info("this run")
+		logger.Close()
+
+		b, _ := os.ReadFile(active)
+		if strings.Contains(string(b), "previous run") || !strings.Contains(string(b), "this run") {
+			t.Errorf("interval %v: active file = %q, want only this run", interval, b)
+		}
+		if files, _ := os.ReadDir(dir); len(files) != 2 {
+			t.Errorf("interval %v: got %d files, want active file and one backup", interval, len(files))
+		}
+	}
+}
+
+// stepClock 是可以在测试中推进的时钟，后台任务也会读取它
+type stepClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *stepClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *stepClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+func TestRotatePeriodRetention(t *testing.T) {
+	clock := &stepClock{t: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	SetClock(clock)
+	defer SetClock(nil)
+
+	dir := t.TempDir()
+	// 上次运行留下的超过 MaxAge 的周期文件，以及一个周期内按大小滚动出的备份
+	for _, name := range []string{"app-2024-04-01.log.gz", "app-2024-05-01-2024-05-01T10-00-00.000.log"} {
+		os.WriteFile(filepath.Join(dir, name), []byte("old\n"), 0o644)
+	}
+	logger := NewLogger(Config{
+		Filename:       filepath.Join(dir, "app.log"),
+		RotateInterval: RotateDaily,
+		RotateUTC:      true,
+		Compress:       true,
+		MaxBackups:     2,
+		MaxAge:         7,
+	})
+	for day := 0; day < 5; day++ {
+		logger.Info("day")
+		clock.advance(24 * time.Hour)
+	}
+	logger.Close()
+
+	var got []string
+	files, _ := os.ReadDir(dir)
+	for _, f := range files {
+		got = append(got, f.Name())
+	}
+	want := []string{"app-2024-05-03.log.gz", "app-2024-05-04.log.gz", "app-2024-05-05.log"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
+
+func TestRotatePeriodSwitchDoesNotBlock(t *testing.T) {
+	clock := &stepClock{t: time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC)}
+	SetClock(clock)
+	defer SetClock(nil)
+
+	release := make(chan struct{})
+	logger := NewLogger(Config{
+		Filename:       filepath.Join(t.TempDir(), "app.log"),
+		RotateInterval: RotateDaily,
+		RotateUTC:      true,
+		OnRotate:       func(string) { <-release },
+	})
+	logger.Info("day one")
+	logger.Rotate() // OnRotate 在后台任务中阻塞，关闭周期文件时需要等待它
+	clock.advance(2 * time.Hour)
+
+	written := make(chan struct{})
+	go func() {
+		logger.Info("day two")
+		close(written)
+	}()
+	select {
+	case <-written:
+	case <-time.After(time.Second):
+		t.Error("Write blocked on the previous period's background tasks")
+	}
+	close(release)
+	logger.Close()
+}
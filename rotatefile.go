@@ -0,0 +1,426 @@
+package log
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// backupTimeFormat 是备份文件名中的时间格式（UTC），与 lumberjack 相同，升级前产生的备份仍能被识别和清理
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// defaultMaxSize 是 MaxSize 为 0 时单个文件的大小上限 (MB)
+const defaultMaxSize = 100
+
+// FsyncPolicy 决定文件输出何时调用 fsync 落盘
+type FsyncPolicy int
+
+const (
+	FsyncNever    FsyncPolicy = iota // 只在 Sync 时落盘，由操作系统决定何时写入磁盘
+	FsyncOnRotate                    // 滚动前落盘，保证备份文件完整
+	FsyncAlways                      // 每次写入后落盘，最安全也最慢
+)
+
+// fileOutput 是可以手动滚动和重新打开的文件输出
+type fileOutput interface {
+	io.WriteCloser
+	rotator
+	reopener
+	Sync() error
+}
+
+// rotatingFile 是按大小滚动的日志文件：
+//
+//   - 超过 MaxSize 时将当前文件改名为 name-<时间戳>.ext 并打开新文件
+//   - 后台压缩备份（gzip 或 zstd），按 MaxBackups、MaxAge、MaxTotalSize 清理，最后调用 OnRotate
+//   - 以 O_APPEND 打开，每条记录一次 write，多个进程写同一个文件时记录不会交错；
+//     某个进程滚动后，其他进程在下次检查时发现路径已指向新文件，会重新打开而不是再次滚动
+//   - 打开、改名、写入的错误由 Write 返回，后台压缩和清理的错误交给 Config.OnFileError
+type rotatingFile struct {
+	mu       sync.Mutex
+	cfg      Config
+	filename string
+	max      int64
+
+	file   *os.File
+	size   int64
+	closed bool // 已调用 Close，与还没有打开文件（file 为 nil）区分
+
+	meter  *rotationMeter // 滚动统计，可以为 nil
+	header []byte         // 写在每个新文件开头的头记录，见 Config.StreamHeader
+
+	mill    sync.Mutex     // 保护 tasks 和 idle
+	tasks   []func()       // 按顺序执行的后台压缩和清理任务
+	idle    chan struct{}  // 执行 tasks 的 goroutine 退出时关闭，没有 goroutine 时为 nil
+	pending sync.WaitGroup // 后台任务，Close 时等待
+
+	frozen    atomic.Int32 // FreezeForBackup 的嵌套次数，不为 0 时暂停滚动和后台任务
+	rotateDue bool         // 冻结期间调用了 Rotate，解冻后滚动，受 mu 保护
+}
+
+// newFileOutput 创建写入 filename 的按大小滚动的文件输出，文件在第一次写入时打开
+func newFileOutput(cfg Config, filename string) *rotatingFile {
+	size := cfg.MaxSize
+	if size <= 0 {
+		size = defaultMaxSize
+	}
+	return &rotatingFile{cfg: cfg, filename: filename, max: int64(size) * 1024 * 1024}
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+		f.background(f.cleanup)
+	}
+	rotated := false
+	if f.size > 0 && f.size+int64(len(p)) > f.max && f.frozen.Load() == 0 {
+		var err error
+		if rotated, err = f.rotateIfOwned(int64(len(p))); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	f.meter.written(n)
+	if err == nil && f.cfg.FsyncPolicy == FsyncAlways {
+		err = f.file.Sync()
+	}
+	if rotated && !f.cfg.Compress && f.cfg.MaxTotalSize > 0 {
+		// 不需要压缩时同步清理，保证写入后磁盘占用立即回到 MaxTotalSize 以内
+		pruneTotalSize(f.cfg, f.filename)
+	}
+	return n, err
+}
+
+// open 以追加方式打开文件，文件已存在时继续写入
+func (f *rotatingFile) open() error {
+	if err := makeDirs(f.cfg, filepath.Dir(f.filename)); err != nil {
+		return err
+	}
+	file, err := createFile(f.cfg, f.filename)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	if f.size == 0 && len(f.header) > 0 {
+		n, err := file.Write(f.header)
+		f.size += int64(n)
+		f.meter.written(n)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rotateIfOwned 在路径仍指向当前文件时滚动；路径已被其他进程滚动为新文件时只重新打开。
+// 返回是否由本进程滚动。
+func (f *rotatingFile) rotateIfOwned(n int64) (bool, error) {
+	current, err := f.file.Stat()
+	if err != nil {
+		return false, err
+	}
+	onDisk, err := os.Stat(f.filename)
+	if err == nil && !os.SameFile(current, onDisk) {
+		f.file.Close()
+		f.file = nil
+		return false, f.open()
+	}
+	if err == nil && onDisk.Size()+n <= f.max {
+		// 其他进程写入时本地计数不准，以磁盘上的大小为准
+		f.size = onDisk.Size()
+		return false, nil
+	}
+	return true, f.rotate()
+}
+
+// rotate 关闭当前文件，改名为备份并打开新文件，结果计入滚动统计
+func (f *rotatingFile) rotate() error {
+	if err := f.rotateFile(); err != nil {
+		f.meter.failed(err)
+		return err
+	}
+	f.meter.rotated(now())
+	return nil
+}
+
+func (f *rotatingFile) rotateFile() error {
+	if f.file != nil {
+		if f.cfg.FsyncPolicy != FsyncNever {
+			f.file.Sync()
+		}
+		if err := f.file.Close(); err != nil {
+			return err
+		}
+		f.file = nil
+	}
+	backup, t := "", now()
+	if _, err := os.Stat(f.filename); err == nil {
+		backup = f.backupName(t)
+		if err := os.Rename(f.filename, backup); err != nil {
+			return err
+		}
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	if backup != "" {
+		f.background(func() { f.archive(backup, t) })
+	}
+	return nil
+}
+
+// backupName 返回滚动时间为 t、不与已有文件重名的备份文件名
+func (f *rotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(f.filename)
+	base := strings.TrimSuffix(f.filename, ext)
+	t = t.UTC()
+	for {
+		name := base + "-" + t.Format(backupTimeFormat) + ext
+		if _, err := os.Lstat(name); os.IsNotExist(err) {
+			return name
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+// Rotate 立即滚动，当前文件不存在时只打开新文件；冻结期间推迟到解冻时滚动
+func (f *rotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return os.ErrClosed
+	}
+	if f.frozen.Load() > 0 {
+		f.rotateDue = true
+		return nil
+	}
+	return f.rotate()
+}
+
+// Reopen 关闭当前文件并重新打开路径，不改名文件。外部工具改名或删除文件后，之后的写入进入路径上的新文件。
+func (f *rotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return os.ErrClosed
+	}
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+	return f.open()
+}
+
+// Sync 将已写入的数据落盘
+func (f *rotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+// Close 关闭文件并等待后台压缩和清理完成，之后的写入返回 os.ErrClosed
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	var err error
+	f.closed = true
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+	f.pending.Wait()
+	return err
+}
+
+// background 将任务加入后台队列，任务按加入的顺序逐个执行
+func (f *rotatingFile) background(task func()) {
+	f.mill.Lock()
+	defer f.mill.Unlock()
+	f.tasks = append(f.tasks, task)
+	f.runTasks()
+}
+
+// runTasks 在没有 goroutine 执行队列且没有冻结时启动一个，调用方需持有 f.mill。
+// 冻结后正在执行的任务完成即退出，剩余任务留到解冻后执行。
+func (f *rotatingFile) runTasks() {
+	if f.idle != nil || len(f.tasks) == 0 || f.frozen.Load() > 0 {
+		return
+	}
+	f.idle = make(chan struct{})
+	f.pending.Add(1)
+	go func() {
+		defer f.pending.Done()
+		f.mill.Lock()
+		for len(f.tasks) > 0 && f.frozen.Load() == 0 {
+			task := f.tasks[0]
+			f.tasks = f.tasks[1:]
+			f.mill.Unlock()
+			task()
+			f.mill.Lock()
+		}
+		close(f.idle)
+		f.idle = nil
+		f.mill.Unlock()
+	}()
+}
+
+// fileError 报告文件输出后台任务的错误
+func fileError(cfg Config, err error) {
+	if cfg.OnFileError != nil {
+		cfg.OnFileError(err)
+		return
+	}
+	fmt.Fprintln(os.Stderr, "slogx:", err)
+}
+
+// archive 压缩在 t 时滚动出的备份，移动到归档目录，清理旧备份，然后调用 OnRotate
+func (f *rotatingFile) archive(backup string, t time.Time) {
+	if f.cfg.Compress {
+		compressed, err := compressBackup(f.cfg, backup)
+		if os.IsNotExist(err) {
+			// 排队压缩期间已被 MaxBackups 等规则清理
+			return
+		}
+		if err != nil {
+			f.meter.failed(err)
+			fileError(f.cfg, fmt.Errorf("compress %s: %w", backup, err))
+		} else {
+			backup = compressed
+		}
+	}
+	if f.cfg.ArchiveDir != "" {
+		archived, err := archiveFile(f.cfg, backup, t)
+		if err != nil {
+			f.meter.failed(err)
+			fileError(f.cfg, fmt.Errorf("archive %s: %w", backup, err))
+		} else {
+			backup = archived
+		}
+	}
+	f.cleanup()
+	if f.cfg.OnRotate != nil {
+		f.cfg.OnRotate(backup)
+	}
+}
+
+// cleanup 按 MaxBackups、MaxAge 和 MaxTotalSize 删除旧备份
+func (f *rotatingFile) cleanup() {
+	if f.cfg.MaxBackups > 0 || f.cfg.MaxAge > 0 {
+		var cutoff time.Time
+		if f.cfg.MaxAge > 0 {
+			cutoff = now().Add(-time.Duration(f.cfg.MaxAge) * 24 * time.Hour)
+		}
+		for i, b := range f.backups() {
+			if (f.cfg.MaxBackups > 0 && i >= f.cfg.MaxBackups) || b.t.Before(cutoff) {
+				if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+					fileError(f.cfg, err)
+				}
+			}
+		}
+	}
+	if f.cfg.MaxTotalSize > 0 {
+		pruneTotalSize(f.cfg, f.filename)
+	}
+}
+
+type backupFile struct {
+	path string
+	t    time.Time
+}
+
+// backups 返回当前文件的备份（包括 .gz 和 .zst，以及移动到归档目录中的），按时间从新到旧排序
+func (f *rotatingFile) backups() []backupFile {
+	ext := filepath.Ext(f.filename)
+	prefix := strings.TrimSuffix(filepath.Base(f.filename), ext) + "-"
+	var backups []backupFile
+	for _, dir := range append([]string{filepath.Dir(f.filename)}, archiveDirs(f.cfg)...) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			stamp := strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ".zst")
+			if !strings.HasSuffix(stamp, ext) {
+				continue
+			}
+			t, err := time.Parse(backupTimeFormat, strings.TrimSuffix(stamp[len(prefix):], ext))
+			if err != nil {
+				continue
+			}
+			backups = append(backups, backupFile{filepath.Join(dir, name), t})
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].t.After(backups[j].t) })
+	return backups
+}
+
+// compressBackup 按 CompressFormat 压缩备份并删除原文件，返回压缩后的路径
+func compressBackup(cfg Config, path string) (string, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return "", err
+	}
+	ext, newEncoder := ".gz", func(w io.Writer) (io.WriteCloser, error) { return gzip.NewWriter(w), nil }
+	if cfg.CompressFormat == "zstd" {
+		ext, newEncoder = ".zst", func(w io.Writer) (io.WriteCloser, error) {
+			return zstd.NewWriter(w, zstdOptions(cfg.CompressLevel, cfg.ZstdDictionary)...)
+		}
+	}
+	dstPath := path + ext
+	dst, err := os.OpenFile(dstPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return "", err
+	}
+	enc, err := newEncoder(dst)
+	if err == nil {
+		_, err = io.Copy(enc, src)
+		err = errors.Join(err, enc.Close())
+	}
+	if err == nil {
+		err = dst.Sync()
+	}
+	if err = errors.Join(err, dst.Close()); err == nil {
+		err = setOwner(cfg, dstPath, info.Mode(), cfg.FileMode)
+	}
+	if err != nil {
+		os.Remove(dstPath)
+		return "", err
+	}
+	os.Chtimes(dstPath, info.ModTime(), info.ModTime())
+	return dstPath, os.Remove(path)
+}
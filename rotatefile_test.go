@@ -0,0 +1,140 @@
+package log
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileSizeAndCompress(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	f := newFileOutput(Config{MaxSize: 1, MaxBackups: 2, Compress: true}, path)
+	line := strings.Repeat("x", 400<<10) + "\n"
+	for i := 0; i < 8; i++ {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(dir, "app-*.log.gz"))
+	if len(backups) != 2 {
+		t.Fatalf("backups = %v, want 2 compressed files", backups)
+	}
+	if plain, _ := filepath.Glob(filepath.Join(dir, "app-*.log")); len(plain) != 0 {
+		t.Errorf("uncompressed backups left: %v", plain)
+	}
+	gz, err := os.Open(backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gz.Close()
+	r, err := gzip.NewReader(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(r)
+	if len(data) != 2*len(line) {
+		t.Errorf("backup holds %d bytes, want %d", len(data), 2*len(line))
+	}
+}
+
+func TestRotatingFileMultiProcess(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	cfg := Config{MaxSize: 1, FsyncPolicy: FsyncAlways}
+	a, b := newFileOutput(cfg, path), newFileOutput(cfg, path)
+	defer a.Close()
+	defer b.Close()
+
+	line := strings.Repeat("x", 600<<10) + "\n"
+	a.Write([]byte(line))
+	b.Write([]byte(line)) // b 看到文件已超过上限，由 b 滚动
+	a.Write([]byte(line)) // a 发现路径已指向新文件，只重新打开
+	if err := a.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	if len(backups) != 1 {
+		t.Fatalf("backups = %v, want exactly one rotation", backups)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Size() != int64(2*len(line)) {
+		t.Errorf("active file size = %v, want %d (%v)", info.Size(), 2*len(line), err)
+	}
+}
+
+func TestRotatingFileWriteError(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "blocker")
+	os.WriteFile(blocker, nil, 0644)
+	f := newFileOutput(Config{}, filepath.Join(blocker, "app.log"))
+	defer f.Close()
+	if _, err := f.Write([]byte("hello\n")); err == nil {
+		t.Fatal("Write succeeded, want error when the directory cannot be created")
+	}
+}
+
+func TestRotatingFileWriteAfterClose(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	f := newFileOutput(Config{}, filename)
+	f.Write([]byte("hello\n"))
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := f.Write([]byte("late\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected os.ErrClosed after Close, got %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Errorf("Second Close failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filename); string(data) != "hello\n" {
+		t.Errorf("Expected file not reopened after Close, got %q", data)
+	}
+}
+
+func TestReopenSignal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	logger := NewLogger(Config{Filename: path, BufferSize: 4096, ReopenSignal: syscall.SIGWINCH})
+	defer logger.Close()
+	logger.Info("before logrotate")
+	logger.Sync()
+
+	// 模拟 logrotate 的 create 模式：改名后发送信号
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		syscall.Kill(os.Getpid(), syscall.SIGWINCH)
+		time.Sleep(10 * time.Millisecond)
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("ReopenSignal did not reopen the file")
+		}
+	}
+	logger.Info("after logrotate")
+	logger.Sync()
+
+	old, _ := os.ReadFile(path + ".1")
+	current, _ := os.ReadFile(path)
+	if !strings.Contains(string(old), "before logrotate") || strings.Contains(string(old), "after logrotate") {
+		t.Errorf("rotated file = %s", old)
+	}
+	if !strings.Contains(string(current), "after logrotate") {
+		t.Errorf("reopened file = %s", current)
+	}
+}
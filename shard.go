@@ -0,0 +1,190 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ShardPaths 返回分片文件的路径，第 i 个分片在扩展名前插入序号，例如 logs/app.log 的分片为 logs/app.0.log、logs/app.1.log
+func ShardPaths(path string, n int) []string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	paths := make([]string, n)
+	for i := range paths {
+		paths[i] = base + "." + strconv.Itoa(i) + ext
+	}
+	return paths
+}
+
+// shard 是一个独立写入的分片，各分片有自己的锁，不同分片的写入可以并行
+type shard struct {
+	mu   sync.Mutex
+	sink Sink
+}
+
+// shardedSink 将记录分散写入多个文件，用于单个文件写入已经成为瓶颈的场景。
+// 设置 key 时按该属性值的哈希选择分片，同一个 worker 的日志落在同一个文件中；
+// 否则（或记录没有该属性时）轮询选择分片。
+type shardedSink struct {
+	shards []*shard
+	key    string
+	next   atomic.Uint64
+}
+
+// newShardedSink 创建写入 ShardPaths(path, n) 的分片 Sink
+func newShardedSink(path string, n int, format, key string) (*shardedSink, error) {
+	s := &shardedSink{key: key}
+	for _, p := range ShardPaths(path, n) {
+		f, err := os.OpenFile(p, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.shards = append(s.shards, &shard{sink: NewWriterSink(f, format)})
+	}
+	return s, nil
+}
+
+func (s *shardedSink) pick(r Record) int {
+	if s.key != "" {
+		var (
+			h     = fnv.New32a()
+			found bool
+		)
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == s.key {
+				h.Write([]byte(a.Value.Resolve().String()))
+				found = true
+				return false
+			}
+			return true
+		})
+		if found {
+			return int(h.Sum32() % uint32(len(s.shards)))
+		}
+	}
+	return int(s.next.Add(1) % uint64(len(s.shards)))
+}
+
+func (s *shardedSink) Write(ctx context.Context, records []Record) error {
+	batches := make([][]Record, len(s.shards))
+	for _, r := range records {
+		i := s.pick(r)
+		batches[i] = append(batches[i], r)
+	}
+	var errs []error
+	for i, batch := range batches {
+		if len(batch) == 0 {
+			continue
+		}
+		sh := s.shards[i]
+		sh.mu.Lock()
+		errs = append(errs, sh.sink.Write(ctx, batch))
+		sh.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+func (s *shardedSink) Close() error {
+	var errs []error
+	for _, sh := range s.shards {
+		errs = append(errs, sh.sink.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// MergeShards 将多个分片按时间顺序合并写入 w，支持 json 和 text 格式的分片文件。
+// 每个分片内部已按时间排序，合并时做多路归并；时间相同的记录按分片顺序输出，
+// 没有时间戳的行跟随同一分片中的前一行。
+//
+//	files := log.ShardPaths("logs/app.log", 4)
+//	readers := make([]io.Reader, len(files))
+//	... // 打开文件
+//	err := log.MergeShards(os.Stdout, readers...)
+func MergeShards(w io.Writer, readers ...io.Reader) error {
+	type cursor struct {
+		scanner *bufio.Scanner
+		line    []byte
+		time    time.Time
+		done    bool
+	}
+	advance := func(c *cursor) error {
+		if !c.scanner.Scan() {
+			c.done = true
+			return c.scanner.Err()
+		}
+		c.line = append(c.line[:0], c.scanner.Bytes()...)
+		if t, ok := lineTime(c.line); ok {
+			c.time = t
+		}
+		return nil
+	}
+
+	cursors := make([]*cursor, len(readers))
+	for i, r := range readers {
+		c := &cursor{scanner: bufio.NewScanner(r)}
+		c.scanner.Buffer(nil, 1<<20)
+		if err := advance(c); err != nil {
+			return fmt.Errorf("slogx: read shard %d: %w", i, err)
+		}
+		cursors[i] = c
+	}
+	for {
+		min := -1
+		for i, c := range cursors {
+			if !c.done && (min < 0 || c.time.Before(cursors[min].time)) {
+				min = i
+			}
+		}
+		if min < 0 {
+			return nil
+		}
+		c := cursors[min]
+		if _, err := w.Write(append(c.line, '\n')); err != nil {
+			return err
+		}
+		if err := advance(c); err != nil {
+			return fmt.Errorf("slogx: read shard %d: %w", min, err)
+		}
+	}
+}
+
+// lineTime 读取一行 json 或 text 格式日志的时间戳
+func lineTime(line []byte) (time.Time, bool) {
+	var s string
+	if bytes.HasPrefix(line, []byte("{")) {
+		var v struct {
+			Time string `json:"time"`
+		}
+		if json.Unmarshal(line, &v) != nil {
+			return time.Time{}, false
+		}
+		s = v.Time
+	} else {
+		i := bytes.Index(line, []byte("time="))
+		if i < 0 || (i > 0 && line[i-1] != ' ') {
+			return time.Time{}, false
+		}
+		rest := line[i+len("time="):]
+		if j := bytes.IndexByte(rest, ' '); j >= 0 {
+			rest = rest[:j]
+		}
+		s = string(rest)
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	return t, err == nil
+}
@@ -0,0 +1,90 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShardedFileSinkMerge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	sink, err := NewSink(SinkConfig{Type: "file", Options: map[string]any{"path": path, "format": "json", "shards": 3}})
+	if err != nil {
+		t.Fatalf("NewSink failed: %v", err)
+	}
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		r := slog.NewRecord(base.Add(time.Duration(i)*time.Millisecond), slog.LevelInfo, "m"+string(rune('0'+i)), 0)
+		if err := sink.Write(context.Background(), []Record{r}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	var readers []io.Reader
+	for _, p := range ShardPaths(path, 3) {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("read shard: %v", err)
+		}
+		if n := bytes.Count(b, []byte("\n")); n < 3 || n > 4 {
+			t.Errorf("%s has %d records, want round-robin distribution", p, n)
+		}
+		readers = append(readers, bytes.NewReader(b))
+	}
+	var out bytes.Buffer
+	if err := MergeShards(&out, readers...); err != nil {
+		t.Fatalf("MergeShards failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 10 {
+		t.Fatalf("merged %d lines, want 10", len(lines))
+	}
+	for i, line := range lines {
+		if want := `"msg":"m` + string(rune('0'+i)) + `"`; !strings.Contains(line, want) {
+			t.Errorf("line %d = %s, want %s", i, line, want)
+		}
+	}
+}
+
+func TestShardedFileSinkKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	sink, err := NewSink(SinkConfig{Type: "file", Options: map[string]any{"path": path, "shards": 4, "shard_key": "worker"}})
+	if err != nil {
+		t.Fatalf("NewSink failed: %v", err)
+	}
+	for i := 0; i < 8; i++ {
+		r := slog.NewRecord(time.Now(), slog.LevelInfo, "job", 0)
+		r.AddAttrs(slog.Int("worker", 7))
+		sink.Write(context.Background(), []Record{r})
+	}
+	sink.Close()
+
+	var files int
+	for _, p := range ShardPaths(path, 4) {
+		if b, _ := os.ReadFile(p); len(b) > 0 {
+			files++
+			if n := bytes.Count(b, []byte("\n")); n != 8 {
+				t.Errorf("%s has %d records, want 8", p, n)
+			}
+		}
+	}
+	if files != 1 {
+		t.Errorf("records of one worker spread over %d files, want 1", files)
+	}
+}
+
+func TestLineTimeText(t *testing.T) {
+	got, ok := lineTime([]byte(`time=2024-05-01T12:00:00.5Z level=INFO msg=x`))
+	if !ok || !got.Equal(time.Date(2024, 5, 1, 12, 0, 0, 5e8, time.UTC)) {
+		t.Errorf("lineTime = %v, %v", got, ok)
+	}
+}
@@ -0,0 +1,193 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Record 是投递给 Sink 的日志记录，With 绑定的属性已经展开到记录中
+type Record = slog.Record
+
+// Sink 是可插拔的日志输出，第三方可以通过 RegisterSink 注册自己的实现。
+// Write 返回后 records 可能被复用，需要保留记录时请调用 Record.Clone。
+type Sink interface {
+	Write(ctx context.Context, records []Record) error
+	Close() error
+}
+
+// SinkFactory 根据配置参数创建 Sink，options 通常来自配置文件
+type SinkFactory func(options map[string]any) (Sink, error)
+
+// SinkConfig 描述一个通过名称引用的 Sink
+type SinkConfig struct {
+	Type          string         // RegisterSink 注册时使用的名称
+	Options       map[string]any // 传给 SinkFactory 的参数
+	TimePrecision TimePrecision  // 投递给该 Sink 的记录时间精度，TimeOmit 表示不带时间戳
+	MaxInFlight   int            // 同时进行中的 Write 上限，用于同步写入的远程 Sink，0 表示不限制，见 LimitedSink
+	QueueTimeout  time.Duration  // 达到 MaxInFlight 时等待空位的最长时间，超时丢弃记录，默认 DefaultSinkQueueTimeout
+	MinLevel      slog.Leveler   // 投递给该 Sink 的最低级别，例如告警 Sink 只接收 Error 以上，nil 表示只受 Config.Level 限制
+}
+
+var (
+	sinkMu        sync.RWMutex
+	sinkFactories = make(map[string]SinkFactory)
+)
+
+// RegisterSink 注册一个 Sink 工厂，之后可以在 Config.Sinks 中通过 name 引用。
+// 与 database/sql.Register 一样，重复注册或 factory 为 nil 时会 panic。
+func RegisterSink(name string, factory SinkFactory) {
+	sinkMu.Lock()
+	defer sinkMu.Unlock()
+	if factory == nil {
+		panic("slogx: RegisterSink factory is nil")
+	}
+	if _, dup := sinkFactories[name]; dup {
+		panic("slogx: RegisterSink called twice for sink " + name)
+	}
+	sinkFactories[name] = factory
+}
+
+// Sinks 返回已注册的 Sink 名称
+func Sinks() []string {
+	sinkMu.RLock()
+	defer sinkMu.RUnlock()
+	names := make([]string, 0, len(sinkFactories))
+	for name := range sinkFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewSink 根据配置创建 Sink
+func NewSink(cfg SinkConfig) (Sink, error) {
+	sinkMu.RLock()
+	factory, ok := sinkFactories[cfg.Type]
+	sinkMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("slogx: unknown sink %q", cfg.Type)
+	}
+	return factory(cfg.Options)
+}
+
+// sinkHandler 把 slog 的记录转交给 Sink
+type sinkHandler struct {
+	sink  Sink
+	level slog.Leveler
+	state attrState
+}
+
+func newSinkHandler(sink Sink, level slog.Leveler) *sinkHandler {
+	return &sinkHandler{sink: sink, level: level}
+}
+
+func (h *sinkHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *sinkHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.sink.Write(ctx, []Record{h.state.flatten(r)})
+}
+
+func (h *sinkHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &sinkHandler{sink: h.sink, level: h.level, state: h.state.withAttrs(attrs)}
+}
+
+func (h *sinkHandler) WithGroup(name string) slog.Handler {
+	return &sinkHandler{sink: h.sink, level: h.level, state: h.state.withGroup(name)}
+}
+
+// writerSink 将记录编码后写入 io.Writer，是内置 stdout/stderr/file sink 的实现
+type writerSink struct {
+	handler slog.Handler
+	closer  io.Closer
+}
+
+// NewWriterSink 创建一个写入 w 的 Sink，format 为 "json"、"msgpack"、"protobuf"、"rfc5424" 或 "text"
+func NewWriterSink(w io.Writer, format string) Sink {
+	opts := &slog.HandlerOptions{
+		Level: slog.Level(-128), // 级别由 sinkHandler 控制
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			return renameLevel(nil, groups, a)
+		},
+	}
+	s := &writerSink{}
+	switch format {
+	case "json":
+		s.handler = slog.NewJSONHandler(w, opts)
+	case "msgpack":
+		s.handler = newMsgpackHandler(w, opts)
+	case "protobuf":
+		s.handler = newProtobufHandler(w, opts)
+	case "rfc5424":
+		s.handler = newRFC5424Handler(w, opts, RFC5424Options{})
+	default:
+		s.handler = slog.NewTextHandler(w, opts)
+	}
+	if c, ok := w.(io.Closer); ok && w != os.Stdout && w != os.Stderr {
+		s.closer = c
+	}
+	return s
+}
+
+func (s *writerSink) Write(ctx context.Context, records []Record) error {
+	for _, r := range records {
+		if err := s.handler.Handle(ctx, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *writerSink) Close() error {
+	if s.closer != nil {
+		return s.closer.Close()
+	}
+	return nil
+}
+
+// optString 从 options 中读取字符串参数
+func optString(options map[string]any, key, def string) string {
+	if v, ok := options[key].(string); ok && v != "" {
+		return v
+	}
+	return def
+}
+
+func init() {
+	RegisterSink("stdout", func(options map[string]any) (Sink, error) {
+		return NewWriterSink(os.Stdout, optString(options, "format", "text")), nil
+	})
+	RegisterSink("stderr", func(options map[string]any) (Sink, error) {
+		return NewWriterSink(os.Stderr, optString(options, "format", "text")), nil
+	})
+	RegisterSink("file", func(options map[string]any) (Sink, error) {
+		path := optString(options, "path", "")
+		if path == "" {
+			return nil, fmt.Errorf("slogx: file sink requires option \"path\"")
+		}
+		// shards 大于 1 时写入多个分片文件，shard_key 指定按哪个属性（例如 worker）选择分片
+		if n := optInt(options, "shards", 1); n > 1 {
+			return newShardedSink(path, n, optString(options, "format", "text"), optString(options, "shard_key", ""))
+		}
+		// compression 为 zstd 时以 zstd 压缩写入，可用 zstd_level 和 zstd_dictionary（字典文件路径）调整，见 zstd.go
+		if optString(options, "compression", "") == "zstd" {
+			zw, err := newZstdFile(path, options)
+			if err != nil {
+				return nil, err
+			}
+			return NewWriterSink(zw, optString(options, "format", "text")), nil
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		return NewWriterSink(f, optString(options, "format", "text")), nil
+	})
+}
@@ -0,0 +1,107 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+// memorySink 在内存中保存收到的记录，供测试使用
+type memorySink struct {
+	mu      sync.Mutex
+	records []Record
+	closed  bool
+}
+
+func (s *memorySink) Write(_ context.Context, records []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range records {
+		s.records = append(s.records, r.Clone())
+	}
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+// recordAttrs 将记录的属性展开为 "group.key" -> value 的映射
+func recordAttrs(r Record) map[string]string {
+	m := make(map[string]string)
+	var walk func(prefix string, a slog.Attr)
+	walk = func(prefix string, a slog.Attr) {
+		if a.Value.Kind() == slog.KindGroup {
+			for _, ga := range a.Value.Group() {
+				walk(prefix+a.Key+".", ga)
+			}
+			return
+		}
+		m[prefix+a.Key] = a.Value.String()
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		walk("", a)
+		return true
+	})
+	return m
+}
+
+// registerTestSink 注册测试用的 Sink，测试结束时取消注册，测试可以重复运行
+func registerTestSink(t *testing.T, name string, factory SinkFactory) {
+	t.Helper()
+	RegisterSink(name, factory)
+	t.Cleanup(func() {
+		sinkMu.Lock()
+		defer sinkMu.Unlock()
+		delete(sinkFactories, name)
+	})
+}
+
+func TestSinkRegistry(t *testing.T) {
+	sink := &memorySink{}
+	registerTestSink(t, "test-memory", func(options map[string]any) (Sink, error) {
+		return sink, nil
+	})
+
+	logger := NewLogger(Config{
+		Level: slog.LevelInfo,
+		Sinks: []SinkConfig{{Type: "test-memory"}},
+	})
+	logger.Debug("dropped by level")
+	logger.With("module", "billing").Info("charged", "amount", 42)
+	slog.New(logger.Handler()).WithGroup("req").Info("grouped", "id", 7)
+
+	if len(sink.records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(sink.records))
+	}
+	attrs := recordAttrs(sink.records[0])
+	if attrs["module"] != "billing" || attrs["amount"] != "42" {
+		t.Errorf("Expected With attrs to be flattened into the record, got %v", attrs)
+	}
+	if attrs := recordAttrs(sink.records[1]); attrs["req.id"] != "7" {
+		t.Errorf("Expected grouped attr req.id, got %v", attrs)
+	}
+
+	if err := logger.Close(); err != nil || !sink.closed {
+		t.Errorf("Expected sink to be closed, err: %v", err)
+	}
+}
+
+func TestRegisterSinkTwicePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected RegisterSink to panic on duplicate name")
+		}
+	}()
+	RegisterSink("stdout", func(map[string]any) (Sink, error) { return nil, nil })
+}
+
+func TestNewSinkUnknown(t *testing.T) {
+	if _, err := NewSink(SinkConfig{Type: "no-such-sink"}); err == nil {
+		t.Error("Expected error for unknown sink")
+	}
+}
@@ -0,0 +1,57 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SpanIDKey 是 span ID 在日志中的 key，与 trace_id 一起会被 OTLP Sink 映射到 LogRecord 的 traceId/spanId
+const SpanIDKey = "span_id"
+
+// SpanContextFunc 返回 ctx 中活动 span 的 trace ID 和 span ID（十六进制字符串），没有活动的 span 时返回空字符串。
+// slogx 不依赖 OpenTelemetry，使用 OTel 时这样设置 Config.SpanContext：
+//
+//	SpanContext: func(ctx context.Context) (traceID, spanID string) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		if !sc.IsValid() {
+//			return "", ""
+//		}
+//		return sc.TraceID().String(), sc.SpanID().String()
+//	},
+type SpanContextFunc func(ctx context.Context) (traceID, spanID string)
+
+// spanHandler 为 ctx 中有活动 span 的记录附加 trace_id 和 span_id，
+// 记录或 With 绑定的属性中已有同名 key 时不重复添加
+type spanHandler struct {
+	slog.Handler
+	span  SpanContextFunc
+	state attrState
+}
+
+func (h *spanHandler) Handle(ctx context.Context, r slog.Record) error {
+	traceID, spanID := h.span(ctx)
+	if traceID == "" && spanID == "" {
+		return h.Handler.Handle(ctx, r)
+	}
+	attrs := h.state.collect(r)
+	var add []slog.Attr
+	if traceID != "" && !hasAttr(attrs, TraceIDKey) {
+		add = append(add, slog.String(TraceIDKey, traceID))
+	}
+	if spanID != "" && !hasAttr(attrs, SpanIDKey) {
+		add = append(add, slog.String(SpanIDKey, spanID))
+	}
+	if len(add) > 0 {
+		r = r.Clone()
+		r.AddAttrs(add...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *spanHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &spanHandler{Handler: h.Handler.WithAttrs(attrs), span: h.span, state: h.state.withAttrs(attrs)}
+}
+
+func (h *spanHandler) WithGroup(name string) slog.Handler {
+	return &spanHandler{Handler: h.Handler.WithGroup(name), span: h.span, state: h.state.withGroup(name)}
+}
@@ -0,0 +1,42 @@
+package log
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type spanKey struct{}
+
+func TestSpanContext(t *testing.T) {
+	var buf strings.Builder
+	logger := NewLogger(Config{
+		Writer: &buf,
+		Format: "json",
+		SpanContext: func(ctx context.Context) (string, string) {
+			if ids, ok := ctx.Value(spanKey{}).([2]string); ok {
+				return ids[0], ids[1]
+			}
+			return "", ""
+		},
+	})
+	ctx := context.WithValue(context.Background(), spanKey{}, [2]string{"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"})
+
+	logger.InfoContext(ctx, "with span")
+	logger.Info("without span")
+	logger.With(TraceIDKey, "bound").InfoContext(ctx, "bound trace")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %q", buf.String())
+	}
+	if !strings.Contains(lines[0], `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`) || !strings.Contains(lines[0], `"span_id":"00f067aa0ba902b7"`) {
+		t.Errorf("Expected trace_id and span_id, got %s", lines[0])
+	}
+	if strings.Contains(lines[1], "trace_id") {
+		t.Errorf("Expected no span attrs without an active span, got %s", lines[1])
+	}
+	if strings.Count(lines[2], "trace_id") != 1 || !strings.Contains(lines[2], `"span_id"`) {
+		t.Errorf("Expected the bound trace_id to be kept, got %s", lines[2])
+	}
+}
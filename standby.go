@@ -0,0 +1,177 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultStandbySpool = 10000           // 默认最多暂存的记录数
+	DefaultStandbyProbe = 5 * time.Second // 默认的主 Sink 探测间隔
+)
+
+// StandbyOptions 是 StandbySink 的参数
+type StandbyOptions struct {
+	MaxSpool      int           // 主 Sink 不可用期间最多暂存的记录数，超出时丢弃最旧的记录
+	ProbeInterval time.Duration // 主 Sink 失败后重新探测的间隔
+}
+
+// StandbySink 是按优先级组织的热备 Sink：主 Sink（例如 loki）健康时只写主 Sink；
+// 主 Sink 失败后记录改写到备用 Sink（例如本地文件），同时暂存到内存 spool，
+// 主 Sink 恢复后先回放 spool 中的记录，再继续正常写入。
+type StandbySink struct {
+	mu       sync.Mutex
+	primary  Sink
+	fallback Sink
+	opts     StandbyOptions
+	spool    []Record
+	retryAt  time.Time // 零值表示主 Sink 健康
+	dropped  int
+}
+
+// NewStandbySink 创建热备 Sink
+func NewStandbySink(primary, fallback Sink, opts StandbyOptions) *StandbySink {
+	if opts.MaxSpool <= 0 {
+		opts.MaxSpool = DefaultStandbySpool
+	}
+	if opts.ProbeInterval <= 0 {
+		opts.ProbeInterval = DefaultStandbyProbe
+	}
+	return &StandbySink{primary: primary, fallback: fallback, opts: opts}
+}
+
+func (s *StandbySink) Write(ctx context.Context, records []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := now()
+	if s.retryAt.IsZero() || !t.Before(s.retryAt) {
+		err := s.replay(ctx)
+		if err == nil {
+			err = s.primary.Write(ctx, records)
+		}
+		if err == nil {
+			s.retryAt = time.Time{}
+			return nil
+		}
+		s.retryAt = t.Add(s.opts.ProbeInterval)
+	}
+
+	s.stash(records)
+	return s.fallback.Write(ctx, records)
+}
+
+// replay 将 spool 中的记录回放到主 Sink
+func (s *StandbySink) replay(ctx context.Context) error {
+	if len(s.spool) == 0 {
+		return nil
+	}
+	if err := s.primary.Write(ctx, s.spool); err != nil {
+		return err
+	}
+	s.spool = nil
+	return nil
+}
+
+// stash 暂存记录，超出容量时丢弃最旧的记录
+func (s *StandbySink) stash(records []Record) {
+	for _, r := range records {
+		s.spool = append(s.spool, r.Clone())
+	}
+	if over := len(s.spool) - s.opts.MaxSpool; over > 0 {
+		s.dropped += over
+		s.spool = append(s.spool[:0:0], s.spool[over:]...)
+	}
+}
+
+// Healthy 返回主 Sink 当前是否健康
+func (s *StandbySink) Healthy() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.retryAt.IsZero()
+}
+
+// Spooled 返回等待回放的记录数以及因 spool 溢出而丢弃的记录数
+func (s *StandbySink) Spooled() (pending, dropped int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.spool), s.dropped
+}
+
+func (s *StandbySink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 关闭前尽量把暂存的记录交给主 Sink
+	s.replay(context.Background())
+	return errors.Join(s.primary.Close(), s.fallback.Close())
+}
+
+// sinkConfigOption 从 options 中读取嵌套的 Sink 配置，兼容配置文件中大小写不同的写法
+func sinkConfigOption(options map[string]any, key string) (SinkConfig, error) {
+	m, ok := options[key].(map[string]any)
+	if !ok {
+		return SinkConfig{}, fmt.Errorf("slogx: option %q must be a sink config", key)
+	}
+	var cfg SinkConfig
+	for k, v := range m {
+		switch strings.ToLower(k) {
+		case "type":
+			cfg.Type, _ = v.(string)
+		case "options":
+			cfg.Options, _ = v.(map[string]any)
+		}
+	}
+	return cfg, nil
+}
+
+// optInt 从 options 中读取整数参数，兼容 JSON 解码得到的 float64
+func optInt(options map[string]any, key string, def int) int {
+	switch v := options[key].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return def
+}
+
+// optDuration 从 options 中读取时长参数，支持 "5s" 这样的字符串和 time.Duration
+func optDuration(options map[string]any, key string, def time.Duration) time.Duration {
+	switch v := options[key].(type) {
+	case time.Duration:
+		return v
+	case string:
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return def
+}
+
+func init() {
+	RegisterSink("standby", func(options map[string]any) (Sink, error) {
+		var sinks [2]Sink
+		for i, key := range []string{"primary", "fallback"} {
+			cfg, err := sinkConfigOption(options, key)
+			if err == nil {
+				sinks[i], err = NewSink(cfg)
+			}
+			if err != nil {
+				if sinks[0] != nil {
+					sinks[0].Close()
+				}
+				return nil, err
+			}
+		}
+		return NewStandbySink(sinks[0], sinks[1], StandbyOptions{
+			MaxSpool:      optInt(options, "max_spool", 0),
+			ProbeInterval: optDuration(options, "probe_interval", 0),
+		}), nil
+	})
+}
@@ -0,0 +1,98 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// flakySink 在 down 为 true 时写入失败
+type flakySink struct {
+	memorySink
+	down bool
+}
+
+func (s *flakySink) Write(ctx context.Context, records []Record) error {
+	if s.down {
+		return errors.New("loki unavailable")
+	}
+	return s.memorySink.Write(ctx, records)
+}
+
+func messages(records []Record) []string {
+	var msgs []string
+	for _, r := range records {
+		msgs = append(msgs, r.Message)
+	}
+	return msgs
+}
+
+func TestStandbySink(t *testing.T) {
+	primary := &flakySink{}
+	fallback := &memorySink{}
+	s := NewStandbySink(primary, fallback, StandbyOptions{ProbeInterval: 10 * time.Millisecond})
+
+	write := func(msg string) {
+		s.Write(context.Background(), []Record{slog.NewRecord(time.Now(), slog.LevelInfo, msg, 0)})
+	}
+
+	write("a")
+	primary.down = true
+	write("b")
+	write("c")
+	if s.Healthy() {
+		t.Error("Expected primary to be unhealthy")
+	}
+	if pending, _ := s.Spooled(); pending != 2 {
+		t.Errorf("Expected 2 spooled records, got %d", pending)
+	}
+
+	primary.down = false
+	time.Sleep(20 * time.Millisecond)
+	write("d")
+
+	if got := messages(primary.records); len(got) != 4 || got[1] != "b" || got[3] != "d" {
+		t.Errorf("Expected primary to catch up in order, got %v", got)
+	}
+	if got := messages(fallback.records); len(got) != 2 {
+		t.Errorf("Expected fallback to receive records only while primary was down, got %v", got)
+	}
+	if pending, _ := s.Spooled(); pending != 0 || !s.Healthy() {
+		t.Errorf("Expected empty spool and healthy primary, got pending=%d", pending)
+	}
+}
+
+func TestStandbySinkSpoolLimit(t *testing.T) {
+	primary := &flakySink{down: true}
+	s := NewStandbySink(primary, &memorySink{}, StandbyOptions{MaxSpool: 2, ProbeInterval: time.Hour})
+	for i := 0; i < 5; i++ {
+		s.Write(context.Background(), []Record{slog.NewRecord(time.Now(), slog.LevelInfo, "x", 0)})
+	}
+	if pending, dropped := s.Spooled(); pending != 2 || dropped != 3 {
+		t.Errorf("Expected pending=2 dropped=3, got pending=%d dropped=%d", pending, dropped)
+	}
+}
+
+func TestStandbySinkFromConfig(t *testing.T) {
+	sink, err := NewSink(SinkConfig{
+		Type: "standby",
+		Options: map[string]any{
+			"primary":        map[string]any{"type": "stderr"},
+			"fallback":       map[string]any{"Type": "stdout", "Options": map[string]any{"format": "json"}},
+			"max_spool":      float64(100),
+			"probe_interval": "1s",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create standby sink: %v", err)
+	}
+	if s := sink.(*StandbySink); s.opts.MaxSpool != 100 || s.opts.ProbeInterval != time.Second {
+		t.Errorf("Unexpected options: %+v", s.opts)
+	}
+
+	if _, err := NewSink(SinkConfig{Type: "standby", Options: map[string]any{"primary": "loki"}}); err == nil {
+		t.Error("Expected error for invalid nested sink config")
+	}
+}
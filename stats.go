@@ -0,0 +1,221 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Watermarker 由异步发送的 Sink 实现，返回最新一条已持久写入（例如已被采集端确认）的记录的时间。
+// 没有实现时，Write 成功返回即视为写入完成。
+type Watermarker interface {
+	Watermark() time.Time
+}
+
+// Dropper 由内部有队列的 Sink 实现，返回队列满时丢弃的记录数
+type Dropper interface {
+	Dropped() uint64
+}
+
+// SinkStats 是一个 Sink 的写入统计
+type SinkStats struct {
+	Type      string        // SinkConfig.Type
+	Records   uint64        // Write 成功的记录数
+	Errors    uint64        // Write 失败的次数
+	Dropped   uint64        // Sink 内部丢弃的记录数，例如 OTLPSink 待发送队列满时丢弃的最旧记录，见 Dropper
+	LastError error         // 最近一次 Write 的错误，成功后清空
+	Watermark time.Time     // 最新一条已持久写入的记录的时间（flush watermark），还没有写入时为零值
+	Lag       time.Duration // 取统计时的时间与 Watermark 之差，表示该 Sink 落后了多少；Watermark 为零值时为 0
+}
+
+// RotationStats 是文件输出的滚动统计，用于发现卡住的滚动（例如磁盘满、权限错误导致改名失败）
+type RotationStats struct {
+	Rotations          uint64    // 完成的滚动次数，包括按大小、按时间和手动滚动
+	Errors             uint64    // 滚动、压缩和归档失败的次数
+	LastError          string    // 最近一次失败的错误信息
+	LastRotation       time.Time // 最近一次滚动的时间，还没有滚动时为零值
+	BytesSinceRotation int64     // 最近一次滚动后写入当前文件的字节数
+}
+
+// Stats 是 Logger 的运行统计
+type Stats struct {
+	Sinks    []SinkStats    // 按 Config.Sinks 的顺序，创建失败的 Sink 不在其中
+	Rotation *RotationStats // 文件滚动统计，没有配置 Filename 时为 nil
+}
+
+// Stats 返回各 Sink 的写入统计。故障时比较各 Sink 的 Watermark 和 Lag，可以看出远程 Sink 落后了多少。
+func (l *Logger) Stats() Stats {
+	t := now()
+	stats := Stats{Sinks: make([]SinkStats, 0, len(l.meters))}
+	for _, m := range l.meters {
+		stats.Sinks = append(stats.Sinks, m.stats(t))
+	}
+	if l.rotation != nil {
+		rs := l.rotation.stats()
+		stats.Rotation = &rs
+	}
+	return stats
+}
+
+// sinkMeter 包装 Sink，统计写入结果和 flush watermark
+type sinkMeter struct {
+	Sink
+	typ string
+
+	mu        sync.Mutex
+	records   uint64
+	errors    uint64
+	lastErr   error
+	watermark time.Time
+}
+
+func newSinkMeter(typ string, sink Sink) *sinkMeter {
+	return &sinkMeter{Sink: sink, typ: typ}
+}
+
+func (m *sinkMeter) Write(ctx context.Context, records []Record) error {
+	err := m.Sink.Write(ctx, records)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastErr = err
+	if err != nil {
+		m.errors++
+		return err
+	}
+	m.records += uint64(len(records))
+	for _, r := range records {
+		if r.Time.After(m.watermark) {
+			m.watermark = r.Time
+		}
+	}
+	return nil
+}
+
+func (m *sinkMeter) stats(t time.Time) SinkStats {
+	m.mu.Lock()
+	s := SinkStats{Type: m.typ, Records: m.records, Errors: m.errors, LastError: m.lastErr, Watermark: m.watermark}
+	m.mu.Unlock()
+	inner := m.Sink
+	if l, ok := inner.(*LimitedSink); ok {
+		inner = l.Sink
+	}
+	if w, ok := inner.(Watermarker); ok {
+		s.Watermark = w.Watermark()
+	}
+	if d, ok := inner.(Dropper); ok {
+		s.Dropped = d.Dropped()
+	}
+	if !s.Watermark.IsZero() {
+		s.Lag = t.Sub(s.Watermark)
+	}
+	return s
+}
+
+// rotationMeter 统计文件滚动，方法可以在 nil 上调用
+type rotationMeter struct {
+	rotations atomic.Uint64
+	errors    atomic.Uint64
+	bytes     atomic.Int64
+	last      atomic.Int64 // 最近一次滚动的 UnixNano
+	lastErr   atomic.Value // string
+}
+
+func (m *rotationMeter) written(n int) {
+	if m != nil {
+		m.bytes.Add(int64(n))
+	}
+}
+
+func (m *rotationMeter) rotated(t time.Time) {
+	if m != nil {
+		m.rotations.Add(1)
+		m.bytes.Store(0)
+		m.last.Store(t.UnixNano())
+	}
+}
+
+func (m *rotationMeter) failed(err error) {
+	if m != nil {
+		m.errors.Add(1)
+		m.lastErr.Store(err.Error())
+	}
+}
+
+func (m *rotationMeter) stats() RotationStats {
+	s := RotationStats{Rotations: m.rotations.Load(), Errors: m.errors.Load(), BytesSinceRotation: m.bytes.Load()}
+	if last := m.last.Load(); last != 0 {
+		s.LastRotation = time.Unix(0, last)
+	}
+	s.LastError, _ = m.lastErr.Load().(string)
+	return s
+}
+
+// WritePrometheus 以 Prometheus 文本格式输出 Stats，不依赖 Prometheus 客户端库
+func (s Stats) WritePrometheus(w io.Writer) error {
+	var err error
+	metric := func(name, typ, help string) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+		}
+	}
+	sample := func(name, labels string, v float64) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, "%s%s %g\n", name, labels, v)
+		}
+	}
+	if r := s.Rotation; r != nil {
+		metric("slogx_rotations_total", "counter", "Log file rotations performed.")
+		sample("slogx_rotations_total", "", float64(r.Rotations))
+		metric("slogx_rotation_errors_total", "counter", "Failed log file rotations, compressions and archive moves.")
+		sample("slogx_rotation_errors_total", "", float64(r.Errors))
+		metric("slogx_last_rotation_timestamp_seconds", "gauge", "Unix time of the last rotation, 0 if none.")
+		sample("slogx_last_rotation_timestamp_seconds", "", unixSeconds(r.LastRotation))
+		metric("slogx_bytes_since_rotation", "gauge", "Bytes written to the active file since the last rotation.")
+		sample("slogx_bytes_since_rotation", "", float64(r.BytesSinceRotation))
+	}
+	if len(s.Sinks) > 0 {
+		label := func(i int, sink SinkStats) string { return fmt.Sprintf("{sink=%q,index=\"%d\"}", sink.Type, i) }
+		metric("slogx_sink_records_total", "counter", "Records written to the sink.")
+		for i, sink := range s.Sinks {
+			sample("slogx_sink_records_total", label(i, sink), float64(sink.Records))
+		}
+		metric("slogx_sink_errors_total", "counter", "Failed sink writes.")
+		for i, sink := range s.Sinks {
+			sample("slogx_sink_errors_total", label(i, sink), float64(sink.Errors))
+		}
+		metric("slogx_sink_dropped_total", "counter", "Records dropped inside the sink, e.g. when its queue is full.")
+		for i, sink := range s.Sinks {
+			sample("slogx_sink_dropped_total", label(i, sink), float64(sink.Dropped))
+		}
+		metric("slogx_sink_watermark_timestamp_seconds", "gauge", "Unix time of the newest durably written record, 0 if none.")
+		for i, sink := range s.Sinks {
+			sample("slogx_sink_watermark_timestamp_seconds", label(i, sink), unixSeconds(sink.Watermark))
+		}
+		metric("slogx_sink_lag_seconds", "gauge", "Seconds between now and the sink watermark.")
+		for i, sink := range s.Sinks {
+			sample("slogx_sink_lag_seconds", label(i, sink), sink.Lag.Seconds())
+		}
+	}
+	return err
+}
+
+func unixSeconds(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return float64(t.UnixNano()) / 1e9
+}
+
+// PrometheusHandler 返回以 Prometheus 文本格式输出 l.Stats() 的 http.Handler，可以挂到 /metrics 供采集：
+//
+//	http.Handle("/metrics/slogx", logger.PrometheusHandler())
+func (l *Logger) PrometheusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		l.Stats().WritePrometheus(w)
+	})
+}
@@ -0,0 +1,121 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type failingSink struct{}
+
+func (failingSink) Write(context.Context, []Record) error { return errors.New("remote down") }
+func (failingSink) Close() error                          { return nil }
+
+func TestSinkStats(t *testing.T) {
+	current := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	SetClock(ClockFunc(func() time.Time { return current }))
+	defer SetClock(nil)
+
+	registerTestSink(t, "stats-memory", func(map[string]any) (Sink, error) { return &memorySink{}, nil })
+	registerTestSink(t, "stats-failing", func(map[string]any) (Sink, error) { return failingSink{}, nil })
+	logger := NewLogger(Config{Sinks: []SinkConfig{{Type: "stats-memory"}, {Type: "stats-failing"}}})
+	defer logger.Close()
+
+	logger.Info("first")
+	current = current.Add(time.Minute)
+	logger.Info("second")
+	current = current.Add(5 * time.Second)
+
+	stats := logger.Stats()
+	if len(stats.Sinks) != 2 {
+		t.Fatalf("got %d sink stats, want 2", len(stats.Sinks))
+	}
+	ok, failing := stats.Sinks[0], stats.Sinks[1]
+	if ok.Type != "stats-memory" || ok.Records != 2 || ok.Errors != 0 || ok.Lag != 5*time.Second {
+		t.Errorf("memory sink stats = %+v", ok)
+	}
+	if failing.Records != 0 || failing.Errors != 2 || failing.LastError == nil || !failing.Watermark.IsZero() || failing.Lag != 0 {
+		t.Errorf("failing sink stats = %+v", failing)
+	}
+}
+
+func TestOTLPWatermark(t *testing.T) {
+	var accept atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !accept.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	sink := NewOTLPSink(OTLPOptions{Endpoint: srv.URL, FlushInterval: time.Hour})
+	defer sink.Close()
+	first := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	sink.Write(context.Background(), []Record{slog.NewRecord(first, slog.LevelInfo, "first", 0)})
+	if sink.Flush() == nil || !sink.Watermark().IsZero() {
+		t.Fatalf("watermark advanced although the collector rejected the batch: %v", sink.Watermark())
+	}
+
+	accept.Store(true)
+	sink.Write(context.Background(), []Record{slog.NewRecord(first.Add(time.Second), slog.LevelInfo, "second", 0)})
+	if err := sink.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got := sink.Watermark(); !got.Equal(first.Add(time.Second)) {
+		t.Errorf("watermark = %v, want %v", got, first.Add(time.Second))
+	}
+}
+
+func TestRotationStats(t *testing.T) {
+	current := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	SetClock(ClockFunc(func() time.Time { return current }))
+	defer SetClock(nil)
+
+	dir := filepath.Join(t.TempDir(), "logs")
+	logger := NewLogger(Config{Filename: filepath.Join(dir, "app.log"), MaxSize: 1})
+	defer logger.Close()
+	if s := NewLogger(Config{Writer: io.Discard}).Stats(); s.Rotation != nil {
+		t.Errorf("Rotation stats without a file = %+v", s.Rotation)
+	}
+
+	big := strings.Repeat("x", 600<<10)
+	logger.Info("first", "payload", big)
+	logger.Info("second", "payload", big)
+	rs := logger.Stats().Rotation
+	if rs.Rotations != 1 || !rs.LastRotation.Equal(current) || rs.BytesSinceRotation < 600<<10 || rs.Errors != 0 {
+		t.Errorf("after size rotation = %+v", rs)
+	}
+
+	// 日志目录被替换成普通文件，重新打开失败
+	os.RemoveAll(dir)
+	os.WriteFile(dir, nil, 0644)
+	if err := logger.Rotate(); err == nil {
+		t.Fatal("Rotate succeeded, want error")
+	}
+	rs = logger.Stats().Rotation
+	if rs.Rotations != 1 || rs.Errors != 1 || rs.LastError == "" {
+		t.Errorf("after failed rotation = %+v", rs)
+	}
+
+	rec := httptest.NewRecorder()
+	logger.PrometheusHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE slogx_rotations_total counter\nslogx_rotations_total 1\n",
+		"slogx_rotation_errors_total 1\n",
+		"slogx_last_rotation_timestamp_seconds 1.7145648e+09\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+}
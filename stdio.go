@@ -0,0 +1,29 @@
+package log
+
+import (
+	"io"
+	"os"
+)
+
+// isStdout 判断 w 是否写入标准输出，包括指向文件描述符 1 的 *os.File
+func isStdout(w io.Writer) bool {
+	if w == os.Stdout {
+		return true
+	}
+	f, ok := w.(*os.File)
+	return ok && f.Fd() == 1
+}
+
+// sinkWritesStdout 判断内置 Sink 的配置是否会写入标准输出
+func sinkWritesStdout(sc SinkConfig) bool {
+	switch sc.Type {
+	case "stdout":
+		return true
+	case "file":
+		switch optString(sc.Options, "path", "") {
+		case "/dev/stdout", "/dev/fd/1", "/proc/self/fd/1":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package log
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNoStdout(t *testing.T) {
+	logger := NewLogger(Config{
+		Stdout:   true,
+		Sinks:    []SinkConfig{{Type: "stdout"}, {Type: "file", Options: map[string]any{"path": "/dev/stdout"}}},
+		NoStdout: true,
+		Quiet:    true,
+	})
+	defer logger.Close()
+	report := logger.InitReport()
+	if slices.Contains(report.Outputs, "stdout") {
+		t.Errorf("Outputs = %v, want no stdout", report.Outputs)
+	}
+	if want := []string{"stderr", "sink:stderr", "sink:stderr"}; !slices.Equal(report.Outputs, want) {
+		t.Errorf("Outputs = %v, want %v", report.Outputs, want)
+	}
+	if len(report.Fallbacks) != 3 {
+		t.Errorf("Fallbacks = %q, want 3 redirections", report.Fallbacks)
+	}
+}
+
+func TestNoStdoutDevelopmentPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for stdout output in development mode")
+		}
+	}()
+	NewLogger(Config{Stdout: true, NoStdout: true, Development: true})
+}
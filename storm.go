@@ -0,0 +1,166 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// StormOptions 是风暴模式的参数
+type StormOptions struct {
+	SampleRate    int          // 低于 KeepLevel 的记录每 SampleRate 条只保留 1 条，默认 100；Config.NeverSample 中的消息不采样
+	MaxMessageLen int          // 消息和字符串属性的最大长度（字符数），超出部分截断，默认 256
+	KeepLevel     slog.Leveler // 不低于该级别的记录不采样（仍会截断），nil 时使用 Error
+}
+
+// storm 保存风暴模式的状态，同一个 Logger 派生出的所有 Logger 共享
+type storm struct {
+	mu      sync.Mutex
+	active  atomic.Pointer[stormRun]
+	banner  slog.Handler // 输出开始/结束提示，不经过风暴模式处理
+	keep    string       // 不截断的 key，即调用位置的 key
+	exempt  exemptions   // 永不采样的消息
+	timer   *time.Timer
+	counter atomic.Uint64
+}
+
+// stormRun 是一次风暴模式的运行状态
+type stormRun struct {
+	opts    StormOptions
+	until   time.Time
+	dropped atomic.Uint64
+}
+
+// StartStorm 开启风暴模式：在 d 时间内对全局日志做激进的采样和截断，结束后自动恢复。
+// 用于值班人员在日志量引发故障时立即减压，无需重新部署。开始和结束时各输出一条提示记录。
+func (l *Logger) StartStorm(d time.Duration, opts StormOptions) {
+	if opts.SampleRate <= 0 {
+		opts.SampleRate = 100
+	}
+	if opts.MaxMessageLen <= 0 {
+		opts.MaxMessageLen = 256
+	}
+	if opts.KeepLevel == nil {
+		opts.KeepLevel = slog.LevelError
+	}
+
+	s := l.storm
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	run := &stormRun{opts: opts, until: now().Add(d)}
+	s.active.Store(run)
+	s.timer = time.AfterFunc(d, func() { l.StopStorm() })
+
+	slog.New(s.banner).Warn("log storm mode started",
+		"duration", d,
+		"sample_rate", opts.SampleRate,
+		"max_message_len", opts.MaxMessageLen,
+		"keep_level", opts.KeepLevel.Level(),
+	)
+}
+
+// StopStorm 立即结束风暴模式
+func (l *Logger) StopStorm() {
+	s := l.storm
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	run := s.active.Swap(nil)
+	if run == nil {
+		return
+	}
+	slog.New(s.banner).Warn("log storm mode ended", "dropped", run.dropped.Load())
+}
+
+// StormActive 返回风暴模式是否处于开启状态
+func (l *Logger) StormActive() bool {
+	run := l.storm.active.Load()
+	return run != nil && now().Before(run.until)
+}
+
+// StartStorm 对默认 logger 开启风暴模式
+func StartStorm(d time.Duration, opts StormOptions) {
+	defaultLogger.StartStorm(d, opts)
+}
+
+// StopStorm 结束默认 logger 的风暴模式
+func StopStorm() {
+	defaultLogger.StopStorm()
+}
+
+// stormHandler 在风暴模式开启时对记录做采样和截断
+type stormHandler struct {
+	slog.Handler
+	storm *storm
+}
+
+func (h *stormHandler) Handle(ctx context.Context, r slog.Record) error {
+	run := h.storm.active.Load()
+	if run == nil || !now().Before(run.until) {
+		return h.Handler.Handle(ctx, r)
+	}
+	if r.Level < run.opts.KeepLevel.Level() && !h.storm.exempt.keep(ctx, r.Message) && h.storm.counter.Add(1)%uint64(run.opts.SampleRate) != 0 {
+		run.dropped.Add(1)
+		return nil
+	}
+	return h.Handler.Handle(ctx, truncateRecord(r, run.opts.MaxMessageLen, h.storm.keep))
+}
+
+func (h *stormHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &stormHandler{Handler: h.Handler.WithAttrs(attrs), storm: h.storm}
+}
+
+func (h *stormHandler) WithGroup(name string) slog.Handler {
+	return &stormHandler{Handler: h.Handler.WithGroup(name), storm: h.storm}
+}
+
+// truncateRecord 截断消息和字符串属性，名为 keep 的顶层属性保持不变
+func truncateRecord(r slog.Record, max int, keep string) slog.Record {
+	nr := slog.NewRecord(r.Time, r.Level, truncateString(r.Message, max), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key != keep {
+			a = truncateAttr(a, max)
+		}
+		nr.AddAttrs(a)
+		return true
+	})
+	return nr
+}
+
+func truncateAttr(a slog.Attr, max int) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, truncateString(a.Value.String(), max))
+	case slog.KindGroup:
+		group := a.Value.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			attrs[i] = truncateAttr(ga, max)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(attrs...)}
+	}
+	return a
+}
+
+func truncateString(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	n := 0
+	for i := range s {
+		if n == max {
+			return s[:i] + "…"
+		}
+		n++
+	}
+	return s
+}
@@ -0,0 +1,76 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStormMode(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: slog.LevelDebug, Writer: &buf})
+
+	logger.StartStorm(time.Hour, StormOptions{SampleRate: 10, MaxMessageLen: 5})
+	if !logger.StormActive() {
+		t.Fatal("Expected storm mode to be active")
+	}
+	for i := 0; i < 100; i++ {
+		logger.Info("noisy")
+	}
+	logger.Error("payment failed for order", "detail", "card declined by issuer")
+	logger.StopStorm()
+	logger.Info("back to normal")
+
+	output := buf.String()
+	if n := strings.Count(output, `msg=noisy`); n != 10 {
+		t.Errorf("Expected 10 sampled info records, got %d", n)
+	}
+	if !strings.Contains(output, `msg=payme…`) || !strings.Contains(output, `detail="card …"`) {
+		t.Errorf("Expected error record to be kept and truncated, got: %s", output)
+	}
+	if !strings.Contains(output, "log storm mode started") || !strings.Contains(output, "dropped=90") {
+		t.Errorf("Expected start and end banners, got: %s", output)
+	}
+	if !strings.Contains(output, `msg="back to normal"`) {
+		t.Errorf("Expected normal logging after storm ended, got: %s", output)
+	}
+}
+
+func TestStormModeExpires(t *testing.T) {
+	var buf syncBuffer
+	logger := NewLogger(Config{Level: slog.LevelDebug, Writer: &buf})
+	logger.StartStorm(10*time.Millisecond, StormOptions{})
+
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(buf.String(), "log storm mode ended") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if logger.StormActive() {
+		t.Error("Expected storm mode to expire")
+	}
+	if !strings.Contains(buf.String(), "log storm mode ended") {
+		t.Errorf("Expected end banner after expiry, got: %s", buf.String())
+	}
+}
+
+func TestStormModeKeepInfo(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: slog.LevelDebug, Writer: &buf})
+
+	logger.StartStorm(time.Hour, StormOptions{SampleRate: 10, KeepLevel: slog.LevelInfo})
+	for i := 0; i < 20; i++ {
+		logger.Debug("chatty")
+		logger.Info("noisy")
+	}
+	logger.StopStorm()
+
+	output := buf.String()
+	if n := strings.Count(output, `msg=noisy`); n != 20 {
+		t.Errorf("Expected all info records kept with KeepLevel Info, got %d", n)
+	}
+	if n := strings.Count(output, `msg=chatty`); n != 2 {
+		t.Errorf("Expected 2 sampled debug records, got %d", n)
+	}
+}
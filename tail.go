@@ -0,0 +1,127 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultTailMaxRecords 是每个请求默认最多缓存的记录数
+const DefaultTailMaxRecords = 1000
+
+// TailSampling 配置按请求缓存日志（尾部采样）：请求内低于 FlushLevel 的记录先缓存在 context 中，
+// 请求失败、耗时超过 Threshold 或者输出了 FlushLevel 及以上的记录时，缓存的记录按原来的时间和顺序输出，
+// 请求正常完成时丢弃。繁忙的服务只保留出问题的请求的 Debug/Info 日志。
+type TailSampling struct {
+	FlushLevel slog.Leveler  // 达到该级别的记录立即输出，并连同缓存的记录一起输出，nil 表示 Warn
+	Threshold  time.Duration // 请求耗时超过该值时也输出缓存的记录，0 表示不按耗时判断
+	MaxRecords int           // 每个请求最多缓存的记录数，超出时丢弃最早的，0 表示 DefaultTailMaxRecords
+}
+
+// TailSample 使用默认 Logger 开始缓存请求的日志，见 Logger.TailSample
+func TailSample(ctx context.Context, opts TailSampling) (context.Context, func(failed bool)) {
+	return defaultLogger.TailSample(ctx, opts)
+}
+
+// TailSample 返回缓存日志的 context，使用该 context 通过本 Logger（及其 With、Named 派生的 logger）
+// 调用 InfoContext 等方法输出的记录按 opts 缓存。请求结束时调用返回的函数，failed 为 true 或耗时超过
+// opts.Threshold 时输出缓存的记录，否则丢弃。结束后同一 context 上的记录直接输出：
+//
+//	ctx, done := logger.TailSample(ctx, log.TailSampling{Threshold: time.Second})
+//	err := process(ctx)
+//	done(err != nil)
+//
+// 设置了 Config.TailSampling 时 HTTPMiddleware 和 StartRPC 会自动这样处理每个请求。
+func (l *Logger) TailSample(ctx context.Context, opts TailSampling) (context.Context, func(failed bool)) {
+	b := &tailBuffer{opts: opts, start: now()}
+	if b.opts.FlushLevel == nil {
+		b.opts.FlushLevel = slog.LevelWarn
+	}
+	if b.opts.MaxRecords <= 0 {
+		b.opts.MaxRecords = DefaultTailMaxRecords
+	}
+	return context.WithValue(ctx, tailKey{}, b), b.finish
+}
+
+type tailKey struct{}
+
+// tailRecord 是缓存的记录以及输出它的 handler，With 派生的 logger 的 handler 不同
+type tailRecord struct {
+	ctx     context.Context
+	handler slog.Handler
+	record  slog.Record
+}
+
+// tailBuffer 是一个请求的缓存
+type tailBuffer struct {
+	opts  TailSampling
+	start time.Time
+
+	mu      sync.Mutex
+	records []tailRecord
+	through bool // 已经输出过缓存或请求已经结束，之后的记录直接输出
+}
+
+func (b *tailBuffer) handle(ctx context.Context, h slog.Handler, r slog.Record) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.through {
+		return h.Handle(ctx, r)
+	}
+	if r.Level < b.opts.FlushLevel.Level() {
+		if len(b.records) >= b.opts.MaxRecords {
+			b.records = append(b.records[:0], b.records[1:]...)
+		}
+		b.records = append(b.records, tailRecord{ctx: ctx, handler: h, record: r.Clone()})
+		return nil
+	}
+	b.flush()
+	return h.Handle(ctx, r)
+}
+
+// finish 在请求结束时调用，决定输出还是丢弃缓存的记录
+func (b *tailBuffer) finish(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.through {
+		return
+	}
+	if failed || (b.opts.Threshold > 0 && now().Sub(b.start) > b.opts.Threshold) {
+		b.flush()
+	}
+	b.records = nil
+	b.through = true
+}
+
+// flush 按顺序输出缓存的记录，调用时需要持有 mu
+func (b *tailBuffer) flush() {
+	for _, t := range b.records {
+		// 请求的 ctx 此时可能已经取消，输出时不再携带取消信号
+		t.handler.Handle(context.WithoutCancel(t.ctx), t.record)
+	}
+	b.records = nil
+	b.through = true
+}
+
+// tailHandler 将记录交给 ctx 中的缓存，ctx 中没有缓存时直接输出。
+// Config.NeverSample 中的消息和 MustDeliver、ErrorSync 等需要确认送达的记录不缓存，直接输出
+type tailHandler struct {
+	slog.Handler
+	exempt exemptions
+}
+
+func (h *tailHandler) Handle(ctx context.Context, r slog.Record) error {
+	if b, ok := ctx.Value(tailKey{}).(*tailBuffer); ok && !h.exempt.keep(ctx, r.Message) {
+		return b.handle(ctx, h.Handler, r)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *tailHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &tailHandler{Handler: h.Handler.WithAttrs(attrs), exempt: h.exempt}
+}
+
+func (h *tailHandler) WithGroup(name string) slog.Handler {
+	return &tailHandler{Handler: h.Handler.WithGroup(name), exempt: h.exempt}
+}
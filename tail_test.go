@@ -0,0 +1,110 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTailSample(t *testing.T) {
+	var buf strings.Builder
+	logger := NewLogger(Config{Writer: &buf, Format: "json", Level: slog.LevelDebug})
+
+	ctx, done := logger.TailSample(context.Background(), TailSampling{MaxRecords: 2})
+	logger.DebugContext(ctx, "dropped debug")
+	logger.InfoContext(ctx, "dropped info")
+	done(false)
+	if buf.Len() != 0 {
+		t.Fatalf("Expected records of a successful request to be dropped, got %q", buf.String())
+	}
+
+	ctx, done = logger.TailSample(context.Background(), TailSampling{MaxRecords: 2})
+	logger.InfoContext(ctx, "evicted")
+	logger.With("step", 1).DebugContext(ctx, "kept debug")
+	logger.InfoContext(ctx, "kept info")
+	logger.Info("no ctx")
+	done(true)
+	logger.InfoContext(ctx, "after done")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{`"msg":"no ctx"`, `"msg":"kept debug","step":1`, `"msg":"kept info"`, `"msg":"after done"`}
+	if len(lines) != len(want) {
+		t.Fatalf("Expected %d lines, got %q", len(want), buf.String())
+	}
+	for i, w := range want {
+		if !strings.Contains(lines[i], w) {
+			t.Errorf("line %d: expected %s, got %s", i, w, lines[i])
+		}
+	}
+
+	buf.Reset()
+	ctx, done = logger.TailSample(context.Background(), TailSampling{Threshold: time.Millisecond})
+	logger.InfoContext(ctx, "slow request")
+	time.Sleep(2 * time.Millisecond)
+	done(false)
+	if !strings.Contains(buf.String(), "slow request") {
+		t.Errorf("Expected records of a slow request, got %q", buf.String())
+	}
+}
+
+func TestTailSampleFlushLevel(t *testing.T) {
+	var buf strings.Builder
+	logger := NewLogger(Config{Writer: &buf, Format: "json", TailSampling: &TailSampling{}})
+	handler := logger.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).InfoContext(r.Context(), "loading order")
+		if r.URL.Path == "/fail" {
+			FromContext(r.Context()).WarnContext(r.Context(), "retrying")
+		}
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+	if strings.Contains(buf.String(), "loading order") || !strings.Contains(buf.String(), `"msg":"HTTP request"`) {
+		t.Errorf("Expected only the completion record, got %q", buf.String())
+	}
+
+	buf.Reset()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fail", nil))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "loading order") || !strings.Contains(lines[1], "retrying") {
+		t.Errorf("Expected the Warn record to flush the buffer, got %q", buf.String())
+	}
+}
+
+func TestTailSampleBypass(t *testing.T) {
+	var buf syncBuffer
+	logger := NewLogger(Config{Writer: &buf, Format: "json", NeverSample: []string{"payment failed"}})
+
+	ctx, done := logger.TailSample(context.Background(), TailSampling{})
+	logger.InfoContext(ctx, "payment failed")
+	logger.InfoContext(ctx, "audit", MustDeliver())
+	logger.InfoContext(ctx, "buffered")
+	done(false)
+
+	out := buf.String()
+	if !strings.Contains(out, `"msg":"payment failed"`) || !strings.Contains(out, `"msg":"audit"`) {
+		t.Errorf("Expected NeverSample and MustDeliver records to bypass the buffer, got %q", out)
+	}
+	if strings.Contains(out, "buffered") {
+		t.Errorf("Expected other records to be dropped, got %q", out)
+	}
+}
+
+func TestTailSampleClock(t *testing.T) {
+	current := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	SetClock(ClockFunc(func() time.Time { return current }))
+	defer SetClock(nil)
+
+	var buf strings.Builder
+	logger := NewLogger(Config{Writer: &buf, Format: "json"})
+	ctx, done := logger.TailSample(context.Background(), TailSampling{Threshold: time.Second})
+	logger.InfoContext(ctx, "slow request")
+	current = current.Add(2 * time.Second)
+	done(false)
+	if !strings.Contains(buf.String(), "slow request") {
+		t.Errorf("Expected the threshold to use the configured clock, got %q", buf.String())
+	}
+}
@@ -0,0 +1,86 @@
+package log
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// TelemetryMessage 是运行时快照记录的消息
+const TelemetryMessage = "runtime telemetry"
+
+// telemetry 定期输出运行时快照，实现 io.Closer 以便随 Logger 一起关闭
+type telemetry struct {
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// Close 停止输出，并等待正在输出的快照完成
+func (t *telemetry) Close() error {
+	t.once.Do(func() { close(t.stop) })
+	<-t.done
+	return nil
+}
+
+// StartTelemetry 每隔 interval 输出一条 Info 级别的运行时快照（GOMAXPROCS、goroutine 数、堆内存、GC 暂停、打开的文件数），
+// 为没有指标采集的主机提供基本的运行状况。返回的函数用于停止输出。
+func (l *Logger) StartTelemetry(interval time.Duration) (stop func()) {
+	t := l.startTelemetry(interval)
+	return func() { t.Close() }
+}
+
+func (l *Logger) startTelemetry(interval time.Duration) *telemetry {
+	t := &telemetry{stop: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		defer close(t.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				l.Info(TelemetryMessage, runtimeSnapshot())
+			case <-t.stop:
+				return
+			}
+		}
+	}()
+	return t
+}
+
+// runtimeSnapshot 采集运行时状态，以 runtime 分组输出
+func runtimeSnapshot() slog.Attr {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	attrs := []any{
+		"gomaxprocs", runtime.GOMAXPROCS(0),
+		"goroutines", runtime.NumGoroutine(),
+		"heap_inuse", ms.HeapInuse,
+		"gc_count", ms.NumGC,
+		"gc_pause_total", time.Duration(ms.PauseTotalNs),
+	}
+	if ms.NumGC > 0 {
+		attrs = append(attrs, "gc_pause_last", time.Duration(ms.PauseNs[(ms.NumGC+255)%256]))
+	}
+	if n, ok := openFDs(); ok {
+		attrs = append(attrs, "open_fds", n)
+	}
+	return slog.Group("runtime", attrs...)
+}
+
+// openFDs 返回进程打开的文件描述符数，只在提供 /proc 的系统上可用
+func openFDs() (int, bool) {
+	f, err := os.Open("/proc/self/fd")
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+	names, err := f.Readdirnames(-1)
+	if err != nil && err != io.EOF {
+		return 0, false
+	}
+	return len(names) - 1, true // 不计入读取目录本身占用的描述符
+}
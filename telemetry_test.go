@@ -0,0 +1,32 @@
+package log
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTelemetry(t *testing.T) {
+	var buf syncBuffer
+	logger := NewLogger(Config{Level: slog.LevelDebug, Writer: &buf, TelemetryInterval: 10 * time.Millisecond})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(buf.String(), TelemetryMessage) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	logger.Close()
+
+	output := buf.String()
+	for _, want := range []string{"runtime.gomaxprocs=", "runtime.goroutines=", "runtime.heap_inuse=", "runtime.gc_pause_total="} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in telemetry record, got: %s", want, output)
+		}
+	}
+
+	n := strings.Count(buf.String(), TelemetryMessage)
+	time.Sleep(30 * time.Millisecond)
+	if m := strings.Count(buf.String(), TelemetryMessage); m != n {
+		t.Errorf("Expected telemetry to stop after Close, got %d more records", m-n)
+	}
+}
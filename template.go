@@ -0,0 +1,124 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// DefaultLineTemplate 是 template 格式的默认行模板
+const DefaultLineTemplate = "{time} [{level}] {caller} {msg} {attrs}"
+
+// lineSegment 是行模板的一段：字面文本或占位符
+type lineSegment struct {
+	literal string
+	field   string // 占位符名称，为空表示字面文本
+	layout  string // {time:layout} 指定的时间格式
+}
+
+// parseLineTemplate 解析行模板，未闭合的 { 按字面文本处理
+func parseLineTemplate(tmpl string) []lineSegment {
+	var segs []lineSegment
+	for tmpl != "" {
+		start := strings.IndexByte(tmpl, '{')
+		end := strings.IndexByte(tmpl[start+1:], '}')
+		if start < 0 || end < 0 {
+			segs = append(segs, lineSegment{literal: tmpl})
+			break
+		}
+		if start > 0 {
+			segs = append(segs, lineSegment{literal: tmpl[:start]})
+		}
+		field := tmpl[start+1 : start+1+end]
+		seg := lineSegment{field: field}
+		if name, layout, ok := strings.Cut(field, ":"); ok && name == "time" {
+			seg.field, seg.layout = name, layout
+		}
+		segs = append(segs, seg)
+		tmpl = tmpl[start+end+2:]
+	}
+	return segs
+}
+
+// newTemplateHandler 创建按行模板输出的文本格式 handler，用于迁移期间兼容旧系统的日志格式：
+//
+//	{time} [{level}] {caller} {msg} {attrs}
+//	2024-05-01 12:00:00.000000 [INFO] [main.go:42] user logged in user_id=123
+//
+// 支持的占位符：{time}、{time:2006-01-02T15:04:05}、{level}、{caller}、{msg}、{attrs}，
+// 其他占位符按属性 key 取值（分组属性使用以点分隔的 key），被单独引用的属性不再出现在 {attrs} 中。
+func newTemplateHandler(w io.Writer, opts *slog.HandlerOptions, tmpl, timeLayout string) *formatHandler {
+	if tmpl == "" {
+		tmpl = DefaultLineTemplate
+	}
+	segs := parseLineTemplate(tmpl)
+	named := make(map[string]bool)
+	for _, seg := range segs {
+		switch seg.field {
+		case "", "time", "level", "caller", "msg", "attrs":
+		default:
+			named[seg.field] = true
+		}
+	}
+	return newFormatHandler(w, opts, func(buf *bytes.Buffer, e *entry) {
+		values := make(map[string]string, len(named))
+		var rest bytes.Buffer
+		var walk func(prefix string, a slog.Attr)
+		walk = func(prefix string, a slog.Attr) {
+			key := prefix + a.Key
+			if a.Value.Kind() == slog.KindGroup {
+				for _, ga := range a.Value.Group() {
+					walk(key+".", ga)
+				}
+				return
+			}
+			if named[key] {
+				values[key] = valueText(a.Value)
+				return
+			}
+			if rest.Len() > 0 {
+				rest.WriteByte(' ')
+			}
+			rest.WriteString(key)
+			rest.WriteByte('=')
+			rest.WriteString(quoteIfNeeded(valueText(a.Value)))
+		}
+		for _, a := range e.attrs {
+			walk("", a)
+		}
+
+		for _, seg := range segs {
+			switch seg.field {
+			case "":
+				buf.WriteString(seg.literal)
+			case "time":
+				if e.time.Key != "" {
+					layout := seg.layout
+					if layout == "" {
+						layout = timeLayout
+					}
+					buf.WriteString(e.timeText(layout))
+				}
+			case "level":
+				if e.level.Key != "" {
+					buf.WriteString(e.levelText())
+				}
+			case "caller":
+				buf.WriteString(e.caller)
+			case "msg":
+				if e.message.Key != "" {
+					buf.WriteString(e.message.Value.String())
+				}
+			case "attrs":
+				buf.Write(rest.Bytes())
+			default:
+				buf.WriteString(values[seg.field])
+			}
+		}
+		// 属性为空等情况会留下行尾空白
+		line := bytes.TrimRight(buf.Bytes(), " ")
+		buf.Truncate(len(line))
+		buf.WriteByte('\n')
+	})
+}
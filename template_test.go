@@ -0,0 +1,42 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"regexp"
+	"testing"
+)
+
+func TestTemplateFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{
+		Level:        slog.LevelDebug,
+		Format:       "template",
+		Writer:       &buf,
+		LineTemplate: "{time:2006/01/02} {level} <{req.id}> {msg} | {attrs}",
+	})
+	logger.Info("user logged in", "user_id", 123, slog.Group("req", "id", "r-1"), "note", "a b")
+
+	pattern := `^\d{4}/\d{2}/\d{2} INFO <r-1> user logged in \| user_id=123 note="a b"\n$`
+	if !regexp.MustCompile(pattern).MatchString(buf.String()) {
+		t.Errorf("Output %q does not match %s", buf.String(), pattern)
+	}
+}
+
+func TestParseLineTemplate(t *testing.T) {
+	segs := parseLineTemplate("{time:15:04} [{level}] {msg")
+	want := []lineSegment{
+		{field: "time", layout: "15:04"},
+		{literal: " ["},
+		{field: "level"},
+		{literal: "] {msg"},
+	}
+	if len(segs) != len(want) {
+		t.Fatalf("Expected %d segments, got %+v", len(want), segs)
+	}
+	for i := range want {
+		if segs[i] != want[i] {
+			t.Errorf("segment %d = %+v, want %+v", i, segs[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+)
+
+// 线程信息属性的 key
+const (
+	ThreadIDKey = "tid"
+	CPUKey      = "cpu"
+)
+
+// threadHandler 在记录上附加写日志时所在的 OS 线程 ID 以及 CPU 编号（仅在能低成本获取时），
+// 用于排查 cgo、LockOSThread 等与线程相关的问题。每条记录都会产生系统调用，开销较大，默认关闭。
+// 记录在调用日志方法的 goroutine 中同步处理，因此取到的是调用方当时所在的线程。
+type threadHandler struct {
+	slog.Handler
+}
+
+func (h *threadHandler) Handle(ctx context.Context, r slog.Record) error {
+	tid, ok := threadID()
+	if !ok {
+		return h.Handler.Handle(ctx, r)
+	}
+	r = r.Clone()
+	r.AddAttrs(slog.Int(ThreadIDKey, tid))
+	if cpu, ok := cpuID(); ok {
+		r.AddAttrs(slog.Int(CPUKey, cpu))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *threadHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &threadHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *threadHandler) WithGroup(name string) slog.Handler {
+	return &threadHandler{Handler: h.Handler.WithGroup(name)}
+}
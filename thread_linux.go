@@ -0,0 +1,22 @@
+package log
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+func threadID() (int, bool) {
+	return syscall.Gettid(), true
+}
+
+// cpuID 通过 getcpu 系统调用获取当前 CPU 编号，getcpuTrap 为 0 的架构不支持
+func cpuID() (int, bool) {
+	if getcpuTrap == 0 {
+		return 0, false
+	}
+	var cpu uint32
+	if _, _, errno := syscall.RawSyscall(getcpuTrap, uintptr(unsafe.Pointer(&cpu)), 0, 0); errno != 0 {
+		return 0, false
+	}
+	return int(cpu), true
+}
@@ -0,0 +1,4 @@
+package log
+
+// getcpuTrap 是 getcpu 的系统调用号，syscall 包在 amd64 上没有定义 SYS_GETCPU
+const getcpuTrap = 309
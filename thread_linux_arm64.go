@@ -0,0 +1,5 @@
+package log
+
+import "syscall"
+
+const getcpuTrap = syscall.SYS_GETCPU
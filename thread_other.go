@@ -0,0 +1,7 @@
+//go:build !linux
+
+package log
+
+func threadID() (int, bool) { return 0, false }
+
+func cpuID() (int, bool) { return 0, false }
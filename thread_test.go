@@ -0,0 +1,30 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestThreadInfo(t *testing.T) {
+	if _, ok := threadID(); !ok {
+		t.Skip("thread ID is not available on " + runtime.GOOS)
+	}
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: slog.LevelDebug, Writer: &buf, ThreadInfo: true})
+	logger.Info("hello")
+	if !strings.Contains(buf.String(), "tid=") {
+		t.Errorf("Expected thread ID, got: %s", buf.String())
+	}
+	if _, ok := cpuID(); ok && !strings.Contains(buf.String(), "cpu=") {
+		t.Errorf("Expected CPU ID, got: %s", buf.String())
+	}
+
+	buf.Reset()
+	NewLogger(Config{Level: slog.LevelDebug, Writer: &buf}).Info("hello")
+	if strings.Contains(buf.String(), "tid=") {
+		t.Errorf("Expected thread info to be off by default, got: %s", buf.String())
+	}
+}
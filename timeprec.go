@@ -0,0 +1,113 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// TimePrecision 定义输出时间戳的精度
+type TimePrecision int
+
+const (
+	// TimeDefault 内置输出使用微秒精度（与旧版本一致），Sink 保持记录的原始精度
+	TimeDefault TimePrecision = iota
+	TimeSecond
+	TimeMilli
+	TimeMicro
+	TimeNano
+	// TimeOmit 不输出时间戳，适用于 journald、CloudWatch 等自带时间戳的传输
+	TimeOmit
+)
+
+var timePrecisionNames = map[TimePrecision]string{
+	TimeDefault: "default",
+	TimeSecond:  "second",
+	TimeMilli:   "milli",
+	TimeMicro:   "micro",
+	TimeNano:    "nano",
+	TimeOmit:    "omit",
+}
+
+func (p TimePrecision) String() string {
+	if name, ok := timePrecisionNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("TimePrecision(%d)", int(p))
+}
+
+// MarshalText 实现 encoding.TextMarshaler
+func (p TimePrecision) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler，配置文件中可以直接写 "milli"、"omit" 等
+func (p *TimePrecision) UnmarshalText(text []byte) error {
+	s := strings.ToLower(strings.TrimSpace(string(text)))
+	for v, name := range timePrecisionNames {
+		if s == name {
+			*p = v
+			return nil
+		}
+	}
+	return fmt.Errorf("slogx: unknown time precision %q", text)
+}
+
+// layout 返回内置输出使用的时间格式
+func (p TimePrecision) layout() string {
+	switch p {
+	case TimeSecond:
+		return "2006-01-02 15:04:05"
+	case TimeMilli:
+		return "2006-01-02 15:04:05.000"
+	case TimeNano:
+		return "2006-01-02 15:04:05.000000000"
+	default:
+		return "2006-01-02 15:04:05.000000"
+	}
+}
+
+// apply 按精度截断时间，TimeOmit 返回零值（slog 的 handler 会省略零值时间）
+func (p TimePrecision) apply(t time.Time) time.Time {
+	switch p {
+	case TimeSecond:
+		return t.Truncate(time.Second)
+	case TimeMilli:
+		return t.Truncate(time.Millisecond)
+	case TimeMicro:
+		return t.Truncate(time.Microsecond)
+	case TimeOmit:
+		return time.Time{}
+	default:
+		return t
+	}
+}
+
+// precisionHandler 在交给下游之前按精度处理记录时间
+type precisionHandler struct {
+	slog.Handler
+	precision TimePrecision
+}
+
+// withPrecision 在需要时为 handler 包装时间精度处理
+func withPrecision(h slog.Handler, p TimePrecision) slog.Handler {
+	if p == TimeDefault {
+		return h
+	}
+	return &precisionHandler{Handler: h, precision: p}
+}
+
+func (h *precisionHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.Time = h.precision.apply(r.Time)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *precisionHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &precisionHandler{Handler: h.Handler.WithAttrs(attrs), precision: h.precision}
+}
+
+func (h *precisionHandler) WithGroup(name string) slog.Handler {
+	return &precisionHandler{Handler: h.Handler.WithGroup(name), precision: h.precision}
+}
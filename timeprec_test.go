@@ -0,0 +1,69 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestTimePrecisionOutput(t *testing.T) {
+	cases := []struct {
+		precision TimePrecision
+		pattern   string
+	}{
+		{TimeDefault, `time="\d{4}-\d\d-\d\d \d\d:\d\d:\d\d\.\d{6}"`},
+		{TimeSecond, `time="\d{4}-\d\d-\d\d \d\d:\d\d:\d\d"`},
+		{TimeMilli, `time="\d{4}-\d\d-\d\d \d\d:\d\d:\d\d\.\d{3}"`},
+		{TimeNano, `time="\d{4}-\d\d-\d\d \d\d:\d\d:\d\d\.\d{9}"`},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		logger := NewLogger(Config{Level: slog.LevelDebug, Writer: &buf, TimePrecision: c.precision})
+		logger.Info("hello")
+		if !regexp.MustCompile(c.pattern).MatchString(buf.String()) {
+			t.Errorf("%s: output %q does not match %s", c.precision, buf.String(), c.pattern)
+		}
+	}
+
+	var buf bytes.Buffer
+	logger := NewLogger(Config{Level: slog.LevelDebug, Writer: &buf, TimePrecision: TimeOmit})
+	logger.Info("hello")
+	if regexp.MustCompile(`time=`).MatchString(buf.String()) {
+		t.Errorf("Expected timestamp to be omitted, got %q", buf.String())
+	}
+}
+
+func TestSinkTimePrecision(t *testing.T) {
+	milli := &memorySink{}
+	omit := &memorySink{}
+	registerTestSink(t, "test-precision-milli", func(map[string]any) (Sink, error) { return milli, nil })
+	registerTestSink(t, "test-precision-omit", func(map[string]any) (Sink, error) { return omit, nil })
+
+	logger := NewLogger(Config{
+		Level: slog.LevelDebug,
+		Sinks: []SinkConfig{
+			{Type: "test-precision-milli", TimePrecision: TimeMilli},
+			{Type: "test-precision-omit", TimePrecision: TimeOmit},
+		},
+	})
+	logger.Info("hello")
+
+	if got := milli.records[0].Time; got.IsZero() || got != got.Truncate(time.Millisecond) {
+		t.Errorf("Expected millisecond precision, got %v", got)
+	}
+	if got := omit.records[0].Time; !got.IsZero() {
+		t.Errorf("Expected zero time, got %v", got)
+	}
+}
+
+func TestTimePrecisionUnmarshalText(t *testing.T) {
+	var p TimePrecision
+	if err := p.UnmarshalText([]byte("Milli")); err != nil || p != TimeMilli {
+		t.Errorf("Expected TimeMilli, got %v, err: %v", p, err)
+	}
+	if err := p.UnmarshalText([]byte("hour")); err == nil {
+		t.Error("Expected error for unknown precision")
+	}
+}
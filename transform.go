@@ -0,0 +1,172 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Transform 是一条记录变换规则，可以从配置文件加载，表达式语法见 expr.go：
+//
+//	Transforms: []log.Transform{
+//		{Field: "latency_bucket", Expr: "bucket(duration_ms, [10, 50, 100, 500])"},
+//		{Drop: `level == "DEBUG" && startswith(msg, "health check")`},
+//	}
+//
+// 规则按顺序执行，后面的规则可以读取前面计算出的字段。求值出错（例如类型不匹配、字符串超出 MaxExprStringLen）时
+// 跳过该规则，记录原样输出，不会因为规则写错而丢失日志。
+type Transform struct {
+	Field string // 计算字段的 key，与 Expr 一起使用，结果为 null 时不添加
+	Expr  string // 计算字段的表达式
+	Drop  string // 丢弃条件，结果为 true 时丢弃记录；与 Field/Expr 二选一
+}
+
+// transformRule 是编译后的 Transform
+type transformRule struct {
+	field string
+	expr  *Expr
+	drop  bool
+}
+
+// compileTransforms 编译规则，返回成功编译的规则和每条失败规则的错误
+func compileTransforms(transforms []Transform) ([]transformRule, []error) {
+	var rules []transformRule
+	var errs []error
+	for i, t := range transforms {
+		src, drop := t.Expr, t.Drop != ""
+		if drop {
+			src = t.Drop
+		}
+		if drop == (t.Field != "") || (!drop && src == "") {
+			errs = append(errs, fmt.Errorf("transform %d: set either Drop or Field and Expr", i))
+			continue
+		}
+		expr, err := CompileExpr(src)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("transform %d: %w", i, err))
+			continue
+		}
+		rules = append(rules, transformRule{field: t.Field, expr: expr, drop: drop})
+	}
+	return rules, errs
+}
+
+// transformHandler 在输出前对记录执行变换规则，WithAttrs 绑定的属性同样可以在表达式中读取
+type transformHandler struct {
+	slog.Handler
+	rules  []transformRule
+	state  attrState
+	exempt exemptions // 永不丢弃的消息，丢弃规则对它们不生效
+}
+
+func (h *transformHandler) Handle(ctx context.Context, r slog.Record) error {
+	env := &recordEnv{r: r, attrs: h.state.collect(r)}
+	for _, rule := range h.rules {
+		v, err := rule.expr.eval(env)
+		if err != nil {
+			continue
+		}
+		if rule.drop {
+			if drop, ok := v.(bool); ok && drop && !h.exempt.keep(ctx, r.Message) {
+				return nil
+			}
+			continue
+		}
+		if v != nil {
+			env.computed = append(env.computed, slog.Any(rule.field, exprToValue(v)))
+		}
+	}
+	if len(env.computed) > 0 {
+		r = r.Clone()
+		r.AddAttrs(env.computed...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *transformHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &transformHandler{Handler: h.Handler.WithAttrs(attrs), rules: h.rules, state: h.state.withAttrs(attrs), exempt: h.exempt}
+}
+
+func (h *transformHandler) WithGroup(name string) slog.Handler {
+	return &transformHandler{Handler: h.Handler.WithGroup(name), rules: h.rules, state: h.state.withGroup(name), exempt: h.exempt}
+}
+
+// recordEnv 为表达式提供记录中的变量
+type recordEnv struct {
+	r        slog.Record
+	attrs    []slog.Attr
+	computed []slog.Attr // 前面的规则计算出的字段
+}
+
+func (e *recordEnv) lookup(name string) (any, bool) {
+	for i := len(e.computed) - 1; i >= 0; i-- {
+		if e.computed[i].Key == name {
+			return attrToExpr(e.computed[i].Value), true
+		}
+	}
+	switch name {
+	case "msg":
+		return e.r.Message, true
+	case "level":
+		return levelString(e.r.Level), true
+	}
+	if v, ok := findAttr(e.attrs, name); ok {
+		return attrToExpr(v), true
+	}
+	return nil, false
+}
+
+// findAttr 按以点分隔的路径查找属性，key 本身含有点时同样可以找到
+func findAttr(attrs []slog.Attr, path string) (slog.Value, bool) {
+	for _, a := range attrs {
+		if a.Key == path {
+			return a.Value.Resolve(), true
+		}
+		if rest, ok := strings.CutPrefix(path, a.Key+"."); ok || a.Key == "" {
+			v := a.Value.Resolve()
+			if v.Kind() != slog.KindGroup {
+				continue
+			}
+			if a.Key == "" {
+				rest = path
+			}
+			if v, ok := findAttr(v.Group(), rest); ok {
+				return v, true
+			}
+		}
+	}
+	return slog.Value{}, false
+}
+
+// attrToExpr 把属性值转换为表达式使用的类型，time.Duration 转换为毫秒
+func attrToExpr(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String()
+	case slog.KindInt64:
+		return float64(v.Int64())
+	case slog.KindUint64:
+		return float64(v.Uint64())
+	case slog.KindFloat64:
+		return v.Float64()
+	case slog.KindBool:
+		return v.Bool()
+	case slog.KindDuration:
+		return float64(v.Duration()) / float64(time.Millisecond)
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindGroup:
+		return nil
+	}
+	return exprValue(v.Any())
+}
+
+// exprToValue 把表达式的结果转换为属性值，整数结果输出为整数
+func exprToValue(v any) slog.Value {
+	if f, ok := v.(float64); ok && f == float64(int64(f)) {
+		return slog.Int64Value(int64(f))
+	}
+	return slog.AnyValue(v)
+}
@@ -0,0 +1,78 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransforms(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{
+		Writer: &buf,
+		Format: "json",
+		Level:  slog.LevelDebug,
+		Quiet:  true,
+		Transforms: []Transform{
+			{Field: "latency_bucket", Expr: "bucket(duration_ms, [10, 50, 100, 500])"},
+			{Field: "slow", Expr: `latency_bucket in ["100-500", ">=500"]`},
+			{Field: "class", Expr: `http.status >= 500 ? "server" : "client"`},
+			{Drop: `level == "DEBUG" && startswith(msg, "health")`},
+			{Drop: `service == "noisy"`},
+			{Field: "broken", Expr: "bucket("}, // 编译失败，被跳过
+			{Field: "oops", Expr: `msg + 1`},   // 求值失败，跳过该规则
+		},
+	})
+	logger.With("service", "api").Info("request done",
+		"duration", 250*time.Millisecond, "duration_ms", 250,
+		slog.Group("http", "status", 502))
+	logger.Debug("health check")
+	logger.With("service", "noisy").Info("tick")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d records, want only the first (the others dropped):\n%s", len(lines), buf.String())
+	}
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec["latency_bucket"] != "100-500" || rec["slow"] != true || rec["class"] != "server" {
+		t.Errorf("computed fields = %v", rec)
+	}
+	if _, ok := rec["oops"]; ok {
+		t.Errorf("failed rule added a field: %v", rec)
+	}
+	if fb := logger.InitReport().Fallbacks; len(fb) != 1 || !strings.Contains(fb[0], "transform 5") {
+		t.Errorf("fallbacks = %v, want the broken rule reported", fb)
+	}
+}
+
+func TestTransformDropExempt(t *testing.T) {
+	var buf syncBuffer
+	logger := NewLogger(Config{
+		Writer:      &buf,
+		Format:      "json",
+		Quiet:       true,
+		NeverSample: []string{"payment failed"},
+		Transforms:  []Transform{{Drop: `service == "billing"`}},
+	})
+	billing := logger.With("service", "billing")
+	billing.Info("tick")
+	billing.Error("payment failed")
+	if err := billing.ErrorSync(context.Background(), "charge declined"); err != nil {
+		t.Errorf("ErrorSync = %v", err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "tick") {
+		t.Errorf("Expected the non-exempt record dropped:\n%s", out)
+	}
+	if !strings.Contains(out, "payment failed") || !strings.Contains(out, "charge declined") {
+		t.Errorf("Expected NeverSample and ErrorSync records to survive the drop rule:\n%s", out)
+	}
+}
@@ -0,0 +1,64 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+)
+
+// MsgIDKey 是消息编号属性的 key，Translator 根据它查找译文
+const MsgIDKey = "msg_id"
+
+// DefaultTranslationKey 是译文属性的默认 key
+const DefaultTranslationKey = "msg_zh"
+
+// Translator 为消息提供第二语言的译文，主消息保持不变以便机器处理，译文作为额外属性输出给运维人员阅读
+type Translator interface {
+	// Translate 返回译文，msgID 为记录中 msg_id 属性的值（没有时为空），msg 为原消息
+	Translate(msgID, msg string) (string, bool)
+}
+
+// TranslationMap 是基于 map 的 Translator，先按 msg_id 查找，找不到时按原消息查找：
+//
+//	log.TranslationMap{"E1001": "数据库连接失败", "user logged in": "用户登录"}
+type TranslationMap map[string]string
+
+func (m TranslationMap) Translate(msgID, msg string) (string, bool) {
+	if msgID != "" {
+		if s, ok := m[msgID]; ok {
+			return s, true
+		}
+	}
+	s, ok := m[msg]
+	return s, ok
+}
+
+// translateHandler 为有译文的记录追加译文属性
+type translateHandler struct {
+	slog.Handler
+	translator Translator
+	key        string
+}
+
+func (h *translateHandler) Handle(ctx context.Context, r slog.Record) error {
+	var msgID string
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == MsgIDKey {
+			msgID = a.Value.Resolve().String()
+			return false
+		}
+		return true
+	})
+	if s, ok := h.translator.Translate(msgID, r.Message); ok {
+		r = r.Clone()
+		r.AddAttrs(slog.String(h.key, s))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *translateHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &translateHandler{Handler: h.Handler.WithAttrs(attrs), translator: h.translator, key: h.key}
+}
+
+func (h *translateHandler) WithGroup(name string) slog.Handler {
+	return &translateHandler{Handler: h.Handler.WithGroup(name), translator: h.translator, key: h.key}
+}
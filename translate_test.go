@@ -0,0 +1,32 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestTranslator(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(Config{
+		Level:      slog.LevelDebug,
+		Format:     "json",
+		Writer:     &buf,
+		Translator: TranslationMap{"E1001": "数据库连接失败", "user logged in": "用户登录"},
+	})
+	logger.Error("database connection failed", MsgIDKey, "E1001")
+	logger.Info("user logged in")
+	logger.Info("untranslated")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if !strings.Contains(lines[0], `"msg":"database connection failed"`) || !strings.Contains(lines[0], `"msg_zh":"数据库连接失败"`) {
+		t.Errorf("Expected translation by msg_id, got %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"msg_zh":"用户登录"`) {
+		t.Errorf("Expected translation by message, got %s", lines[1])
+	}
+	if strings.Contains(lines[2], "msg_zh") {
+		t.Errorf("Expected no translation, got %s", lines[2])
+	}
+}
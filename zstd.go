@@ -0,0 +1,189 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// DefaultZstdDictSize 是训练 zstd 字典时默认的字典大小
+const DefaultZstdDictSize = 32 << 10
+
+// TrainZstdDictionary 用样本训练 zstd 字典，样本通常是输出的日志行。size 是字典的大小上限，<= 0 时使用 DefaultZstdDictSize。
+// 结构化日志的 key、级别、消息模板高度重复，使用字典后每条记录单独压缩也能得到很高的压缩率。
+func TrainZstdDictionary(samples [][]byte, size int) ([]byte, error) {
+	if len(samples) == 0 {
+		return nil, errors.New("slogx: no samples to train zstd dictionary")
+	}
+	if size <= 0 {
+		size = DefaultZstdDictSize
+	}
+	// 字典内容取不重复的样本，越靠后的内容在压缩时偏移越小，因此把较新的样本放在后面
+	seen := make(map[string]bool)
+	var history []byte
+	for i := len(samples) - 1; i >= 0 && len(history) < size; i-- {
+		s := samples[i]
+		if seen[string(s)] {
+			continue
+		}
+		seen[string(s)] = true
+		if len(history)+len(s) > size {
+			s = s[:size-len(history)]
+		}
+		history = append(append([]byte(nil), s...), history...)
+	}
+	if len(history) < 8 {
+		return nil, errors.New("slogx: zstd dictionary samples are too small")
+	}
+	h := fnv.New32a()
+	h.Write(history)
+	// 0-32767 由 zstd 保留，2^31 以上保留给未来使用
+	id := 32768 + h.Sum32()%(1<<31-32768)
+	return zstd.BuildDict(zstd.BuildDictOptions{ID: id, Contents: samples, History: history, Level: zstd.SpeedDefault})
+}
+
+// ZstdDictSampler 是采样输出记录的 Sink，用于在真实流量上训练字典：
+//
+//	sampler := log.NewZstdDictSampler(100, 5000)
+//	log.RegisterSink("dict-sampler", func(map[string]any) (log.Sink, error) { return sampler, nil })
+//	... // 运行一段时间
+//	dict, err := sampler.Train(0)
+//	os.WriteFile("logs.dict", dict, 0644)
+type ZstdDictSampler struct {
+	mu      sync.Mutex
+	every   int
+	max     int
+	seen    int
+	samples [][]byte
+	buf     bytes.Buffer
+	handler slog.Handler
+}
+
+// NewZstdDictSampler 创建每 every 条记录采样一条、最多保留 max 条样本的采样器，样本按 json 格式编码
+func NewZstdDictSampler(every, max int) *ZstdDictSampler {
+	if every <= 0 {
+		every = 1
+	}
+	s := &ZstdDictSampler{every: every, max: max}
+	s.handler = slog.NewJSONHandler(&s.buf, &slog.HandlerOptions{Level: slog.Level(-128)})
+	return s
+}
+
+func (s *ZstdDictSampler) Write(ctx context.Context, records []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range records {
+		s.seen++
+		if (s.seen-1)%s.every != 0 {
+			continue
+		}
+		s.buf.Reset()
+		if err := s.handler.Handle(ctx, r); err != nil {
+			return err
+		}
+		sample := bytes.Clone(s.buf.Bytes())
+		if s.max > 0 && len(s.samples) >= s.max {
+			// 保留最新的样本
+			copy(s.samples, s.samples[1:])
+			s.samples[len(s.samples)-1] = sample
+			continue
+		}
+		s.samples = append(s.samples, sample)
+	}
+	return nil
+}
+
+func (s *ZstdDictSampler) Close() error { return nil }
+
+// Samples 返回已采集的样本数量
+func (s *ZstdDictSampler) Samples() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.samples)
+}
+
+// Train 用已采集的样本训练字典，size 的含义与 TrainZstdDictionary 相同
+func (s *ZstdDictSampler) Train(size int) ([]byte, error) {
+	s.mu.Lock()
+	samples := append([][]byte(nil), s.samples...)
+	s.mu.Unlock()
+	return TrainZstdDictionary(samples, size)
+}
+
+// ZstdWriter 以 zstd 压缩写入底层输出，适合文件或网络连接。每次 Write 后立即输出一个压缩块，
+// 记录不会滞留在压缩器中，进程崩溃时最多丢失正在写入的一条。读取时需要使用相同的字典。
+type ZstdWriter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc *zstd.Encoder
+}
+
+// NewZstdWriter 创建 zstd 压缩写入器，level 为 zstd 压缩级别（1-22，<= 0 时使用默认级别 3），dict 为 nil 时不使用字典
+func NewZstdWriter(w io.Writer, level int, dict []byte) (*ZstdWriter, error) {
+	enc, err := zstd.NewWriter(w, append(zstdOptions(level, dict), zstd.WithEncoderConcurrency(1))...)
+	if err != nil {
+		return nil, err
+	}
+	return &ZstdWriter{w: w, enc: enc}, nil
+}
+
+// zstdOptions 返回压缩级别和字典对应的编码参数，level <= 0 时使用默认级别 3
+func zstdOptions(level int, dict []byte) []zstd.EOption {
+	if level <= 0 {
+		level = 3
+	}
+	opts := []zstd.EOption{zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level))}
+	if dict != nil {
+		opts = append(opts, zstd.WithEncoderDict(dict))
+	}
+	return opts
+}
+
+func (z *ZstdWriter) Write(p []byte) (int, error) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	n, err := z.enc.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, z.enc.Flush()
+}
+
+// Close 结束压缩流，底层输出实现了 io.Closer 时一并关闭
+func (z *ZstdWriter) Close() error {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	err := z.enc.Close()
+	if c, ok := z.w.(io.Closer); ok && z.w != os.Stdout && z.w != os.Stderr {
+		err = errors.Join(err, c.Close())
+	}
+	return err
+}
+
+// newZstdFile 打开 path 并返回 zstd 压缩的写入器，供 file sink 的 compression=zstd 选项使用
+func newZstdFile(path string, options map[string]any) (io.WriteCloser, error) {
+	var dict []byte
+	if dictPath := optString(options, "zstd_dictionary", ""); dictPath != "" {
+		var err error
+		if dict, err = os.ReadFile(dictPath); err != nil {
+			return nil, err
+		}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	zw, err := NewZstdWriter(f, optInt(options, "zstd_level", 0), dict)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return zw, nil
+}
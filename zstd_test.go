@@ -0,0 +1,120 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestZstdDictionary(t *testing.T) {
+	sampler := NewZstdDictSampler(2, 300)
+	for i := 0; i < 1000; i++ {
+		r := slog.NewRecord(time.Now(), slog.LevelInfo, "request handled", 0)
+		r.AddAttrs(slog.String("method", "GET"), slog.String("path", "/api/v1/orders"), slog.Int("status", 200), slog.Int("id", i))
+		sampler.Write(context.Background(), []Record{r})
+	}
+	if n := sampler.Samples(); n != 300 {
+		t.Fatalf("Samples = %d, want 300", n)
+	}
+	dict, err := sampler.Train(0)
+	if err != nil {
+		t.Fatalf("Train failed: %v", err)
+	}
+
+	line := []byte(`{"time":"2024-05-01T12:00:00Z","level":"INFO","msg":"request handled","method":"GET","path":"/api/v1/orders","status":200,"id":4242}` + "\n")
+	compress := func(dict []byte) []byte {
+		var buf bytes.Buffer
+		zw, err := NewZstdWriter(&buf, 3, dict)
+		if err != nil {
+			t.Fatalf("NewZstdWriter failed: %v", err)
+		}
+		zw.Write(line)
+		zw.Close()
+		return buf.Bytes()
+	}
+	plain, withDict := compress(nil), compress(dict)
+	if len(withDict) >= len(plain) {
+		t.Errorf("compressed with dictionary = %d bytes, without = %d", len(withDict), len(plain))
+	}
+
+	dec, err := zstd.NewReader(bytes.NewReader(withDict), zstd.WithDecoderDicts(dict))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dec.Close()
+	got, err := io.ReadAll(dec)
+	if err != nil || !bytes.Equal(got, line) {
+		t.Errorf("round trip = %q, %v", got, err)
+	}
+}
+
+func TestZstdFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log.zst")
+	sink, err := NewSink(SinkConfig{Type: "file", Options: map[string]any{"path": path, "compression": "zstd"}})
+	if err != nil {
+		t.Fatalf("NewSink failed: %v", err)
+	}
+	sink.Write(context.Background(), []Record{slog.NewRecord(time.Now(), slog.LevelInfo, "compressed", 0)})
+	sink.Close()
+
+	f, _ := os.Open(path)
+	defer f.Close()
+	dec, err := zstd.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dec.Close()
+	got, _ := io.ReadAll(dec)
+	if !strings.Contains(string(got), "msg=compressed") {
+		t.Errorf("decompressed = %q", got)
+	}
+}
+
+func TestZstdBackups(t *testing.T) {
+	dir := t.TempDir()
+	rotated := make(chan string, 2)
+	logger := NewLogger(Config{
+		Filename:       filepath.Join(dir, "app.log"),
+		MaxBackups:     1,
+		Compress:       true,
+		CompressFormat: "zstd",
+		CompressLevel:  9,
+		OnRotate:       func(oldPath string) { rotated <- oldPath },
+	})
+	defer logger.Close()
+
+	var last string
+	for i := 0; i < 2; i++ {
+		logger.Info("archived", "round", i)
+		logger.Rotate()
+		last = <-rotated
+		time.Sleep(5 * time.Millisecond) // 备份名精确到毫秒
+	}
+	if !strings.HasSuffix(last, ".log.zst") {
+		t.Fatalf("OnRotate path = %s, want .zst backup", last)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("got %d files, want app.log and one .zst backup (MaxBackups=1)", len(entries))
+	}
+
+	f, err := os.Open(last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	dec, _ := zstd.NewReader(f)
+	defer dec.Close()
+	got, _ := io.ReadAll(dec)
+	if !strings.Contains(string(got), "round=1") {
+		t.Errorf("decompressed backup = %q", got)
+	}
+}